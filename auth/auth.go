@@ -7,6 +7,7 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"slices"
 	"strings"
@@ -19,6 +20,16 @@ type TokenInfo struct {
 	Expiration time.Time
 	// TODO: add standard JWT fields
 	Extra map[string]any
+	// Subject identifies the principal that is the subject of the token,
+	// usually the user (the "sub" claim of a JWT).
+	Subject string
+	// ClientID identifies the OAuth client to which the token was issued (the
+	// "client_id" claim of a JWT).
+	ClientID string
+	// DPoPThumbprint is the JWK SHA-256 thumbprint of the key to which the
+	// token is bound by DPoP (the "jkt" member of the "cnf" claim of a JWT),
+	// or empty if the token is not DPoP-bound. See [DPoPOptions].
+	DPoPThumbprint string
 }
 
 // The error that a TokenVerifier should return if the token cannot be verified.
@@ -39,6 +50,9 @@ type RequireBearerTokenOptions struct {
 	ResourceMetadataURL string
 	// The required scopes.
 	Scopes []string
+	// DPoP, if non-nil, enables DPoP-bound tokens, and configures the
+	// validation of their proofs.
+	DPoP *DPoPOptions
 }
 
 type tokenInfoKey struct{}
@@ -61,14 +75,34 @@ func TokenInfoFromContext(ctx context.Context) *TokenInfo {
 func RequireBearerToken(verifier TokenVerifier, opts *RequireBearerTokenOptions) func(http.Handler) http.Handler {
 	// Based on typescript-sdk/src/server/auth/middleware/bearerAuth.ts.
 
+	var dpop *dpopValidator
+	if opts != nil && opts.DPoP != nil {
+		dpop = newDPoPValidator(opts.DPoP)
+	}
 	return func(handler http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var dpopErr *dpopError
+			if dpop != nil {
+				dpop.setNonce(w)
+			}
 			tokenInfo, errmsg, code := verify(r, verifier, opts)
+			if code == 0 && dpop != nil {
+				if dpopErr = dpop.check(r, tokenInfo); dpopErr != nil {
+					errmsg, code = dpopErr.msg, http.StatusUnauthorized
+				}
+			}
 			if code != 0 {
-				if code == http.StatusUnauthorized || code == http.StatusForbidden {
+				if code == http.StatusForbidden {
+					// The token lacks the required scopes: challenge the client
+					// to obtain them (step-up authorization).
+					w.Header().Add("WWW-Authenticate", InsufficientScopeChallenge(opts.Scopes, opts.ResourceMetadataURL))
+				} else if code == http.StatusUnauthorized {
 					if opts != nil && opts.ResourceMetadataURL != "" {
 						w.Header().Add("WWW-Authenticate", "Bearer resource_metadata="+opts.ResourceMetadataURL)
 					}
+					if dpop != nil {
+						w.Header().Add("WWW-Authenticate", dpop.challenge(dpopErr))
+					}
 				}
 				http.Error(w, errmsg, code)
 				return
@@ -79,11 +113,43 @@ func RequireBearerToken(verifier TokenVerifier, opts *RequireBearerTokenOptions)
 	}
 }
 
+// InsufficientScopeChallenge returns the value of a WWW-Authenticate header
+// challenging the client to obtain a token with the given scopes, as described
+// in [RFC 6750 section 3.1]. If resourceMetadataURL is non-empty, it is
+// included so that the client can discover the authorization server.
+//
+// A client that receives this challenge with a 403 Forbidden response may
+// request a new token with the scopes, and retry (step-up authorization).
+//
+// [RFC 6750 section 3.1]: https://datatracker.ietf.org/doc/html/rfc6750#section-3.1
+func InsufficientScopeChallenge(scopes []string, resourceMetadataURL string) string {
+	var b strings.Builder
+	b.WriteString(`Bearer error="insufficient_scope"`)
+	if len(scopes) > 0 {
+		fmt.Fprintf(&b, `, scope="%s"`, strings.Join(scopes, " "))
+	}
+	if resourceMetadataURL != "" {
+		fmt.Fprintf(&b, `, resource_metadata="%s"`, resourceMetadataURL)
+	}
+	return b.String()
+}
+
+// HasScopes reports whether the token grants all of the given scopes.
+// A nil TokenInfo grants no scopes.
+func (ti *TokenInfo) HasScopes(scopes ...string) bool {
+	for _, s := range scopes {
+		if ti == nil || !slices.Contains(ti.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
 func verify(req *http.Request, verifier TokenVerifier, opts *RequireBearerTokenOptions) (_ *TokenInfo, errmsg string, code int) {
 	// Extract bearer token.
 	authHeader := req.Header.Get("Authorization")
 	fields := strings.Fields(authHeader)
-	if len(fields) != 2 || strings.ToLower(fields[0]) != "bearer" {
+	if len(fields) != 2 || !(strings.EqualFold(fields[0], "bearer") || strings.EqualFold(fields[0], "dpop") && opts != nil && opts.DPoP != nil) {
 		return nil, "no bearer token", http.StatusUnauthorized
 	}
 
@@ -100,13 +166,9 @@ func verify(req *http.Request, verifier TokenVerifier, opts *RequireBearerTokenO
 	}
 
 	// Check scopes. All must be present.
-	if opts != nil {
-		// Note: quadratic, but N is small.
-		for _, s := range opts.Scopes {
-			if !slices.Contains(tokenInfo.Scopes, s) {
-				return nil, "insufficient scope", http.StatusForbidden
-			}
-		}
+	// Note: quadratic, but N is small.
+	if opts != nil && !tokenInfo.HasScopes(opts.Scopes...) {
+		return nil, "insufficient scope", http.StatusForbidden
 	}
 
 	// Check expiration.
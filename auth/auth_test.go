@@ -76,3 +76,19 @@ func TestVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestInsufficientScopeChallenge(t *testing.T) {
+	for _, tt := range []struct {
+		scopes []string
+		url    string
+		want   string
+	}{
+		{nil, "", `Bearer error="insufficient_scope"`},
+		{[]string{"read", "write"}, "", `Bearer error="insufficient_scope", scope="read write"`},
+		{[]string{"read"}, "https://example.com/meta", `Bearer error="insufficient_scope", scope="read", resource_metadata="https://example.com/meta"`},
+	} {
+		if got := InsufficientScopeChallenge(tt.scopes, tt.url); got != tt.want {
+			t.Errorf("InsufficientScopeChallenge(%q, %q) = %q, want %q", tt.scopes, tt.url, got, tt.want)
+		}
+	}
+}
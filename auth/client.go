@@ -8,6 +8,8 @@ package auth
 
 import (
 	"bytes"
+	"context"
+	"crypto"
 	"errors"
 	"io"
 	"net/http"
@@ -26,7 +28,8 @@ type OAuthHandler func(req *http.Request, res *http.Response) (oauth2.TokenSourc
 // OAuth protocol when it encounters a 401 Unauthorized response.
 type HTTPTransport struct {
 	handler OAuthHandler
-	mu      sync.Mutex // protects opts.Base
+	dpop    *DPoPProver // nil if DPoP is disabled
+	mu      sync.Mutex  // protects opts.Base
 	opts    HTTPTransportOptions
 }
 
@@ -47,6 +50,13 @@ func NewHTTPTransport(handler OAuthHandler, opts *HTTPTransportOptions) (*HTTPTr
 	if t.opts.Base == nil {
 		t.opts.Base = http.DefaultTransport
 	}
+	if t.opts.DPoP || t.opts.DPoPKey != nil {
+		p, err := NewDPoPProver(t.opts.DPoPKey)
+		if err != nil {
+			return nil, err
+		}
+		t.dpop = p
+	}
 	return t, nil
 }
 
@@ -55,6 +65,14 @@ type HTTPTransportOptions struct {
 	// Base is the [http.RoundTripper] to use.
 	// If nil, [http.DefaultTransport] is used.
 	Base http.RoundTripper
+	// DPoP enables DPoP-bound tokens (RFC 9449). The transport generates a
+	// key pair, passes a [DPoPProver] for it to the handler (see
+	// [DPoPProverFromContext]), and proves possession of the key in each
+	// request authorized with a DPoP-bound token.
+	DPoP bool
+	// DPoPKey, if non-nil, enables DPoP with the given private key instead
+	// of a generated one.
+	DPoPKey crypto.Signer
 }
 
 func (t *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -82,6 +100,7 @@ func (t *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		// close it.
 		req.Body.Close() // ignore error
 		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(bodyBytes)), nil }
 	}
 
 	resp, err := base.RoundTrip(req)
@@ -105,11 +124,19 @@ func (t *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	// TODO: We hold the lock for the entire OAuth flow. This could be a long
 	// time. Is there a better way?
 	if _, ok := t.opts.Base.(*oauth2.Transport); !ok {
-		ts, err := t.handler(req, resp)
+		hreq := req
+		if t.dpop != nil {
+			hreq = req.WithContext(context.WithValue(req.Context(), dpopProverKey{}, t.dpop))
+		}
+		ts, err := t.handler(hreq, resp)
 		if err != nil {
 			return nil, err
 		}
-		t.opts.Base = &oauth2.Transport{Base: t.opts.Base, Source: ts}
+		base := t.opts.Base
+		if t.dpop != nil {
+			base = t.dpop.Transport(base)
+		}
+		t.opts.Base = &oauth2.Transport{Base: base, Source: ts}
 	}
 
 	// If we don't have a body, the request is reusable, though it will be cloned
@@ -117,6 +144,7 @@ func (t *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	if haveBody {
 		req = req.Clone(req.Context())
 		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(bodyBytes)), nil }
 	}
 
 	return t.opts.Base.RoundTrip(req)
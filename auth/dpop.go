@@ -0,0 +1,411 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// This file implements DPoP (Demonstrating Proof of Possession), which
+// binds access tokens to a key held by the client, so that leaked tokens
+// cannot be replayed. See https://www.rfc-editor.org/rfc/rfc9449.html.
+
+const (
+	dpopProofType   = "dpop+jwt"
+	dpopNonceHeader = "DPoP-Nonce"
+)
+
+// defaultDPoPAlgs are the signing algorithms of DPoP proofs accepted by
+// default.
+var defaultDPoPAlgs = []string{"ES256", "ES384", "ES512", "RS256", "EdDSA"}
+
+// DPoPOptions configure the validation of DPoP proofs by [RequireBearerToken].
+//
+// When DPoP is enabled, requests may present a DPoP-bound token with the
+// "DPoP" authorization scheme, along with a proof of possession of the key
+// to which the token is bound. The [TokenVerifier] reports the binding in
+// [TokenInfo.DPoPThumbprint]. Bound tokens presented with the "Bearer" scheme
+// are rejected.
+type DPoPOptions struct {
+	// Required rejects tokens that are not DPoP-bound.
+	Required bool
+	// SigningAlgs are the accepted signing algorithms of proofs.
+	// If empty, ES256, ES384, ES512, RS256 and EdDSA are accepted.
+	SigningAlgs []string
+	// RequireNonce requires proofs to include a nonce provided by the
+	// server in the DPoP-Nonce header (RFC 9449 section 8).
+	RequireNonce bool
+	// MaxAge is the maximum age of proofs, and the lifetime of nonces.
+	// If zero, it is five minutes.
+	MaxAge time.Duration
+	// RequestURL returns the URL of a request, to compare with the "htu"
+	// claim of its proof. If nil, the URL is reconstructed from the request,
+	// which may be incorrect behind a reverse proxy.
+	RequestURL func(*http.Request) string
+}
+
+// A dpopValidator validates DPoP proofs. It remembers the IDs of recent
+// proofs, to prevent their replay, and issues nonces.
+type dpopValidator struct {
+	opts   DPoPOptions
+	algs   []string
+	maxAge time.Duration
+
+	mu        sync.Mutex
+	nonce     string               // current nonce
+	prevNonce string               // nonce before the current one, still accepted
+	rotated   time.Time            // when nonce was created
+	jtis      map[string]time.Time // IDs of recent proofs, by issue time
+	pruned    time.Time            // when jtis was last pruned
+}
+
+func newDPoPValidator(opts *DPoPOptions) *dpopValidator {
+	v := &dpopValidator{opts: *opts, algs: opts.SigningAlgs, maxAge: opts.MaxAge, jtis: make(map[string]time.Time)}
+	if len(v.algs) == 0 {
+		v.algs = defaultDPoPAlgs
+	}
+	if v.maxAge <= 0 {
+		v.maxAge = 5 * time.Minute
+	}
+	return v
+}
+
+// A dpopError is an error of DPoP validation, with the error code to report
+// in the challenge.
+type dpopError struct {
+	code string // "invalid_token", "invalid_dpop_proof" or "use_dpop_nonce"
+	msg  string
+}
+
+func (e *dpopError) Error() string { return e.msg }
+
+// check validates the DPoP proof of req, which was authorized with a token
+// described by ti.
+func (v *dpopValidator) check(req *http.Request, ti *TokenInfo) *dpopError {
+	scheme, token, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+	token = strings.TrimSpace(token)
+	if !strings.EqualFold(scheme, "dpop") {
+		if ti.DPoPThumbprint != "" {
+			// Prevent downgrading to a bearer token (RFC 9449 section 7.2).
+			return &dpopError{"invalid_token", "DPoP-bound token used as bearer token"}
+		}
+		if v.opts.Required {
+			return &dpopError{"invalid_token", "DPoP-bound token required"}
+		}
+		return nil
+	}
+	proofs := req.Header.Values("DPoP")
+	if len(proofs) != 1 {
+		return &dpopError{"invalid_dpop_proof", "request must have exactly one DPoP proof"}
+	}
+	jkt, err := v.validate(req, proofs[0], token)
+	if err != nil {
+		return err
+	}
+	if ti.DPoPThumbprint == "" {
+		return &dpopError{"invalid_token", "token is not DPoP-bound"}
+	}
+	if jkt != ti.DPoPThumbprint {
+		return &dpopError{"invalid_token", "DPoP proof key does not match token binding"}
+	}
+	return nil
+}
+
+// dpopClaims are the claims of a DPoP proof.
+type dpopClaims struct {
+	jwt.RegisteredClaims
+	HTM   string `json:"htm"`
+	HTU   string `json:"htu"`
+	ATH   string `json:"ath,omitempty"`
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// validate checks a DPoP proof for req and its access token, as described in
+// RFC 9449 section 4.3, and returns the thumbprint of the proof's key.
+func (v *dpopValidator) validate(req *http.Request, proof, token string) (string, *dpopError) {
+	invalid := func(format string, args ...any) (string, *dpopError) {
+		return "", &dpopError{"invalid_dpop_proof", "invalid DPoP proof: " + fmt.Sprintf(format, args...)}
+	}
+	var key *jwk
+	var claims dpopClaims
+	_, err := jwt.ParseWithClaims(proof, &claims, func(t *jwt.Token) (any, error) {
+		if t.Header["typ"] != dpopProofType {
+			return nil, fmt.Errorf("typ is %v, want %s", t.Header["typ"], dpopProofType)
+		}
+		data, err := json.Marshal(t.Header["jwk"])
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &key); err != nil || key == nil {
+			return nil, errors.New("missing or malformed jwk")
+		}
+		return key.publicKey()
+	}, jwt.WithValidMethods(v.algs))
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if claims.ID == "" {
+		return invalid("missing jti")
+	}
+	if !strings.EqualFold(claims.HTM, req.Method) {
+		return invalid("htm %q does not match method %s", claims.HTM, req.Method)
+	}
+	if !sameURL(claims.HTU, v.requestURL(req)) {
+		return invalid("htu %q does not match request URL", claims.HTU)
+	}
+	if claims.ATH != accessTokenHash(token) {
+		return invalid("ath does not match access token")
+	}
+	if claims.IssuedAt == nil {
+		return invalid("missing iat")
+	}
+	now := time.Now()
+	iat := claims.IssuedAt.Time
+	if iat.Before(now.Add(-v.maxAge)) || iat.After(now.Add(time.Minute)) {
+		return invalid("iat is out of range")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.opts.RequireNonce && (claims.Nonce == "" || (claims.Nonce != v.currentNonce() && claims.Nonce != v.prevNonce)) {
+		return "", &dpopError{"use_dpop_nonce", "DPoP proof requires a valid nonce"}
+	}
+	if _, ok := v.jtis[claims.ID]; ok {
+		return invalid("replayed jti")
+	}
+	v.jtis[claims.ID] = iat
+	if now.Sub(v.pruned) > v.maxAge {
+		for jti, t := range v.jtis {
+			if t.Before(now.Add(-v.maxAge)) {
+				delete(v.jtis, jti)
+			}
+		}
+		v.pruned = now
+	}
+	return key.thumbprint(), nil
+}
+
+// currentNonce returns the nonce that clients should use, rotating it if it
+// has expired. It must be called with v.mu held.
+func (v *dpopValidator) currentNonce() string {
+	if v.nonce == "" || time.Since(v.rotated) > v.maxAge {
+		v.prevNonce = v.nonce
+		v.nonce = randomString()
+		v.rotated = time.Now()
+	}
+	return v.nonce
+}
+
+// setNonce sets the DPoP-Nonce header of the response, if nonces are
+// required.
+func (v *dpopValidator) setNonce(w http.ResponseWriter) {
+	if !v.opts.RequireNonce {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	w.Header().Set(dpopNonceHeader, v.currentNonce())
+}
+
+// challenge returns the DPoP challenge of a WWW-Authenticate header for the
+// given error, or for no error if err is nil.
+func (v *dpopValidator) challenge(err *dpopError) string {
+	c := fmt.Sprintf(`DPoP algs="%s"`, strings.Join(v.algs, " "))
+	if err != nil {
+		desc := strings.ReplaceAll(err.msg, `"`, "'")
+		c += fmt.Sprintf(`, error="%s", error_description="%s"`, err.code, desc)
+	}
+	return c
+}
+
+func (v *dpopValidator) requestURL(req *http.Request) string {
+	if v.opts.RequestURL != nil {
+		return v.opts.RequestURL(req)
+	}
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + req.Host + req.URL.EscapedPath()
+}
+
+// sameURL reports whether the URLs are equal, ignoring their query and
+// fragment, and the case of their scheme and host (RFC 9449 section 4.3).
+func sameURL(u1, u2 string) bool {
+	p1, err1 := url.Parse(u1)
+	p2, err2 := url.Parse(u2)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return strings.EqualFold(p1.Scheme, p2.Scheme) &&
+		strings.EqualFold(p1.Host, p2.Host) &&
+		p1.EscapedPath() == p2.EscapedPath()
+}
+
+// randomString returns a random string, for use as a nonce or JWT ID.
+func randomString() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+// accessTokenHash returns the "ath" claim of a proof for the access token.
+func accessTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// A jwk is a public JSON Web Key (RFC 7517).
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	D   string `json:"d,omitempty"` // private; must be empty
+}
+
+var curves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// publicJWK returns the JWK for a public key.
+func publicJWK(pub crypto.PublicKey) (*jwk, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return &jwk{
+			Kty: "EC",
+			Crv: k.Curve.Params().Name,
+			X:   b64(k.X.FillBytes(make([]byte, size))),
+			Y:   b64(k.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case *rsa.PublicKey:
+		return &jwk{Kty: "RSA", N: b64(k.N.Bytes()), E: b64(big.NewInt(int64(k.E)).Bytes())}, nil
+	case ed25519.PublicKey:
+		return &jwk{Kty: "OKP", Crv: "Ed25519", X: b64(k)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// publicKey returns the public key of k.
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	if k.D != "" {
+		return nil, errors.New("jwk contains a private key")
+	}
+	decode := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil || len(b) == 0 {
+			return nil, errors.New("malformed jwk")
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+	switch k.Kty {
+	case "EC":
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if _, err := pub.ECDH(); err != nil {
+			return nil, err
+		}
+		return pub, nil
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("unsupported or malformed OKP key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// thumbprint returns the JWK SHA-256 thumbprint of k (RFC 7638).
+func (k *jwk) thumbprint() string {
+	// The members are required members of the key type, in lexicographic
+	// order. Their values need no escaping.
+	var s string
+	switch k.Kty {
+	case "EC":
+		s = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, k.Crv, k.X, k.Y)
+	case "RSA":
+		s = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, k.E, k.N)
+	case "OKP":
+		s = fmt.Sprintf(`{"crv":"%s","kty":"OKP","x":"%s"}`, k.Crv, k.X)
+	}
+	sum := sha256.Sum256([]byte(s))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// signingMethod returns the JWS signing method for the private key.
+func signingMethod(key crypto.Signer) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case nil:
+		return nil, errors.New("missing private key")
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		default:
+			return nil, fmt.Errorf("unsupported elliptic curve %s", k.Curve.Params().Name)
+		}
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
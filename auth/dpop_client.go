@@ -0,0 +1,213 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// A DPoPProver proves possession of a private key to authorization and
+// resource servers, by signing a DPoP proof for each request (RFC 9449).
+// Access tokens obtained with its proofs are bound to its key, and are
+// useless without it.
+//
+// An [HTTPTransport] with DPoP enabled passes its prover to its
+// [OAuthHandler] in the request context; see [DPoPProverFromContext].
+type DPoPProver struct {
+	key    crypto.Signer
+	method jwt.SigningMethod
+	jwk    *jwk
+
+	mu     sync.Mutex
+	nonces map[string]string // latest nonce, by origin
+}
+
+// NewDPoPProver returns a prover for the given private key, which must be
+// an *rsa.PrivateKey, an *ecdsa.PrivateKey, or an ed25519.PrivateKey.
+// If key is nil, a new P-256 key pair is generated.
+func NewDPoPProver(key crypto.Signer) (*DPoPProver, error) {
+	if key == nil {
+		var err error
+		if key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			return nil, err
+		}
+	}
+	method, err := signingMethod(key)
+	if err != nil {
+		return nil, err
+	}
+	k, err := publicJWK(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &DPoPProver{key: key, method: method, jwk: k, nonces: make(map[string]string)}, nil
+}
+
+// Alg returns the signing algorithm of the prover's proofs, such as "ES256".
+func (p *DPoPProver) Alg() string {
+	return p.method.Alg()
+}
+
+// Thumbprint returns the JWK SHA-256 thumbprint of the prover's public key
+// (RFC 7638), to which its tokens are bound.
+func (p *DPoPProver) Thumbprint() string {
+	return p.jwk.thumbprint()
+}
+
+// Proof returns a DPoP proof for a request with the given method and URL.
+// If accessToken is non-empty, the proof is bound to it. The proof includes
+// the latest nonce provided by the URL's server, if any.
+func (p *DPoPProver) Proof(method, rawURL, accessToken string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	htu := url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path, RawPath: u.RawPath}
+	claims := jwt.MapClaims{
+		"jti": randomString(),
+		"htm": method,
+		"htu": htu.String(),
+		"iat": time.Now().Unix(),
+	}
+	if accessToken != "" {
+		claims["ath"] = accessTokenHash(accessToken)
+	}
+	p.mu.Lock()
+	nonce := p.nonces[origin(u)]
+	p.mu.Unlock()
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	tok := jwt.NewWithClaims(p.method, claims)
+	tok.Header["typ"] = dpopProofType
+	tok.Header["jwk"] = p.jwk
+	return tok.SignedString(p.key)
+}
+
+// Transport returns an [http.RoundTripper] that adds a DPoP proof to each
+// request made with base, and retries requests once when the server
+// requires a new nonce.
+//
+// Requests authorized with the "DPoP" scheme get proofs bound to their
+// access token. Requests authorized with the "Bearer" scheme get no proof.
+// Other requests, such as requests to a token endpoint, get unbound proofs.
+func (p *DPoPProver) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &dpopTransport{p: p, base: base}
+}
+
+type dpopTransport struct {
+	p    *DPoPProver
+	base http.RoundTripper
+}
+
+func (t *dpopTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	scheme, token, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+	if strings.EqualFold(scheme, "bearer") {
+		return t.base.RoundTrip(req)
+	}
+	if !strings.EqualFold(scheme, "dpop") {
+		token = ""
+	}
+	send := func(req *http.Request) (*http.Response, error) {
+		proof, err := t.p.Proof(req.Method, req.URL.String(), strings.TrimSpace(token))
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Header.Set("DPoP", proof)
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		if nonce := resp.Header.Get(dpopNonceHeader); nonce != "" {
+			t.p.mu.Lock()
+			t.p.nonces[origin(req.URL)] = nonce
+			t.p.mu.Unlock()
+		}
+		return resp, nil
+	}
+	resp, err := send(req)
+	if err != nil || !needsNonce(resp) {
+		return resp, err
+	}
+	// Retry with the new nonce, if we can replay the body.
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return resp, nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+	resp.Body.Close()
+	return send(req)
+}
+
+// needsNonce reports whether resp asks for a proof with the nonce in its
+// DPoP-Nonce header: from a resource server, with a challenge (RFC 9449
+// section 9), or from an authorization server, with an error response
+// (section 8).
+func needsNonce(resp *http.Response) bool {
+	if resp.Header.Get(dpopNonceHeader) == "" {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		for _, h := range resp.Header.Values("WWW-Authenticate") {
+			if strings.Contains(h, "use_dpop_nonce") {
+				return true
+			}
+		}
+	case http.StatusBadRequest:
+		// Read the error, keeping the body readable. Error responses are small.
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		var oerr struct {
+			Error string `json:"error"`
+		}
+		return err == nil && json.Unmarshal(data, &oerr) == nil && oerr.Error == "use_dpop_nonce"
+	}
+	return false
+}
+
+// origin returns the origin of u, which identifies the server that provides
+// nonces.
+func origin(u *url.URL) string {
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+type dpopProverKey struct{}
+
+// DPoPProverFromContext returns the [DPoPProver] stored in ctx, or nil if
+// none. An [HTTPTransport] with DPoP enabled stores its prover in the context
+// of the request passed to its [OAuthHandler], so that the handler can
+// request tokens bound to the prover's key.
+func DPoPProverFromContext(ctx context.Context) *DPoPProver {
+	p, _ := ctx.Value(dpopProverKey{}).(*DPoPProver)
+	return p
+}
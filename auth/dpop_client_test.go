@@ -0,0 +1,145 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/modelcontextprotocol/go-sdk/oauthex"
+	"golang.org/x/oauth2"
+)
+
+// TestDPoPTransport checks that an HTTPTransport with DPoP enabled obtains a
+// DPoP-bound token with the client credentials grant, and uses it to access a
+// resource protected by RequireBearerToken. Both servers require nonces.
+func TestDPoPTransport(t *testing.T) {
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+	writeJSON := func(w http.ResponseWriter, code int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(v)
+	}
+
+	// The resource accepts tokens of the form "token:<jkt>", bound to the key
+	// with thumbprint jkt.
+	verifier := func(_ context.Context, token string, _ *http.Request) (*TokenInfo, error) {
+		jkt, ok := strings.CutPrefix(token, "token:")
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		return &TokenInfo{DPoPThumbprint: jkt, Expiration: time.Now().Add(time.Hour)}, nil
+	}
+	var resourceRequests atomic.Int32
+	mux.Handle("/mcp", RequireBearerToken(verifier, &RequireBearerTokenOptions{
+		ResourceMetadataURL: server.URL + "/.well-known/oauth-protected-resource/mcp",
+		DPoP:                &DPoPOptions{Required: true, RequireNonce: true},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resourceRequests.Add(1)
+	})))
+	mux.Handle("/.well-known/oauth-protected-resource/mcp", ProtectedResourceMetadataHandler(&oauthex.ProtectedResourceMetadata{
+		Resource:             server.URL + "/mcp",
+		AuthorizationServers: []string{server.URL},
+	}))
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, &oauthex.AuthServerMeta{
+			Issuer:                        server.URL,
+			TokenEndpoint:                 server.URL + "/token",
+			CodeChallengeMethodsSupported: []string{"S256"},
+			DPOPSigningAlgValuesSupported: []string{"ES256"},
+		})
+	})
+	// The token endpoint requires a proof with the nonce "as-nonce", and
+	// binds the token to the proof's key.
+	var tokenRequests atomic.Int32
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests.Add(1)
+		var claims dpopClaims
+		var key *jwk
+		_, err := jwt.ParseWithClaims(r.Header.Get("DPoP"), &claims, func(tok *jwt.Token) (any, error) {
+			data, _ := json.Marshal(tok.Header["jwk"])
+			if err := json.Unmarshal(data, &key); err != nil || key == nil {
+				return nil, errors.New("bad jwk")
+			}
+			return key.publicKey()
+		})
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_dpop_proof", "error_description": err.Error()})
+			return
+		}
+		if claims.Nonce != "as-nonce" {
+			w.Header().Set("DPoP-Nonce", "as-nonce")
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "use_dpop_nonce"})
+			return
+		}
+		if claims.HTM != http.MethodPost || claims.HTU != server.URL+"/token" || claims.ATH != "" {
+			t.Errorf("token request proof has claims %+v", claims)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token": "token:" + key.thumbprint(),
+			"token_type":   "DPoP",
+			"expires_in":   3600,
+		})
+	})
+
+	var prover *DPoPProver
+	cc, err := NewClientCredentialsHandler(&ClientCredentialsOptions{
+		ClientID:   "agent",
+		PrivateKey: clientKey,
+		HTTPClient: server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := func(req *http.Request, res *http.Response) (oauth2.TokenSource, error) {
+		prover = DPoPProverFromContext(req.Context())
+		return cc(req, res)
+	}
+	transport, err := NewHTTPTransport(handler, &HTTPTransportOptions{Base: server.Client().Transport, DPoP: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: transport}
+	for i := range 2 {
+		resp, err := client.Post(server.URL+"/mcp", "application/json", strings.NewReader(fmt.Sprint(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: got status %d", i, resp.StatusCode)
+		}
+	}
+	if prover == nil {
+		t.Fatal("handler got no DPoP prover")
+	}
+	// One token request without the nonce, and one with it.
+	if got := tokenRequests.Load(); got != 2 {
+		t.Errorf("got %d token requests, want 2", got)
+	}
+	if got := resourceRequests.Load(); got != 2 {
+		t.Errorf("resource served %d requests, want 2", got)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestJWKThumbprint(t *testing.T) {
+	// The example of RFC 7638 section 3.1.
+	k := &jwk{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	}
+	if got, want := k.thumbprint(), "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Errorf("thumbprint() = %q, want %q", got, want)
+	}
+	pub, err := k.publicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2, err := publicJWK(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *k2 != *k {
+		t.Errorf("publicJWK(publicKey()) = %+v, want %+v", k2, k)
+	}
+}
+
+// dpopProof returns a DPoP proof signed by key, with the given claims in
+// addition to a fresh jti and iat.
+func dpopProof(t *testing.T, key crypto.Signer, claims jwt.MapClaims) string {
+	t.Helper()
+	method, err := signingMethod(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := publicJWK(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := jwt.MapClaims{"jti": randomString(), "iat": time.Now().Unix()}
+	for name, v := range claims {
+		all[name] = v
+	}
+	tok := jwt.NewWithClaims(method, all)
+	tok.Header["typ"] = dpopProofType
+	tok.Header["jwk"] = k
+	s, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRequireBearerTokenDPoP(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := publicJWK(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	jkt := k.thumbprint()
+
+	// Tokens named "bound" are bound to key.
+	verifier := func(_ context.Context, token string, _ *http.Request) (*TokenInfo, error) {
+		ti := &TokenInfo{Expiration: time.Now().Add(time.Hour)}
+		if strings.HasPrefix(token, "bound") {
+			ti.DPoPThumbprint = jkt
+		}
+		return ti, nil
+	}
+	const target = "http://example.com/mcp"
+	newRequest := func(scheme, token, proof string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		req.Header.Set("Authorization", scheme+" "+token)
+		if proof != "" {
+			req.Header.Set("DPoP", proof)
+		}
+		return req
+	}
+	claims := func(token string) jwt.MapClaims {
+		return jwt.MapClaims{"htm": "POST", "htu": target, "ath": accessTokenHash(token)}
+	}
+
+	handler := RequireBearerToken(verifier, &RequireBearerTokenOptions{DPoP: &DPoPOptions{}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	replayed := dpopProof(t, key, claims("bound"))
+	for _, tt := range []struct {
+		name    string
+		req     *http.Request
+		wantErr string // expected error in the DPoP challenge, or "" for success
+	}{
+		{"valid", newRequest("DPoP", "bound", dpopProof(t, key, claims("bound"))), ""},
+		{"unbound bearer", newRequest("Bearer", "unbound", ""), ""},
+		{"bound bearer", newRequest("Bearer", "bound", ""), "invalid_token"},
+		{"unbound dpop", newRequest("DPoP", "unbound", dpopProof(t, key, claims("unbound"))), "invalid_token"},
+		{"missing proof", newRequest("DPoP", "bound", ""), "invalid_dpop_proof"},
+		{"wrong key", newRequest("DPoP", "bound", dpopProof(t, otherKey, claims("bound"))), "invalid_token"},
+		{"wrong method", newRequest("DPoP", "bound", dpopProof(t, key, jwt.MapClaims{"htm": "GET", "htu": target, "ath": accessTokenHash("bound")})), "invalid_dpop_proof"},
+		{"wrong URL", newRequest("DPoP", "bound", dpopProof(t, key, jwt.MapClaims{"htm": "POST", "htu": "http://example.com/other", "ath": accessTokenHash("bound")})), "invalid_dpop_proof"},
+		{"wrong token", newRequest("DPoP", "bound", dpopProof(t, key, claims("bound2"))), "invalid_dpop_proof"},
+		{"stale", newRequest("DPoP", "bound", dpopProof(t, key, jwt.MapClaims{"htm": "POST", "htu": target, "ath": accessTokenHash("bound"), "iat": time.Now().Add(-time.Hour).Unix()})), "invalid_dpop_proof"},
+		{"first use", newRequest("DPoP", "bound", replayed), ""},
+		{"replayed", newRequest("DPoP", "bound", replayed), "invalid_dpop_proof"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, tt.req)
+			if tt.wantErr == "" {
+				if w.Code != http.StatusOK {
+					t.Errorf("got status %d (%s), want success", w.Code, w.Body)
+				}
+				return
+			}
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("got status %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); !strings.Contains(got, `error="`+tt.wantErr+`"`) {
+				t.Errorf("WWW-Authenticate = %q, want error %q", got, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("required", func(t *testing.T) {
+		handler := RequireBearerToken(verifier, &RequireBearerTokenOptions{DPoP: &DPoPOptions{Required: true}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest("Bearer", "unbound", ""))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("unbound token: got status %d, want %d", w.Code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("nonce", func(t *testing.T) {
+		handler := RequireBearerToken(verifier, &RequireBearerTokenOptions{DPoP: &DPoPOptions{RequireNonce: true}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest("DPoP", "bound", dpopProof(t, key, claims("bound"))))
+		if w.Code != http.StatusUnauthorized || !strings.Contains(w.Header().Get("WWW-Authenticate"), `error="use_dpop_nonce"`) {
+			t.Fatalf("without nonce: got status %d, WWW-Authenticate %q", w.Code, w.Header().Get("WWW-Authenticate"))
+		}
+		nonce := w.Header().Get("DPoP-Nonce")
+		if nonce == "" {
+			t.Fatal("no DPoP-Nonce header")
+		}
+		c := claims("bound")
+		c["nonce"] = nonce
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest("DPoP", "bound", dpopProof(t, key, c)))
+		if w.Code != http.StatusOK {
+			t.Errorf("with nonce: got status %d (%s)", w.Code, w.Body)
+		}
+	})
+}
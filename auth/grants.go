@@ -0,0 +1,344 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/modelcontextprotocol/go-sdk/oauthex"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// This file implements OAuth grants for clients that act without a user
+// present: the client credentials grant (RFC 6749 section 4.4) and token
+// exchange (RFC 8693).
+
+const (
+	grantTypeClientCredentials = "client_credentials"
+	grantTypeTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+	clientAssertionTypeJWT     = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	authMethodPrivateKeyJWT    = "private_key_jwt"
+
+	// TokenTypeAccessToken is the token type URI of OAuth 2.0 access tokens
+	// (RFC 8693 section 3).
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// ClientCredentialsOptions are options for [NewClientCredentialsHandler].
+type ClientCredentialsOptions struct {
+	// ClientID is the client's identifier at the authorization server.
+	// Required.
+	ClientID string
+	// PrivateKey is the key with which the client authenticates to the token
+	// endpoint, by signing a JWT assertion ("private_key_jwt", RFC 7523
+	// section 2.2). It must be an *rsa.PrivateKey, an *ecdsa.PrivateKey, or
+	// an ed25519.PrivateKey. Required.
+	PrivateKey crypto.Signer
+	// KeyID, if set, is the "kid" header of the assertion, identifying the
+	// public key registered with the authorization server.
+	KeyID string
+	// Scopes are the scopes to request. If empty, the scopes in the
+	// resource server's challenge are requested or, if there are none, the
+	// scopes supported by the resource.
+	Scopes []string
+	// HTTPClient is used to fetch metadata and tokens.
+	// If nil, [http.DefaultClient] is used.
+	HTTPClient *http.Client
+}
+
+// NewClientCredentialsHandler returns an [OAuthHandler] that obtains tokens
+// with the client credentials grant, for clients that access resources on
+// their own behalf, such as backend agents.
+//
+// The handler discovers the authorization server from the protected resource
+// metadata of the resource server that rejected the request (see
+// [oauthex.GetProtectedResourceMetadataFromHeader]), and its token endpoint
+// with [oauthex.GetAuthServerMeta]. Tokens are requested for the resource
+// identified in the metadata, using the "resource" parameter of RFC 8707, so
+// that they are restricted to that audience. When a token expires, a new one
+// is requested.
+//
+// If the handler is used by an [HTTPTransport] with DPoP enabled, and the
+// authorization server supports DPoP, tokens are bound to the transport's key.
+func NewClientCredentialsHandler(opts *ClientCredentialsOptions) (OAuthHandler, error) {
+	if opts == nil {
+		return nil, errors.New("NewClientCredentialsHandler: nil options")
+	}
+	signer, err := newAssertionSigner(opts.ClientID, opts.PrivateKey, opts.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("NewClientCredentialsHandler: %w", err)
+	}
+	return func(req *http.Request, res *http.Response) (oauth2.TokenSource, error) {
+		g, err := discoverGrant(req, res, grantTypeClientCredentials, signer, opts.Scopes, opts.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		return g.tokenSource(nil)
+	}, nil
+}
+
+// TokenExchangeOptions are options for [NewTokenExchangeHandler].
+type TokenExchangeOptions struct {
+	// ClientID is the client's identifier at the authorization server.
+	// Required.
+	ClientID string
+	// PrivateKey and KeyID authenticate the client to the token endpoint, as
+	// for [ClientCredentialsOptions]. PrivateKey is required.
+	PrivateKey crypto.Signer
+	KeyID      string
+	// SubjectToken provides the token to exchange, typically the token with
+	// which the user called the client. Required.
+	SubjectToken oauth2.TokenSource
+	// SubjectTokenType is the type of the subject token.
+	// If empty, [TokenTypeAccessToken] is used.
+	SubjectTokenType string
+	// Scopes are the scopes to request, as for [ClientCredentialsOptions].
+	Scopes []string
+	// HTTPClient is used to fetch metadata and tokens.
+	// If nil, [http.DefaultClient] is used.
+	HTTPClient *http.Client
+}
+
+// NewTokenExchangeHandler returns an [OAuthHandler] that obtains tokens with
+// the token exchange grant of RFC 8693, for clients that access resources on
+// behalf of a user, such as gateways calling downstream servers.
+//
+// The user's token, provided by opts.SubjectToken, is exchanged for a token
+// restricted to the resource server that rejected the request. The
+// authorization server and resource are discovered as for
+// [NewClientCredentialsHandler]. When the issued token expires, a new subject
+// token is exchanged.
+func NewTokenExchangeHandler(opts *TokenExchangeOptions) (OAuthHandler, error) {
+	if opts == nil {
+		return nil, errors.New("NewTokenExchangeHandler: nil options")
+	}
+	if opts.SubjectToken == nil {
+		return nil, errors.New("NewTokenExchangeHandler: missing subject token")
+	}
+	signer, err := newAssertionSigner(opts.ClientID, opts.PrivateKey, opts.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("NewTokenExchangeHandler: %w", err)
+	}
+	subjectTokenType := opts.SubjectTokenType
+	if subjectTokenType == "" {
+		subjectTokenType = TokenTypeAccessToken
+	}
+	return func(req *http.Request, res *http.Response) (oauth2.TokenSource, error) {
+		g, err := discoverGrant(req, res, grantTypeTokenExchange, signer, opts.Scopes, opts.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		return g.tokenSource(func() (url.Values, error) {
+			tok, err := opts.SubjectToken.Token()
+			if err != nil {
+				return nil, fmt.Errorf("getting subject token: %w", err)
+			}
+			return url.Values{
+				"subject_token":        {tok.AccessToken},
+				"subject_token_type":   {subjectTokenType},
+				"requested_token_type": {TokenTypeAccessToken},
+			}, nil
+		})
+	}, nil
+}
+
+// A grant holds what is needed to request tokens for a resource from its
+// authorization server.
+type grant struct {
+	ctx       context.Context
+	grantType string
+	tokenURL  string
+	resource  string
+	scopes    []string
+	signer    *assertionSigner
+}
+
+// discoverGrant discovers the authorization server of the resource server
+// that responded to req with res, and checks that it supports grantType with
+// private_key_jwt client authentication.
+func discoverGrant(req *http.Request, res *http.Response, grantType string, signer *assertionSigner, scopes []string, c *http.Client) (*grant, error) {
+	// The token source outlives the request.
+	ctx := context.WithoutCancel(req.Context())
+	if c != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, c)
+	}
+	serverURL := *req.URL
+	serverURL.RawQuery = ""
+	serverURL.Fragment = ""
+	prm, err := oauthex.GetProtectedResourceMetadataFromHeader(ctx, serverURL.String(), res.Header, c)
+	if err != nil {
+		return nil, err
+	}
+	if prm == nil {
+		// The challenge has no metadata URL: try the well-known location.
+		if prm, err = oauthex.GetProtectedResourceMetadataFromID(ctx, serverURL.String(), c); err != nil {
+			return nil, err
+		}
+	}
+	if len(prm.AuthorizationServers) == 0 {
+		return nil, fmt.Errorf("resource %q lists no authorization servers", prm.Resource)
+	}
+	asm, err := oauthex.GetAuthServerMeta(ctx, prm.AuthorizationServers[0], c)
+	if err != nil {
+		return nil, err
+	}
+	if asm.TokenEndpoint == "" {
+		return nil, fmt.Errorf("authorization server %q has no token endpoint", asm.Issuer)
+	}
+	if len(asm.GrantTypesSupported) > 0 && !slices.Contains(asm.GrantTypesSupported, grantType) {
+		return nil, fmt.Errorf("authorization server %q does not support grant type %q", asm.Issuer, grantType)
+	}
+	if len(asm.TokenEndpointAuthMethodsSupported) > 0 && !slices.Contains(asm.TokenEndpointAuthMethodsSupported, authMethodPrivateKeyJWT) {
+		return nil, fmt.Errorf("authorization server %q does not support %s client authentication", asm.Issuer, authMethodPrivateKeyJWT)
+	}
+	if algs := asm.TokenEndpointAuthSigningAlgValuesSupported; len(algs) > 0 && !slices.Contains(algs, signer.method.Alg()) {
+		return nil, fmt.Errorf("authorization server %q does not support signing algorithm %s", asm.Issuer, signer.method.Alg())
+	}
+	if len(scopes) == 0 {
+		scopes = challengeScopes(res.Header)
+	}
+	if len(scopes) == 0 {
+		scopes = prm.ScopesSupported
+	}
+	// Bind tokens to the transport's DPoP key, if the authorization server
+	// supports DPoP.
+	if p := DPoPProverFromContext(req.Context()); p != nil && len(asm.DPOPSigningAlgValuesSupported) > 0 {
+		if !slices.Contains(asm.DPOPSigningAlgValuesSupported, p.Alg()) {
+			return nil, fmt.Errorf("authorization server %q does not support DPoP signing algorithm %s", asm.Issuer, p.Alg())
+		}
+		if c == nil {
+			c = http.DefaultClient
+		}
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{
+			Transport:     p.Transport(c.Transport),
+			CheckRedirect: c.CheckRedirect,
+			Jar:           c.Jar,
+			Timeout:       c.Timeout,
+		})
+	}
+	return &grant{
+		ctx:       ctx,
+		grantType: grantType,
+		tokenURL:  asm.TokenEndpoint,
+		resource:  prm.Resource,
+		scopes:    scopes,
+		signer:    signer,
+	}, nil
+}
+
+// challengeScopes returns the scopes in the Bearer or DPoP challenge of the
+// WWW-Authenticate headers in h, if any.
+func challengeScopes(h http.Header) []string {
+	cs, err := oauthex.ParseWWWAuthenticate(h.Values("WWW-Authenticate"))
+	if err != nil {
+		return nil
+	}
+	for _, c := range cs {
+		if (c.Scheme == "bearer" || c.Scheme == "dpop") && c.Params["scope"] != "" {
+			return strings.Fields(c.Params["scope"])
+		}
+	}
+	return nil
+}
+
+// tokenSource returns a token source for g, after obtaining its first token.
+// If params is non-nil, it is called for additional parameters before each
+// token request.
+func (g *grant) tokenSource(params func() (url.Values, error)) (oauth2.TokenSource, error) {
+	ts := &grantTokenSource{g: g, params: params}
+	tok, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.ReuseTokenSource(tok, ts), nil
+}
+
+// A grantTokenSource requests a new token from the token endpoint each time
+// it is called.
+type grantTokenSource struct {
+	g      *grant
+	params func() (url.Values, error)
+}
+
+func (ts *grantTokenSource) Token() (*oauth2.Token, error) {
+	g := ts.g
+	// The assertion must be fresh for each request.
+	assertion, err := g.signer.sign(g.tokenURL)
+	if err != nil {
+		return nil, err
+	}
+	v := url.Values{
+		"grant_type":            {g.grantType},
+		"resource":              {g.resource},
+		"client_assertion_type": {clientAssertionTypeJWT},
+		"client_assertion":      {assertion},
+	}
+	if ts.params != nil {
+		extra, err := ts.params()
+		if err != nil {
+			return nil, err
+		}
+		for k, vs := range extra {
+			v[k] = vs
+		}
+	}
+	cfg := &clientcredentials.Config{
+		ClientID:       g.signer.clientID,
+		TokenURL:       g.tokenURL,
+		Scopes:         g.scopes,
+		EndpointParams: v,
+		AuthStyle:      oauth2.AuthStyleInParams,
+	}
+	return cfg.Token(g.ctx)
+}
+
+// An assertionSigner signs JWT assertions that authenticate a client
+// (RFC 7523 section 2.2).
+type assertionSigner struct {
+	clientID string
+	key      crypto.Signer
+	keyID    string
+	method   jwt.SigningMethod
+}
+
+func newAssertionSigner(clientID string, key crypto.Signer, keyID string) (*assertionSigner, error) {
+	if clientID == "" {
+		return nil, errors.New("missing client ID")
+	}
+	method, err := signingMethod(key)
+	if err != nil {
+		return nil, err
+	}
+	return &assertionSigner{clientID: clientID, key: key, keyID: keyID, method: method}, nil
+}
+
+// sign returns a new assertion for the given audience.
+func (s *assertionSigner) sign(audience string) (string, error) {
+	now := time.Now()
+	tok := jwt.NewWithClaims(s.method, jwt.RegisteredClaims{
+		Issuer:    s.clientID,
+		Subject:   s.clientID,
+		Audience:  jwt.ClaimStrings{audience},
+		ID:        randomString(),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+	})
+	if s.keyID != "" {
+		tok.Header["kid"] = s.keyID
+	}
+	return tok.SignedString(s.key)
+}
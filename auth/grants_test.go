@@ -0,0 +1,159 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/modelcontextprotocol/go-sdk/oauthex"
+	"golang.org/x/oauth2"
+)
+
+// newGrantServer returns a server that acts as both a resource server, at
+// /mcp, and its authorization server. The token endpoint checks the client
+// assertion against key, and issues tokens that encode the request.
+func newGrantServer(t *testing.T, key crypto.PublicKey) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	resource := server.URL + "/mcp"
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer resource_metadata="%s/.well-known/oauth-protected-resource/mcp", scope="tools"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, r.Header.Get("Authorization"))
+	})
+	mux.HandleFunc("/.well-known/oauth-protected-resource/mcp", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, &oauthex.ProtectedResourceMetadata{
+			Resource:             resource,
+			AuthorizationServers: []string{server.URL},
+		})
+	})
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, &oauthex.AuthServerMeta{
+			Issuer:                            server.URL,
+			TokenEndpoint:                     server.URL + "/token",
+			GrantTypesSupported:               []string{grantTypeClientCredentials, grantTypeTokenExchange},
+			TokenEndpointAuthMethodsSupported: []string{authMethodPrivateKeyJWT},
+			CodeChallengeMethodsSupported:     []string{"S256"},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		fail := func(format string, args ...any) {
+			t.Errorf(format, args...)
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]string{"error": "invalid_request"})
+		}
+		if got := r.PostForm.Get("client_assertion_type"); got != clientAssertionTypeJWT {
+			fail("client_assertion_type = %q", got)
+			return
+		}
+		var claims jwt.RegisteredClaims
+		if _, err := jwt.ParseWithClaims(r.PostForm.Get("client_assertion"), &claims, func(*jwt.Token) (any, error) { return key, nil },
+			jwt.WithAudience(server.URL+"/token"), jwt.WithIssuer("agent"), jwt.WithSubject("agent"), jwt.WithExpirationRequired()); err != nil {
+			fail("invalid client assertion: %v", err)
+			return
+		}
+		if got := r.PostForm.Get("resource"); got != resource {
+			fail("resource = %q, want %q", got, resource)
+			return
+		}
+		token := r.PostForm.Get("grant_type") + ":" + r.PostForm.Get("scope")
+		if r.PostForm.Get("grant_type") == grantTypeTokenExchange {
+			if got := r.PostForm.Get("subject_token_type"); got != TokenTypeAccessToken {
+				fail("subject_token_type = %q", got)
+				return
+			}
+			token += ":" + r.PostForm.Get("subject_token")
+		}
+		writeJSON(w, map[string]any{
+			"access_token":      token,
+			"token_type":        "Bearer",
+			"expires_in":        3600,
+			"issued_token_type": TokenTypeAccessToken,
+		})
+	})
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGrantHandlers(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newGrantServer(t, key.Public())
+
+	clientCredentials, err := NewClientCredentialsHandler(&ClientCredentialsOptions{
+		ClientID:   "agent",
+		PrivateKey: key,
+		HTTPClient: server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenExchange, err := NewTokenExchangeHandler(&TokenExchangeOptions{
+		ClientID:     "agent",
+		PrivateKey:   key,
+		SubjectToken: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "user-token"}),
+		Scopes:       []string{"a", "b"},
+		HTTPClient:   server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name      string
+		handler   OAuthHandler
+		wantToken string
+	}{
+		{"client credentials", clientCredentials, "Bearer client_credentials:tools"},
+		{"token exchange", tokenExchange, "Bearer " + grantTypeTokenExchange + ":a b:user-token"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := NewHTTPTransport(tt.handler, &HTTPTransportOptions{Base: server.Client().Transport})
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &http.Client{Transport: transport}
+			resp, err := client.Get(server.URL + "/mcp")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status %d: %s", resp.StatusCode, body)
+			}
+			if got := string(body); got != tt.wantToken {
+				t.Errorf("resource got authorization %q, want %q", got, tt.wantToken)
+			}
+		})
+	}
+}
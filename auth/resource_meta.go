@@ -0,0 +1,197 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/oauthex"
+)
+
+// This file implements serving Protected Resource Metadata.
+// See https://www.rfc-editor.org/rfc/rfc9728.html.
+
+const protectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+// ProtectedResourceMetadataPath returns the path of the well-known location of
+// the metadata for the protected resource with the given identifier, as
+// described in RFC 9728 section 3.1. For example, the metadata for
+//
+//	https://example.com/mcp
+//
+// is located at
+//
+//	/.well-known/oauth-protected-resource/mcp
+//
+// The resource identifier must be an absolute URL without a fragment.
+func ProtectedResourceMetadataPath(resource string) (string, error) {
+	u, err := parseResource(resource)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(protectedResourceMetadataPath, u.Path), nil
+}
+
+// ProtectedResourceMetadataURL returns the URL of the well-known location of the
+// metadata for the protected resource with the given identifier. See
+// [ProtectedResourceMetadataPath].
+func ProtectedResourceMetadataURL(resource string) (string, error) {
+	u, err := parseResource(resource)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(protectedResourceMetadataPath, u.Path)
+	u.RawPath = ""
+	u.RawQuery = ""
+	return u.String(), nil
+}
+
+// parseResource parses a resource identifier, and checks that it is valid
+// (RFC 9728 section 1.2).
+func parseResource(resource string) (*url.URL, error) {
+	u, err := url.Parse(resource)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource identifier %q: %w", resource, err)
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return nil, fmt.Errorf("resource identifier %q is not an absolute URL", resource)
+	}
+	if u.Fragment != "" {
+		return nil, fmt.Errorf("resource identifier %q has a fragment", resource)
+	}
+	return u, nil
+}
+
+// ProtectedResourceMetadataHandler returns an [http.Handler] that serves the
+// given metadata as a JSON document, as described in RFC 9728 section 3.2.
+// The handler should be registered at the path returned by
+// [ProtectedResourceMetadataPath] for the metadata's resource.
+//
+// Since the metadata is public, it may be fetched by browser-based clients
+// from any origin. The handler must not be wrapped by [RequireBearerToken].
+func ProtectedResourceMetadataHandler(metadata *oauthex.ProtectedResourceMetadata) http.Handler {
+	data, err := json.Marshal(metadata)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		switch req.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodOptions:
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// A ProtectedResource is an OAuth 2.0 protected resource, such as an MCP
+// server. It combines the resource's metadata with the requirements for
+// accessing it, so that the metadata served to clients, the challenges in
+// WWW-Authenticate headers, and the scopes checked on requests all agree.
+//
+// A typical server registers its MCP handler with [ProtectedResource.Handle]:
+//
+//	pr, err := auth.NewProtectedResource(&oauthex.ProtectedResourceMetadata{
+//		Resource:             "https://example.com/mcp",
+//		AuthorizationServers: []string{"https://auth.example.com"},
+//		ScopesSupported:      []string{"read", "write"},
+//	}, "read")
+//	...
+//	pr.Handle(mux, "/mcp", verifier, mcpHandler)
+type ProtectedResource struct {
+	metadata    oauthex.ProtectedResourceMetadata
+	metadataURL string
+	path        string // path of the metadata
+	scopes      []string
+}
+
+// NewProtectedResource returns a ProtectedResource described by metadata, that
+// requires an access token with all of the given scopes for every request.
+//
+// It reports an error if the metadata's resource identifier is invalid (see
+// [ProtectedResourceMetadataPath]), or if it lists supported scopes that do
+// not include the required scopes. If the metadata lists no supported scopes,
+// the served metadata advertises the required scopes.
+func NewProtectedResource(metadata *oauthex.ProtectedResourceMetadata, scopes ...string) (*ProtectedResource, error) {
+	if metadata == nil {
+		return nil, errors.New("NewProtectedResource: nil metadata")
+	}
+	p := &ProtectedResource{metadata: *metadata, scopes: slices.Clone(scopes)}
+	var err error
+	if p.path, err = ProtectedResourceMetadataPath(metadata.Resource); err != nil {
+		return nil, fmt.Errorf("NewProtectedResource: %w", err)
+	}
+	if p.metadataURL, err = ProtectedResourceMetadataURL(metadata.Resource); err != nil {
+		return nil, fmt.Errorf("NewProtectedResource: %w", err)
+	}
+	if len(metadata.ScopesSupported) == 0 {
+		p.metadata.ScopesSupported = p.scopes
+	} else {
+		for _, s := range scopes {
+			if !slices.Contains(metadata.ScopesSupported, s) {
+				return nil, fmt.Errorf("NewProtectedResource: required scope %q is not supported", s)
+			}
+		}
+	}
+	return p, nil
+}
+
+// MetadataURL returns the URL of the resource's metadata, for use in
+// WWW-Authenticate challenges (for example, in the ResourceMetadataURL
+// field of mcp.StreamableHTTPOptions).
+func (p *ProtectedResource) MetadataURL() string {
+	return p.metadataURL
+}
+
+// MetadataHandler returns an [http.Handler] that serves the resource's
+// metadata. See [ProtectedResourceMetadataHandler].
+func (p *ProtectedResource) MetadataHandler() http.Handler {
+	return ProtectedResourceMetadataHandler(&p.metadata)
+}
+
+// RequireBearerToken returns middleware that verifies bearer tokens with the
+// given verifier, as [RequireBearerToken] does, requiring the resource's
+// scopes and challenging clients to fetch the resource's metadata.
+func (p *ProtectedResource) RequireBearerToken(verifier TokenVerifier) func(http.Handler) http.Handler {
+	return RequireBearerToken(verifier, &RequireBearerTokenOptions{
+		ResourceMetadataURL: p.metadataURL,
+		Scopes:              p.scopes,
+	})
+}
+
+// Handle registers handler on mux for the given pattern, protected by
+// [ProtectedResource.RequireBearerToken], along with the resource's metadata
+// at its well-known location.
+//
+// If the resource identifier has a path, the metadata is also served at the
+// root of the well-known location (/.well-known/oauth-protected-resource), for
+// clients that do not support path-suffixed locations. Since mux panics on
+// conflicting registrations, this limits mux to a single such resource; use
+// [ProtectedResource.MetadataHandler] to register the metadata explicitly
+// otherwise.
+func (p *ProtectedResource) Handle(mux *http.ServeMux, pattern string, verifier TokenVerifier, handler http.Handler) {
+	metadataHandler := p.MetadataHandler()
+	mux.Handle(p.path, metadataHandler)
+	if p.path != protectedResourceMetadataPath {
+		mux.Handle(protectedResourceMetadataPath, metadataHandler)
+	}
+	mux.Handle(pattern, p.RequireBearerToken(verifier)(handler))
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/oauthex"
+)
+
+func TestProtectedResourceMetadataURL(t *testing.T) {
+	for _, tt := range []struct {
+		resource, wantPath, wantURL string
+	}{
+		{"https://example.com", "/.well-known/oauth-protected-resource", "https://example.com/.well-known/oauth-protected-resource"},
+		{"https://example.com/", "/.well-known/oauth-protected-resource", "https://example.com/.well-known/oauth-protected-resource"},
+		{"https://example.com:8443/mcp", "/.well-known/oauth-protected-resource/mcp", "https://example.com:8443/.well-known/oauth-protected-resource/mcp"},
+		{"https://example.com/a/b?x=1", "/.well-known/oauth-protected-resource/a/b", "https://example.com/.well-known/oauth-protected-resource/a/b"},
+	} {
+		gotPath, err := ProtectedResourceMetadataPath(tt.resource)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotURL, err := ProtectedResourceMetadataURL(tt.resource)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotPath != tt.wantPath || gotURL != tt.wantURL {
+			t.Errorf("%s: got (%q, %q), want (%q, %q)", tt.resource, gotPath, gotURL, tt.wantPath, tt.wantURL)
+		}
+	}
+	for _, resource := range []string{"", "/mcp", "https://example.com/mcp#frag"} {
+		if _, err := ProtectedResourceMetadataURL(resource); err == nil {
+			t.Errorf("%q: got nil error, want error", resource)
+		}
+	}
+}
+
+func TestProtectedResource(t *testing.T) {
+	if _, err := NewProtectedResource(&oauthex.ProtectedResourceMetadata{
+		Resource:        "https://example.com/mcp",
+		ScopesSupported: []string{"read"},
+	}, "write"); err == nil {
+		t.Error("unsupported scope: got nil error, want error")
+	}
+
+	pr, err := NewProtectedResource(&oauthex.ProtectedResourceMetadata{
+		Resource:             "https://example.com/mcp",
+		AuthorizationServers: []string{"https://auth.example.com"},
+	}, "read")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pr.MetadataURL(), "https://example.com/.well-known/oauth-protected-resource/mcp"; got != want {
+		t.Errorf("MetadataURL() = %q, want %q", got, want)
+	}
+
+	verifier := func(_ context.Context, token string, _ *http.Request) (*TokenInfo, error) {
+		return &TokenInfo{Scopes: strings.Split(token, "+"), Expiration: time.Now().Add(time.Hour)}, nil
+	}
+	mux := http.NewServeMux()
+	pr.Handle(mux, "/mcp", verifier, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// The metadata is served at both well-known locations, and advertises
+	// the required scopes.
+	for _, path := range []string{"/.well-known/oauth-protected-resource/mcp", "/.well-known/oauth-protected-resource"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, w.Code)
+		}
+		if got := w.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("GET %s: Content-Type = %q", path, got)
+		}
+		var got oauthex.ProtectedResourceMetadata
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Resource != "https://example.com/mcp" || !slices.Equal(got.ScopesSupported, []string{"read"}) {
+			t.Errorf("GET %s: got metadata %+v", path, got)
+		}
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/.well-known/oauth-protected-resource/mcp", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST metadata: status %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	// The protected handler challenges clients consistently with the metadata.
+	for _, tt := range []struct {
+		token         string
+		wantCode      int
+		wantChallenge string
+	}{
+		{"", http.StatusUnauthorized, "Bearer resource_metadata=https://example.com/.well-known/oauth-protected-resource/mcp"},
+		{"write", http.StatusForbidden, `Bearer error="insufficient_scope", scope="read", resource_metadata="https://example.com/.well-known/oauth-protected-resource/mcp"`},
+		{"read", http.StatusOK, ""},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != tt.wantCode {
+			t.Errorf("token %q: status %d, want %d", tt.token, w.Code, tt.wantCode)
+		}
+		if got := w.Header().Get("WWW-Authenticate"); got != tt.wantChallenge {
+			t.Errorf("token %q: WWW-Authenticate = %q, want %q", tt.token, got, tt.wantChallenge)
+		}
+	}
+}
@@ -788,7 +788,7 @@ func (c *Connection) write(ctx context.Context, msg Message) error {
 	// For rejected requests, we don't set the writeErr (which would break the
 	// connection). They can just be returned to the caller.
 	if errors.Is(err, ErrRejected) {
-		return err
+		return &rejectedError{err}
 	}
 
 	if err != nil && ctx.Err() == nil {
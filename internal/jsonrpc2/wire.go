@@ -6,6 +6,7 @@ package jsonrpc2
 
 import (
 	"encoding/json"
+	"errors"
 )
 
 // This file contains the go forms of the wire specification.
@@ -50,6 +51,23 @@ var (
 	ErrRejected = NewError(-32004, "rejected by transport")
 )
 
+// IsRejected reports whether err is a rejection of an outgoing message by the
+// local transport (see [ErrRejected]).
+//
+// Unlike errors.Is(err, ErrRejected), it does not match error responses from
+// the peer, which compare by code, and so can't be told apart from
+// [ErrServerClosing].
+func IsRejected(err error) bool {
+	var r *rejectedError
+	return errors.As(err, &r)
+}
+
+// rejectedError marks an error from Writer.Write that wraps [ErrRejected].
+type rejectedError struct{ err error }
+
+func (e *rejectedError) Error() string { return e.err.Error() }
+func (e *rejectedError) Unwrap() error { return e.err }
+
 const wireVersion = "2.0"
 
 // wireCombined has all the fields of both Request and Response.
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"maps"
 	"slices"
 	"strings"
 	"sync"
@@ -84,6 +85,20 @@ type ClientOptions struct {
 	// If the peer fails to respond to pings originating from the keepalive check,
 	// the session is automatically closed.
 	KeepAlive time.Duration
+	// Timeouts holds default timeouts for requests sent by the client, keyed by
+	// method (for example, "tools/call"). When a timeout expires, the request
+	// fails with [context.DeadlineExceeded], and the server is sent a
+	// "notifications/cancelled" notification.
+	Timeouts map[string]time.Duration
+	// If set, the timeout of a request is restarted each time a progress
+	// notification for it is received. A progress token is added to requests
+	// that have a timeout but no token.
+	ResetTimeoutOnProgress bool
+	// If set, the deadline of a request, whether from a timeout or the
+	// caller's context, is sent to the server in the request's _meta, so that
+	// a server using this SDK can cancel its handler when the client stops
+	// waiting.
+	PropagateDeadlines bool
 }
 
 // bind implements the binder[*ClientSession] interface, so that Clients can
@@ -103,6 +118,13 @@ func (c *Client) bind(mcpConn Connection, conn *jsonrpc2.Connection, state *clie
 // disconnect implements the binder[*Client] interface, so that
 // Clients can be connected using [connect].
 func (c *Client) disconnect(cs *ClientSession) {
+	if cs.connDone(cs.getConn()) {
+		return // the session is reconnecting
+	}
+	c.removeSession(cs)
+}
+
+func (c *Client) removeSession(cs *ClientSession) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.sessions = slices.DeleteFunc(c.sessions, func(cs2 *ClientSession) bool {
@@ -119,8 +141,12 @@ func (e unsupportedProtocolVersionError) Error() string {
 	return fmt.Sprintf("unsupported protocol version: %q", e.version)
 }
 
-// ClientSessionOptions is reserved for future use.
-type ClientSessionOptions struct{}
+// ClientSessionOptions configures a [ClientSession].
+type ClientSessionOptions struct {
+	// If non-nil, the session reconnects automatically when its connection
+	// fails. See [ReconnectOptions].
+	Reconnect *ReconnectOptions
+}
 
 func (c *Client) capabilities() *ClientCapabilities {
 	caps := &ClientCapabilities{}
@@ -151,12 +177,29 @@ func (c *Client) capabilities() *ClientCapabilities {
 // when it is no longer needed. However, if the connection is closed by the
 // server, calls or notifications will return an error wrapping
 // [ErrConnectionClosed].
-func (c *Client) Connect(ctx context.Context, t Transport, _ *ClientSessionOptions) (cs *ClientSession, err error) {
+func (c *Client) Connect(ctx context.Context, t Transport, opts *ClientSessionOptions) (cs *ClientSession, err error) {
 	cs, err = connect(ctx, t, c, (*clientSessionState)(nil), nil)
 	if err != nil {
 		return nil, err
 	}
+	if err := cs.initialize(ctx); err != nil {
+		return nil, err
+	}
+	if opts != nil && opts.Reconnect != nil {
+		cs.startReconnecting(t, opts.Reconnect)
+	}
+
+	if c.opts.KeepAlive > 0 {
+		cs.startKeepalive(c.opts.KeepAlive)
+	}
+
+	return cs, nil
+}
 
+// initialize performs the MCP initialization handshake on the session's
+// current connection.
+func (cs *ClientSession) initialize(ctx context.Context) error {
+	c := cs.client
 	params := &InitializeParams{
 		ProtocolVersion: latestProtocolVersion,
 		ClientInfo:      c.impl,
@@ -165,27 +208,26 @@ func (c *Client) Connect(ctx context.Context, t Transport, _ *ClientSessionOptio
 	req := &InitializeRequest{Session: cs, Params: params}
 	res, err := handleSend[*InitializeResult](ctx, methodInitialize, req)
 	if err != nil {
-		_ = cs.Close()
-		return nil, err
+		_ = cs.closeConn()
+		return err
 	}
 	if !slices.Contains(supportedProtocolVersions, res.ProtocolVersion) {
-		return nil, unsupportedProtocolVersionError{res.ProtocolVersion}
+		_ = cs.closeConn()
+		return unsupportedProtocolVersionError{res.ProtocolVersion}
 	}
+	cs.mu.Lock()
 	cs.state.InitializeResult = res
-	if hc, ok := cs.mcpConn.(clientConnection); ok {
-		hc.sessionUpdated(cs.state)
+	state, mcpConn := cs.state, cs.mcpConn
+	cs.mu.Unlock()
+	if hc, ok := mcpConn.(clientConnection); ok {
+		hc.sessionUpdated(state)
 	}
 	req2 := &initializedClientRequest{Session: cs, Params: &InitializedParams{}}
 	if err := handleNotify(ctx, notificationInitialized, req2); err != nil {
-		_ = cs.Close()
-		return nil, err
+		_ = cs.closeConn()
+		return err
 	}
-
-	if c.opts.KeepAlive > 0 {
-		cs.startKeepalive(c.opts.KeepAlive)
-	}
-
-	return cs, nil
+	return nil
 }
 
 // A ClientSession is a logical connection with an MCP server. Its
@@ -201,28 +243,40 @@ type ClientSession struct {
 	calledOnClose atomic.Bool
 	onClose       func()
 
-	conn            *jsonrpc2.Connection
 	client          *Client
 	keepaliveCancel context.CancelFunc
-	mcpConn         Connection
 
-	// No mutex is (currently) required to guard the session state, because it is
-	// only set synchronously during Client.Connect.
-	state clientSessionState
+	// mu guards the connection and session state, which change if the session
+	// reconnects.
+	mu      sync.Mutex
+	conn    *jsonrpc2.Connection
+	mcpConn Connection
+	state   clientSessionState
+	rc      *reconnector // non-nil if the session reconnects automatically
 
 	// Pending URL elicitations waiting for completion notifications.
 	pendingElicitationsMu sync.Mutex
 	pendingElicitations   map[string]chan struct{}
+
+	// Listeners for progress on outgoing requests.
+	progress progressListeners
 }
 
 type clientSessionState struct {
 	InitializeResult *InitializeResult
 }
 
-func (cs *ClientSession) InitializeResult() *InitializeResult { return cs.state.InitializeResult }
+func (cs *ClientSession) InitializeResult() *InitializeResult {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.state.InitializeResult
+}
 
 func (cs *ClientSession) ID() string {
-	if c, ok := cs.mcpConn.(hasSessionID); ok {
+	cs.mu.Lock()
+	mcpConn := cs.mcpConn
+	cs.mu.Unlock()
+	if c, ok := mcpConn.(hasSessionID); ok {
 		return c.SessionID()
 	}
 	return ""
@@ -242,7 +296,15 @@ func (cs *ClientSession) Close() error {
 	if cs.keepaliveCancel != nil {
 		cs.keepaliveCancel()
 	}
-	err := cs.conn.Close()
+	if cs.rc != nil {
+		cs.mu.Lock()
+		cs.rc.closing = true
+		cs.mu.Unlock()
+	}
+	err := cs.closeConn()
+	if cs.rc != nil {
+		cs.finish(nil)
+	}
 
 	if cs.onClose != nil && cs.calledOnClose.CompareAndSwap(false, true) {
 		cs.onClose()
@@ -251,10 +313,22 @@ func (cs *ClientSession) Close() error {
 	return err
 }
 
+// closeConn closes the current connection.
+func (cs *ClientSession) closeConn() error {
+	return cs.getConn().Close()
+}
+
 // Wait waits for the connection to be closed by the server.
 // Generally, clients should be responsible for closing the connection.
+//
+// If the session reconnects automatically, Wait waits until the session is
+// closed, or reconnection fails.
 func (cs *ClientSession) Wait() error {
-	return cs.conn.Wait()
+	if cs.rc != nil {
+		<-cs.rc.closed
+		return cs.rc.err
+	}
+	return cs.getConn().Wait()
 }
 
 // registerElicitationWaiter registers a waiter for an elicitation complete
@@ -739,7 +813,27 @@ func (cs *ClientSession) receivingMethodHandler() MethodHandler {
 }
 
 // getConn implements [Session.getConn].
-func (cs *ClientSession) getConn() *jsonrpc2.Connection { return cs.conn }
+func (cs *ClientSession) getConn() *jsonrpc2.Connection {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.conn
+}
+
+// sendingTimeout implements [Session.sendingTimeout].
+func (cs *ClientSession) sendingTimeout(method string) (time.Duration, bool, bool) {
+	opts := &cs.client.opts
+	return opts.Timeouts[method], opts.ResetTimeoutOnProgress, opts.PropagateDeadlines
+}
+
+// listenProgress implements [Session.listenProgress].
+func (cs *ClientSession) listenProgress(token any, f func(*ProgressNotificationParams)) func() {
+	return cs.progress.listen(token, f)
+}
+
+// dispatchProgress implements [progressDispatcher].
+func (cs *ClientSession) dispatchProgress(params *ProgressNotificationParams) {
+	cs.progress.dispatch(params)
+}
 
 func (*ClientSession) ping(context.Context, *PingParams) (*emptyResult, error) {
 	return &emptyResult{}, nil
@@ -776,7 +870,11 @@ func (cs *ClientSession) GetPrompt(ctx context.Context, params *GetPromptParams)
 
 // ListTools lists tools that are currently available on the server.
 func (cs *ClientSession) ListTools(ctx context.Context, params *ListToolsParams) (*ListToolsResult, error) {
-	return handleSend[*ListToolsResult](ctx, methodListTools, newClientRequest(cs, orZero[Params](params)))
+	res, err := handleSend[*ListToolsResult](ctx, methodListTools, newClientRequest(cs, orZero[Params](params)))
+	if err == nil {
+		cs.noteTools(res.Tools)
+	}
+	return res, err
 }
 
 // CallTool calls the tool with the given parameters.
@@ -793,6 +891,89 @@ func (cs *ClientSession) CallTool(ctx context.Context, params *CallToolParams) (
 	return handleSend[*CallToolResult](ctx, methodCallTool, newClientRequest(cs, orZero[Params](params)))
 }
 
+// A ToolCall is a tool call in progress, started by [ClientSession.StartCallTool].
+type ToolCall struct {
+	progress chan *ProgressNotificationParams
+	cancel   context.CancelCauseFunc
+	done     chan struct{}
+	result   *CallToolResult // set when done is closed
+	err      error           // set when done is closed
+}
+
+// StartCallTool starts a tool call and returns immediately, without waiting
+// for the result. Use the returned [ToolCall] to observe progress, cancel the
+// call, or wait for its result.
+//
+// If params does not contain a progress token, a unique one is generated, so
+// that progress notifications for concurrent calls do not collide. If params
+// contains a progress token that is in use by another call started by
+// StartCallTool that has not completed, the call is not sent, and Wait
+// reports an error.
+// Progress notifications are still delivered to
+// [ClientOptions.ProgressNotificationHandler], if set.
+func (cs *ClientSession) StartCallTool(ctx context.Context, params *CallToolParams) *ToolCall {
+	var p CallToolParams
+	if params != nil {
+		p = *params
+	}
+	p.Meta = maps.Clone(p.Meta)
+	token := p.GetProgressToken()
+	if token == nil {
+		token = randText()
+		p.SetProgressToken(token)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	tc := &ToolCall{
+		progress: make(chan *ProgressNotificationParams, 16),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	stop, ok := cs.progress.listenExclusive(token, func(params *ProgressNotificationParams) {
+		select {
+		case tc.progress <- params:
+		default: // the receiver isn't keeping up; drop the update
+		}
+	})
+	if !ok {
+		cancel(nil)
+		tc.err = fmt.Errorf("calling %q: progress token %v is in use by another call", p.Name, token)
+		close(tc.progress)
+		close(tc.done)
+		return tc
+	}
+
+	go func() {
+		defer cancel(nil)
+		tc.result, tc.err = cs.CallTool(ctx, &p)
+		stop()
+		close(tc.progress)
+		close(tc.done)
+	}()
+	return tc
+}
+
+// Progress returns a channel that receives progress notifications for the
+// call. The channel is closed when the call completes.
+//
+// The channel is buffered. If the receiver does not keep up, notifications
+// are dropped.
+func (tc *ToolCall) Progress() <-chan *ProgressNotificationParams { return tc.progress }
+
+// Cancel cancels the call, sending a "notifications/cancelled" notification
+// with the given reason to the server.
+// It has no effect if the call has already completed.
+func (tc *ToolCall) Cancel(reason string) {
+	tc.cancel(errors.New(reason))
+}
+
+// Wait waits for the call to complete, and returns its result.
+// If the call was cancelled, the error wraps [context.Canceled].
+func (tc *ToolCall) Wait() (*CallToolResult, error) {
+	<-tc.done
+	return tc.result, tc.err
+}
+
 func (cs *ClientSession) SetLoggingLevel(ctx context.Context, params *SetLoggingLevelParams) error {
 	_, err := handleSend[*emptyResult](ctx, methodSetLevel, newClientRequest(cs, orZero[Params](params)))
 	return err
@@ -821,6 +1002,9 @@ func (cs *ClientSession) Complete(ctx context.Context, params *CompleteParams) (
 // notifications when the specified resource changes.
 func (cs *ClientSession) Subscribe(ctx context.Context, params *SubscribeParams) error {
 	_, err := handleSend[*emptyResult](ctx, methodSubscribe, newClientRequest(cs, orZero[Params](params)))
+	if err == nil && params != nil {
+		cs.noteSubscription(params.URI, true)
+	}
 	return err
 }
 
@@ -828,6 +1012,9 @@ func (cs *ClientSession) Subscribe(ctx context.Context, params *SubscribeParams)
 // a previous subscription.
 func (cs *ClientSession) Unsubscribe(ctx context.Context, params *UnsubscribeParams) error {
 	_, err := handleSend[*emptyResult](ctx, methodUnsubscribe, newClientRequest(cs, orZero[Params](params)))
+	if err == nil && params != nil {
+		cs.noteSubscription(params.URI, false)
+	}
 	return err
 }
 
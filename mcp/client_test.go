@@ -6,12 +6,16 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 )
 
 type Item struct {
@@ -290,3 +294,135 @@ func TestClientCapabilities(t *testing.T) {
 		})
 	}
 }
+
+func TestStartCallTool(t *testing.T) {
+	cancelled := make(chan *CancelledParams, 1)
+	server := NewServer(testImpl, &ServerOptions{ProgressInterval: -1})
+	server.AddReceivingMiddleware(func(next MethodHandler) MethodHandler {
+		return func(ctx context.Context, method string, req Request) (Result, error) {
+			if method == notificationCancelled {
+				cancelled <- req.GetParams().(*CancelledParams)
+			}
+			return next(ctx, method, req)
+		}
+	})
+	AddTool(server, &Tool{Name: "echo"}, func(ctx context.Context, req *CallToolRequest, args struct{ Msg string }) (*CallToolResult, any, error) {
+		req.Progress().Report(0.5, args.Msg)
+		return &CallToolResult{}, nil, nil
+	})
+	AddTool(server, &Tool{Name: "block"}, func(ctx context.Context, req *CallToolRequest, _ any) (*CallToolResult, any, error) {
+		req.Progress().Report(0.5, "waiting")
+		<-ctx.Done()
+		return nil, nil, ctx.Err()
+	})
+	cs, _, cleanup := basicClientServerConnection(t, nil, server, nil)
+	defer cleanup()
+	ctx := context.Background()
+
+	t.Run("progress", func(t *testing.T) {
+		// Concurrent calls each see only their own progress.
+		var calls []*ToolCall
+		for i := range 3 {
+			calls = append(calls, cs.StartCallTool(ctx, &CallToolParams{
+				Name:      "echo",
+				Arguments: map[string]any{"Msg": fmt.Sprint(i)},
+			}))
+		}
+		for i, tc := range calls {
+			if _, err := tc.Wait(); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for p := range tc.Progress() {
+				got = append(got, fmt.Sprintf("%s:%v", p.Message, p.Progress))
+			}
+			want := []string{fmt.Sprintf("%d:0.5", i), ":1"}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("call %d: progress mismatch (-want +got):\n%s", i, diff)
+			}
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		tc := cs.StartCallTool(ctx, &CallToolParams{Name: "block"})
+		if p := <-tc.Progress(); p == nil || p.Message != "waiting" {
+			t.Fatalf("got progress %v, want message %q", p, "waiting")
+		}
+		tc.Cancel("user abort")
+		if _, err := tc.Wait(); !errors.Is(err, context.Canceled) {
+			t.Errorf("Wait() error = %v, want context.Canceled", err)
+		}
+		if got := (<-cancelled).Reason; got != "user abort" {
+			t.Errorf("cancellation reason = %q, want %q", got, "user abort")
+		}
+		if _, ok := <-tc.Progress(); ok {
+			t.Error("progress channel not closed after Wait")
+		}
+	})
+
+	t.Run("duplicate token", func(t *testing.T) {
+		// A caller-supplied token can't be shared by concurrent calls, which
+		// would receive each other's progress.
+		params := &CallToolParams{Name: "block"}
+		params.SetProgressToken("tok")
+		tc := cs.StartCallTool(ctx, params)
+		if p := <-tc.Progress(); p == nil || p.Message != "waiting" {
+			t.Fatalf("got progress %v, want message %q", p, "waiting")
+		}
+		if _, err := cs.StartCallTool(ctx, params).Wait(); err == nil {
+			t.Error("concurrent call with the same progress token succeeded, want error")
+		}
+		tc.Cancel("done")
+		if _, err := tc.Wait(); !errors.Is(err, context.Canceled) {
+			t.Errorf("Wait() error = %v, want context.Canceled", err)
+		}
+		<-cancelled
+
+		// Once the first call completes, its token may be reused.
+		params.Name = "echo"
+		params.Arguments = map[string]any{"Msg": "again"}
+		if _, err := cs.StartCallTool(ctx, params).Wait(); err != nil {
+			t.Errorf("reusing a completed call's token: %v", err)
+		}
+	})
+}
+
+func TestClientUnsupportedProtocolVersion(t *testing.T) {
+	ctx := context.Background()
+	ct, st := NewInMemoryTransports()
+	sconn, err := st.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sconn.Close()
+	// Answer the initialize request with an unsupported version.
+	go func() {
+		msg, err := sconn.Read(ctx)
+		if err != nil {
+			return
+		}
+		req := msg.(*jsonrpc.Request)
+		res := &InitializeResult{ProtocolVersion: "1999-01-01", ServerInfo: testImpl}
+		_ = sconn.Write(ctx, &jsonrpc.Response{ID: req.ID, Result: mustMarshal(res)})
+	}()
+
+	_, err = NewClient(testImpl, nil).Connect(ctx, ct, nil)
+	if err == nil || !strings.Contains(err.Error(), "unsupported protocol version") {
+		t.Fatalf("Connect() = %v, want unsupported protocol version", err)
+	}
+	// The client closes its connection, so the server sees it end.
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			if _, err := sconn.Read(ctx); err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+	select {
+	case <-readErr:
+	case <-time.After(5 * time.Second):
+		t.Fatal("client connection not closed")
+	}
+}
@@ -23,6 +23,11 @@ type CommandTransport struct {
 	// for the process to exit before sending SIGTERM.
 	// If zero or negative, the default of 5s is used.
 	TerminateDuration time.Duration
+
+	// MaxMessageSize and MaxBatchSize limit incoming messages, as for
+	// [IOTransport].
+	MaxMessageSize int64
+	MaxBatchSize   int
 }
 
 // Connect starts the command, and connects to it over stdin/stdout.
@@ -43,7 +48,7 @@ func (t *CommandTransport) Connect(ctx context.Context) (Connection, error) {
 	if td <= 0 {
 		td = defaultTerminateDuration
 	}
-	return newIOConn(&pipeRWC{t.Command, stdout, stdin, td}), nil
+	return newLimitedIOConn(&pipeRWC{t.Command, stdout, stdin, td}, t.MaxMessageSize, t.MaxBatchSize), nil
 }
 
 // A pipeRWC is an io.ReadWriteCloser that communicates with a subprocess over
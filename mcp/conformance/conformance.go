@@ -0,0 +1,567 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package conformance provides a harness for JSON-level conformance tests of
+// MCP clients and servers.
+//
+// A conformance test is a scripted exchange of JSON-RPC messages between a
+// synthetic peer, played by this package, and a real client or server under
+// test. Tests are stored as txtar archives, containing:
+//
+//   - a "client" file, holding a sequence of JSON-RPC messages sent by the
+//     client;
+//   - a "server" file, holding a sequence of JSON-RPC messages sent by the
+//     server;
+//   - any number of other files, each holding a list of named features (one
+//     per line) used to configure the peer under test.
+//
+// Each message is a JSON value: either a single JSON-RPC message, or an array
+// of messages representing a JSON-RPC batch.
+//
+// For server tests, the client messages are played by [PlayClient], and the
+// server messages are those expected from the real server. For client tests,
+// it's the other way around: the server messages are played by [PlayServer],
+// and the client messages are those expected from the real client. Use [Diff]
+// to compare the expected messages with the messages actually received, and
+// [Test.Update] to record them.
+//
+// The synthetic peer communicates over a stream of newline-delimited JSON
+// values, such as one end of a [net.Pipe] whose other end is connected to the
+// peer under test, or the standard input and output of a server subprocess.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"golang.org/x/tools/txtar"
+)
+
+// A Test is a conformance test loaded from a txtar archive.
+type Test struct {
+	Name     string              // test name: the path of the archive relative to its directory
+	Path     string              // path to the archive
+	Archive  *txtar.Archive      // raw archive, for updating
+	Features map[string][]string // named features, keyed by file name
+	Client   []json.RawMessage   // client messages
+	Server   []json.RawMessage   // server messages
+}
+
+// LoadDir loads all conformance tests (files with the .txtar extension) in the
+// directory tree rooted at dir.
+func LoadDir(dir string) ([]*Test, error) {
+	var tests []*Test
+	err := filepath.WalkDir(dir, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(path, ".txtar") {
+			test, err := Load(path)
+			if err != nil {
+				return err
+			}
+			test.Name = strings.TrimPrefix(path, dir+string(filepath.Separator))
+			tests = append(tests, test)
+		}
+		return nil
+	})
+	return tests, err
+}
+
+// Load loads the conformance test archive at path.
+func Load(path string) (*Test, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	test := &Test{
+		Name:     filepath.Base(path),
+		Path:     path,
+		Archive:  txtar.Parse(content),
+		Features: make(map[string][]string),
+	}
+	if len(test.Archive.Files) == 0 {
+		return nil, fmt.Errorf("txtar archive %q has no '-- filename --' sections", path)
+	}
+
+	seen := make(map[string]bool) // catch accidentally duplicate files
+	for _, f := range test.Archive.Files {
+		if seen[f.Name] {
+			return nil, fmt.Errorf("txtar archive %q: duplicate file name %q", path, f.Name)
+		}
+		seen[f.Name] = true
+		switch f.Name {
+		case "client":
+			test.Client, err = decodeMessages(f.Data)
+			if err != nil {
+				return nil, fmt.Errorf("txtar archive %q contains bad -- client -- section: %v", path, err)
+			}
+		case "server":
+			test.Server, err = decodeMessages(f.Data)
+			if err != nil {
+				return nil, fmt.Errorf("txtar archive %q contains bad -- server -- section: %v", path, err)
+			}
+		default:
+			var feats []string
+			for _, line := range strings.Split(string(f.Data), "\n") {
+				if f := strings.TrimSpace(line); f != "" {
+					feats = append(feats, f)
+				}
+			}
+			test.Features[f.Name] = feats
+		}
+	}
+	return test, nil
+}
+
+// decodeMessages loads a sequence of JSON values from the data of an archive
+// file, checking that each is a JSON-RPC message or batch.
+func decodeMessages(data []byte) ([]json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var res []json.RawMessage
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if _, _, err := decodeBatch(raw); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, raw); err != nil {
+			return nil, err
+		}
+		res = append(res, buf.Bytes())
+	}
+	return res, nil
+}
+
+// decodeBatch decodes a JSON value that is either a single JSON-RPC message,
+// or an array of messages.
+func decodeBatch(raw json.RawMessage) (msgs []jsonrpc.Message, isBatch bool, _ error) {
+	if raw = bytes.TrimSpace(raw); len(raw) > 0 && raw[0] == '[' {
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return nil, true, err
+		}
+		if len(elems) == 0 {
+			return nil, true, errors.New("empty batch")
+		}
+		for _, e := range elems {
+			msg, err := jsonrpc.DecodeMessage(e)
+			if err != nil {
+				return nil, true, err
+			}
+			msgs = append(msgs, msg)
+		}
+		return msgs, true, nil
+	}
+	msg, err := jsonrpc.DecodeMessage(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return []jsonrpc.Message{msg}, false, nil
+}
+
+// encodeBatch is the inverse of decodeBatch.
+func encodeBatch(msgs []jsonrpc.Message, isBatch bool) (json.RawMessage, error) {
+	var elems []json.RawMessage
+	for _, msg := range msgs {
+		data, err := jsonrpc.EncodeMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, data)
+	}
+	if !isBatch {
+		return elems[0], nil
+	}
+	return json.Marshal(elems)
+}
+
+// Format formats msgs in the canonical form used in conformance test
+// archives: one indented JSON value per message.
+func Format(msgs []json.RawMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, raw := range msgs {
+		// Decode and re-encode, so that the formatting doesn't depend on the
+		// peer's encoding.
+		batch, isBatch, err := decodeBatch(raw)
+		if err != nil {
+			return nil, err
+		}
+		data, err := encodeBatch(batch, isBatch)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Indent(&buf, data, "", "\t"); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// Diff returns a human-readable report of the differences between the
+// expected messages want and the received messages got, or "" if they are
+// equivalent.
+func Diff(want, got []json.RawMessage) string {
+	lines := func(msgs []json.RawMessage) []string {
+		data, err := Format(msgs)
+		if err != nil {
+			return []string{fmt.Sprintf("invalid messages: %v", err)}
+		}
+		return strings.Split(string(data), "\n")
+	}
+	return cmp.Diff(lines(want), lines(got))
+}
+
+// Update sets the contents of the named archive file (typically "client" or
+// "server") to msgs, and writes the updated archive to the test's path.
+func (t *Test) Update(file string, msgs []json.RawMessage) error {
+	data, err := Format(msgs)
+	if err != nil {
+		return err
+	}
+	arch := &txtar.Archive{Comment: t.Archive.Comment}
+	updated := txtar.File{Name: file, Data: data}
+	seen := false // replace or append the file
+	for _, f := range t.Archive.Files {
+		if f.Name == file {
+			seen = true
+			arch.Files = append(arch.Files, updated)
+		} else {
+			arch.Files = append(arch.Files, f)
+		}
+	}
+	if !seen {
+		arch.Files = append(arch.Files, updated)
+	}
+	if err := os.WriteFile(t.Path, txtar.Format(arch), 0o666); err != nil {
+		return err
+	}
+	t.Archive = arch
+	return nil
+}
+
+// Options configures [PlayClient] and [PlayServer].
+type Options struct {
+	// If set, Wait is called to wait until the peer under test is idle, so
+	// that the order of received messages is deterministic. For example, in a
+	// synctest bubble, use synctest.Wait.
+	//
+	// If Wait is nil, the synthetic peer doesn't wait for the peer under test
+	// to finish processing messages before sending the next one, and doesn't
+	// wait for any final messages before closing the stream.
+	Wait func()
+}
+
+func (o *Options) wait() {
+	if o != nil && o.Wait != nil {
+		o.Wait()
+	}
+}
+
+// PlayClient plays the client side of test over the stream rwc, which must be
+// connected to the server under test. It returns the messages received from
+// the server.
+//
+// The client messages are sent in order, except for responses, which are sent
+// in reply to calls from the server: the first response is sent in reply to
+// the first call, and so on, with the ID rewritten to match. After sending a
+// call, PlayClient waits for its response, which must be the next response
+// received. After sending all messages, PlayClient waits for the server to be
+// idle (see [Options.Wait]), and then closes rwc. The server may close the
+// stream after the last message is sent.
+func PlayClient(ctx context.Context, rwc io.ReadWriteCloser, test *Test, opts *Options) ([]json.RawMessage, error) {
+	// Separate the scripted responses; they are sent in reply to calls.
+	var (
+		outgoing  []json.RawMessage
+		responses []*jsonrpc.Response
+	)
+	for _, raw := range test.Client {
+		msgs, isBatch, err := decodeBatch(raw)
+		if err != nil {
+			return nil, err
+		}
+		if resp, ok := msgs[0].(*jsonrpc.Response); ok && !isBatch {
+			responses = append(responses, resp)
+		} else {
+			outgoing = append(outgoing, raw)
+		}
+	}
+	var p *player
+	p = newPlayer(rwc, func(req *jsonrpc.Request) error {
+		if len(responses) == 0 {
+			return fmt.Errorf("no scripted response for call %v", req.ID.Raw())
+		}
+		resp := responses[0]
+		responses = responses[1:]
+		resp.ID = req.ID
+		return p.writeMessages([]jsonrpc.Message{resp}, false)
+	})
+	defer p.close()
+
+	for _, raw := range outgoing {
+		msgs, _, _ := decodeBatch(raw) // already checked above
+		if err := p.write(raw); err != nil {
+			return p.received(), err
+		}
+		for _, msg := range msgs {
+			req, ok := msg.(*jsonrpc.Request)
+			if !ok || !req.IsCall() {
+				continue
+			}
+			// A call (as opposed to a notification). Wait for the response.
+			resp, err := p.nextResponse(ctx)
+			if err != nil {
+				return p.received(), fmt.Errorf("awaiting response to %v: %v", req.ID.Raw(), err)
+			}
+			if resp.ID != req.ID {
+				return p.received(), fmt.Errorf("out-of-order response %v to request %v", resp.ID.Raw(), req.ID.Raw())
+			}
+		}
+	}
+	// There might be more notifications or requests, but there shouldn't be
+	// more responses.
+	opts.wait()
+	if err := p.checkIdle(); err != nil {
+		return p.received(), err
+	}
+	return p.close(), nil
+}
+
+// PlayServer plays the server side of test over the stream rwc, which must be
+// connected to the client under test. It returns the messages received from
+// the client.
+//
+// The server messages are sent in order. Before sending each message,
+// PlayServer waits for the client to be idle (see [Options.Wait]). A
+// response, or batch of responses, is sent in reply to the next call received
+// from the client that has not yet been answered, with the ID rewritten to
+// match. After sending all messages, PlayServer waits for the client to be
+// idle, and then closes rwc. The client may close the stream after the last
+// message is sent.
+func PlayServer(ctx context.Context, rwc io.ReadWriteCloser, test *Test, opts *Options) ([]json.RawMessage, error) {
+	calls := make(chan jsonrpc.ID, 100)
+	p := newPlayer(rwc, func(req *jsonrpc.Request) error {
+		select {
+		case calls <- req.ID:
+			return nil
+		default:
+			return fmt.Errorf("too many unanswered calls")
+		}
+	})
+	defer p.close()
+
+	for _, raw := range test.Server {
+		opts.wait()
+		msgs, isBatch, err := decodeBatch(raw)
+		if err != nil {
+			return p.received(), err
+		}
+		if _, ok := msgs[0].(*jsonrpc.Response); !ok {
+			if err := p.write(raw); err != nil {
+				return p.received(), err
+			}
+			continue
+		}
+		// Pair up each response with the next unanswered call.
+		for _, msg := range msgs {
+			resp, ok := msg.(*jsonrpc.Response)
+			if !ok {
+				return p.received(), errors.New("batch mixes responses with other messages")
+			}
+			select {
+			case <-ctx.Done():
+				return p.received(), ctx.Err()
+			case <-p.done:
+				return p.received(), fmt.Errorf("awaiting call: %v", p.err())
+			case id := <-calls:
+				resp.ID = id
+			}
+		}
+		if err := p.writeMessages(msgs, isBatch); err != nil {
+			return p.received(), err
+		}
+	}
+	opts.wait()
+	if err := p.err(); err != nil && err != errPeerClosed {
+		return p.received(), err
+	}
+	return p.close(), nil
+}
+
+// A player is the synthetic peer in a conformance test.
+type player struct {
+	rwc     io.ReadWriteCloser
+	writeMu sync.Mutex
+
+	onCall func(*jsonrpc.Request) error // called by the read loop for each incoming call
+
+	responses chan *jsonrpc.Response // incoming responses
+	done      chan struct{}          // closed when the read loop exits
+
+	mu       sync.Mutex
+	messages []json.RawMessage // received messages
+	closed   bool              // rwc was closed by the player
+	readErr  error             // error that terminated the read loop, if not closed
+}
+
+// newPlayer returns a new player communicating over rwc, and starts its read
+// loop. The read loop calls onCall for each incoming call.
+func newPlayer(rwc io.ReadWriteCloser, onCall func(*jsonrpc.Request) error) *player {
+	p := &player{
+		rwc:       rwc,
+		onCall:    onCall,
+		responses: make(chan *jsonrpc.Response, 100),
+		done:      make(chan struct{}),
+	}
+	go p.read()
+	return p
+}
+
+// read reads incoming messages until the stream is closed.
+func (p *player) read() {
+	defer close(p.done)
+	dec := json.NewDecoder(p.rwc)
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		var msgs []jsonrpc.Message
+		if err == nil {
+			msgs, _, err = decodeBatch(raw)
+		}
+		p.mu.Lock()
+		if err != nil && !p.closed {
+			p.readErr = err
+		}
+		if err == nil {
+			p.messages = append(p.messages, raw)
+		}
+		p.mu.Unlock()
+		if err != nil {
+			return
+		}
+		for _, msg := range msgs {
+			switch msg := msg.(type) {
+			case *jsonrpc.Request:
+				if msg.IsCall() {
+					if err := p.onCall(msg); err != nil {
+						p.fail(err)
+						return
+					}
+				}
+			case *jsonrpc.Response:
+				select {
+				case p.responses <- msg:
+				default:
+					p.fail(fmt.Errorf("too many unexpected responses"))
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *player) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.readErr == nil {
+		p.readErr = err
+	}
+}
+
+// errPeerClosed reports that the peer closed the stream.
+var errPeerClosed = errors.New("stream closed by peer")
+
+// err returns the error that terminated the read loop, if any.
+func (p *player) err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if errors.Is(p.readErr, io.EOF) || errors.Is(p.readErr, io.ErrClosedPipe) {
+		return errPeerClosed
+	}
+	return p.readErr
+}
+
+// nextResponse returns the next incoming response.
+func (p *player) nextResponse(ctx context.Context) (*jsonrpc.Response, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case resp := <-p.responses:
+		return resp, nil
+	case <-p.done:
+		select {
+		case resp := <-p.responses:
+			return resp, nil
+		default:
+			return nil, p.err()
+		}
+	}
+}
+
+// checkIdle reports an error if there are unread responses, or if the read
+// loop failed. It is not an error for the peer to have closed the stream.
+func (p *player) checkIdle() error {
+	select {
+	case resp := <-p.responses:
+		return fmt.Errorf("got extra response %v", resp.ID.Raw())
+	default:
+	}
+	select {
+	case <-p.done:
+		if err := p.err(); err != errPeerClosed {
+			return err
+		}
+	default:
+	}
+	return nil
+}
+
+func (p *player) write(raw json.RawMessage) error {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	data := bytes.TrimSpace(raw)
+	_, err := p.rwc.Write(append(data[:len(data):len(data)], '\n'))
+	return err
+}
+
+func (p *player) writeMessages(msgs []jsonrpc.Message, isBatch bool) error {
+	data, err := encodeBatch(msgs, isBatch)
+	if err != nil {
+		return err
+	}
+	return p.write(data)
+}
+
+// received returns the messages received so far.
+func (p *player) received() []json.RawMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]json.RawMessage(nil), p.messages...)
+}
+
+// close closes the stream, waits for the read loop to exit, and returns the
+// received messages.
+func (p *player) close() []json.RawMessage {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.rwc.Close()
+	<-p.done
+	return p.received()
+}
@@ -7,27 +7,18 @@
 package mcp
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"flag"
-	"fmt"
-	"io"
-	"io/fs"
-	"os"
+	"net"
 	"path/filepath"
-	"strings"
+	"slices"
 	"testing"
 	"testing/synctest"
 	"time"
 
-	"github.com/google/go-cmp/cmp"
-	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/jsonschema-go/jsonschema"
-	"github.com/modelcontextprotocol/go-sdk/internal/jsonrpc2"
-	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
-	"golang.org/x/tools/txtar"
+	"github.com/modelcontextprotocol/go-sdk/mcp/conformance"
 )
 
 var update = flag.Bool("update", false, "if set, update conformance test data")
@@ -37,50 +28,19 @@ var update = flag.Bool("update", false, "if set, update conformance test data")
 // SDKs, even if they behave differently at the JSON level (for example, have
 // different behavior with respect to optional fields).
 //
-// The client and server fields hold an encoded sequence of JSON-RPC messages.
-//
-// For server tests, the client messages are a sequence of messages to be sent
-// from the (synthetic) client and the server messages are the expected
-// messages to be received from the real server.
-//
-// For client tests, it's the other way around: server messages are synthetic,
-// and client messages are expected from the real client.
-//
-// Conformance tests are loaded from txtar-encoded testdata files. Run the test
-// with -update to have the test runner update the expected output, which may
-// be client or server depending on the perspective of the test.
-type conformanceTest struct {
-	name                      string            // test name
-	path                      string            // path to test file
-	archive                   *txtar.Archive    // raw archive, for updating
-	tools, prompts, resources []string          // named features to include
-	client                    []jsonrpc.Message // client messages
-	server                    []jsonrpc.Message // server messages
-}
-
-// TODO(rfindley): add client conformance tests.
+// Conformance tests are loaded from txtar-encoded testdata files, and run
+// using the conformance package; see its documentation for the format. Run the
+// test with -update to have the test runner update the expected output, which
+// may be client or server depending on the perspective of the test.
 
 func TestServerConformance(t *testing.T) {
-	var tests []*conformanceTest
-	dir := filepath.Join("testdata", "conformance", "server")
-	if err := filepath.WalkDir(dir, func(path string, _ fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if strings.HasSuffix(path, ".txtar") {
-			test, err := loadConformanceTest(dir, path)
-			if err != nil {
-				return fmt.Errorf("%s: %v", path, err)
-			}
-			tests = append(tests, test)
-		}
-		return nil
-	}); err != nil {
+	tests, err := conformance.LoadDir(filepath.Join("testdata", "conformance", "server"))
+	if err != nil {
 		t.Fatal(err)
 	}
 
 	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
+		t.Run(test.Name, func(t *testing.T) {
 			// We use synctest here because in general, there is no way to know when the
 			// server is done processing any notifications. As long as our server doesn't
 			// do background work, synctest provides an easy way for us to detect when the
@@ -99,6 +59,30 @@ func TestServerConformance(t *testing.T) {
 	}
 }
 
+func TestClientConformance(t *testing.T) {
+	tests, err := conformance.LoadDir(filepath.Join("testdata", "conformance", "client"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			// See TestServerConformance for why we use synctest.
+			runSyncTest(t, func(t *testing.T) { runClientTest(t, test) })
+		})
+	}
+}
+
+// checkFeatures reports an error if test contains feature lists other than
+// those named.
+func checkFeatures(t *testing.T, test *conformance.Test, names ...string) {
+	for name := range test.Features {
+		if !slices.Contains(names, name) {
+			t.Fatalf("txtar archive %q contains unexpected file %q", test.Path, name)
+		}
+	}
+}
+
 type structuredInput struct {
 	In string `jsonschema:"the input"`
 }
@@ -157,18 +141,19 @@ var iconObj = Icon{
 
 // runServerTest runs the server conformance test.
 // It must be executed in a synctest bubble.
-func runServerTest(t *testing.T, test *conformanceTest) {
+func runServerTest(t *testing.T, test *conformance.Test) {
 	ctx := t.Context()
 	// Construct the server based on features listed in the test.
 	impl := &Implementation{Name: "testServer", Version: "v1.0.0"}
 
-	if test.name == "spec-sep-973-additional-metadata.txtar" {
+	checkFeatures(t, test, "tools", "prompts", "resources")
+	if test.Name == "spec-sep-973-additional-metadata.txtar" {
 		impl.Icons = []Icon{iconObj}
 		impl.WebsiteURL = "https://github.com/modelcontextprotocol/go-sdk"
 	}
 
 	s := NewServer(impl, nil)
-	for _, tn := range test.tools {
+	for _, tn := range test.Features["tools"] {
 		switch tn {
 		case "greet":
 			AddTool(s, &Tool{
@@ -202,7 +187,7 @@ func runServerTest(t *testing.T, test *conformanceTest) {
 			t.Fatalf("unknown tool %q", tn)
 		}
 	}
-	for _, pn := range test.prompts {
+	for _, pn := range test.Features["prompts"] {
 		switch pn {
 		case "code_review":
 			s.AddPrompt(codeReviewPrompt, codReviewPromptHandler)
@@ -217,7 +202,7 @@ func runServerTest(t *testing.T, test *conformanceTest) {
 			t.Fatalf("unknown prompt %q", pn)
 		}
 	}
-	for _, rn := range test.resources {
+	for _, rn := range test.Features["resources"] {
 		switch rn {
 		case "info.txt":
 			s.AddResource(resource1, readHandler)
@@ -235,226 +220,92 @@ func runServerTest(t *testing.T, test *conformanceTest) {
 		}
 	}
 
-	// Connect the server, and connect the client stream,
-	// but don't connect an actual client.
-	cTransport, sTransport := NewInMemoryTransports()
-	ss, err := s.Connect(ctx, sTransport, nil)
+	// Connect the server, and play the client side of the test against it,
+	// without an actual client.
+	cConn, sConn := net.Pipe()
+	ss, err := s.Connect(ctx, &InMemoryTransport{sConn}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	cStream, err := cTransport.Connect(ctx)
+	serverMessages, err := conformance.PlayClient(ctx, cConn, test, &conformance.Options{Wait: synctest.Wait})
 	if err != nil {
-		t.Fatal(err)
-	}
-
-	writeMsg := func(msg jsonrpc.Message) {
-		if err := cStream.Write(ctx, msg); err != nil {
-			t.Fatalf("Write failed: %v", err)
-		}
+		t.Fatalf("playing client messages failed: %v", err)
 	}
+	ss.Wait()
 
-	var (
-		serverMessages []jsonrpc.Message
-		outRequests    []*jsonrpc.Request
-		outResponses   []*jsonrpc.Response
-	)
-
-	// Separate client requests and responses; we use them differently.
-	for _, msg := range test.client {
-		switch msg := msg.(type) {
-		case *jsonrpc.Request:
-			outRequests = append(outRequests, msg)
-		case *jsonrpc.Response:
-			outResponses = append(outResponses, msg)
-		default:
-			t.Fatalf("bad message type %T", msg)
+	// Handle server output. If -update is set, write the 'server' file.
+	// Otherwise, compare with expected.
+	if *update {
+		if err := test.Update("server", serverMessages); err != nil {
+			t.Fatal(err)
 		}
+	} else if diff := conformance.Diff(test.Server, serverMessages); diff != "" {
+		t.Errorf("Mismatching server messages (-want +got):\n%s", diff)
 	}
+}
 
-	// nextResponse handles incoming requests and notifications, and returns the
-	// next incoming response.
-	nextResponse := func() (*jsonrpc.Response, error, bool) {
-		for {
-			msg, err := cStream.Read(ctx)
-			if err != nil {
-				// TODO(rfindley): we don't document (or want to document) that the in
-				// memory transports use a net.Pipe. How can users detect this failure?
-				// Should we promote it to EOF?
-				if errors.Is(err, io.ErrClosedPipe) {
-					err = nil
-				}
-				return nil, err, false
+// runClientTest runs the client conformance test.
+// It must be executed in a synctest bubble.
+func runClientTest(t *testing.T, test *conformance.Test) {
+	ctx := t.Context()
+	checkFeatures(t, test, "roots", "handlers")
+
+	// Construct the client based on features listed in the test.
+	opts := &ClientOptions{}
+	for _, hn := range test.Features["handlers"] {
+		switch hn {
+		case "sampling":
+			opts.CreateMessageHandler = func(context.Context, *CreateMessageRequest) (*CreateMessageResult, error) {
+				return &CreateMessageResult{
+					Model:   "testModel",
+					Role:    "assistant",
+					Content: &TextContent{Text: "hello"},
+				}, nil
 			}
-			serverMessages = append(serverMessages, msg)
-			if req, ok := msg.(*jsonrpc.Request); ok && req.IsCall() {
-				// Pair up the next outgoing response with this request.
-				// We assume requests arrive in the same order every time.
-				if len(outResponses) == 0 {
-					t.Fatalf("no outgoing response for request %v", req)
+		case "elicitation":
+			opts.ElicitationHandler = func(_ context.Context, req *ElicitRequest) (*ElicitResult, error) {
+				return &ElicitResult{Action: "accept", Content: map[string]any{"name": "Gopher"}}, nil
+			}
+		case "toolListChanged":
+			// Re-list tools when they change, as a host might.
+			opts.ToolListChangedHandler = func(ctx context.Context, req *ToolListChangedRequest) {
+				if _, err := req.Session.ListTools(ctx, nil); err != nil {
+					t.Errorf("ListTools failed: %v", err)
 				}
-				outResponses[0].ID = req.ID
-				writeMsg(outResponses[0])
-				outResponses = outResponses[1:]
-				continue
 			}
-			return msg.(*jsonrpc.Response), nil, true
+		default:
+			t.Fatalf("unknown handler %q", hn)
 		}
 	}
-
-	// Synthetic peer interacts with real peer.
-	for _, req := range outRequests {
-		writeMsg(req)
-		if req.IsCall() {
-			// A call (as opposed to a notification). Wait for the response.
-			res, err, ok := nextResponse()
-			if err != nil {
-				t.Fatalf("reading server messages failed: %v", err)
-			}
-			if !ok {
-				t.Fatalf("missing response for request %v", req)
-			}
-			if res.ID != req.ID {
-				t.Fatalf("out-of-order response %v to request %v", req, res)
-			}
-		}
+	c := NewClient(&Implementation{Name: "testClient", Version: "v1.0.0"}, opts)
+	for _, uri := range test.Features["roots"] {
+		c.AddRoots(&Root{URI: uri})
 	}
-	// There might be more notifications or requests, but there shouldn't be more
-	// responses.
-	// Run this in a goroutine so the current thread can wait for it.
-	var extra *jsonrpc.Response
+
+	// Connect the client, and play the server side of the test against it,
+	// without an actual server.
+	cConn, sConn := net.Pipe()
+	connected := make(chan error, 1)
 	go func() {
-		extra, err, _ = nextResponse()
+		// The session ends when the stream is closed.
+		_, err := c.Connect(ctx, &InMemoryTransport{cConn}, nil)
+		connected <- err
 	}()
-	// Before closing the stream, wait for all messages to be processed.
-	synctest.Wait()
+	clientMessages, err := conformance.PlayServer(ctx, sConn, test, &conformance.Options{Wait: synctest.Wait})
 	if err != nil {
-		t.Fatalf("reading server messages failedd: %v", err)
-	}
-	if extra != nil {
-		t.Fatalf("got extra response: %v", extra)
+		t.Fatalf("playing server messages failed: %v", err)
 	}
-	if err := cStream.Close(); err != nil {
-		t.Fatalf("Stream.Close failed: %v", err)
-	}
-	ss.Wait()
+	// Connection errors are part of the test, and evident in the output.
+	<-connected
+	synctest.Wait()
 
-	// Handle server output. If -update is set, write the 'server' file.
+	// Handle client output. If -update is set, write the 'client' file.
 	// Otherwise, compare with expected.
 	if *update {
-		arch := &txtar.Archive{
-			Comment: test.archive.Comment,
-		}
-		var buf bytes.Buffer
-		for _, msg := range serverMessages {
-			data, err := jsonrpc2.EncodeIndent(msg, "", "\t")
-			if err != nil {
-				t.Fatalf("jsonrpc2.EncodeIndent failed: %v", err)
-			}
-			buf.Write(data)
-			buf.WriteByte('\n')
-		}
-		serverFile := txtar.File{Name: "server", Data: buf.Bytes()}
-		seenServer := false // replace or append the 'server' file
-		for _, f := range test.archive.Files {
-			if f.Name == "server" {
-				seenServer = true
-				arch.Files = append(arch.Files, serverFile)
-			} else {
-				arch.Files = append(arch.Files, f)
-			}
-		}
-		if !seenServer {
-			arch.Files = append(arch.Files, serverFile)
-		}
-		if err := os.WriteFile(test.path, txtar.Format(arch), 0o666); err != nil {
-			t.Fatalf("os.WriteFile(%q) failed: %v", test.path, err)
-		}
-	} else {
-		// jsonrpc.Messages are not comparable, so we instead compare lines of JSON.
-		transform := cmpopts.AcyclicTransformer("toJSON", func(msg jsonrpc.Message) []string {
-			encoded, err := jsonrpc2.EncodeIndent(msg, "", "\t")
-			if err != nil {
-				t.Fatal(err)
-			}
-			return strings.Split(string(encoded), "\n")
-		})
-		if diff := cmp.Diff(test.server, serverMessages, transform); diff != "" {
-			t.Errorf("Mismatching server messages (-want +got):\n%s", diff)
-		}
-	}
-}
-
-// loadConformanceTest loads one conformance test from the given path contained
-// in the root dir.
-func loadConformanceTest(dir, path string) (*conformanceTest, error) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	test := &conformanceTest{
-		name:    strings.TrimPrefix(path, dir+string(filepath.Separator)),
-		path:    path,
-		archive: txtar.Parse(content),
-	}
-	if len(test.archive.Files) == 0 {
-		return nil, fmt.Errorf("txtar archive %q has no '-- filename --' sections", path)
-	}
-
-	// decodeMessages loads JSON-RPC messages from the archive file.
-	decodeMessages := func(data []byte) ([]jsonrpc.Message, error) {
-		dec := json.NewDecoder(bytes.NewReader(data))
-		var res []jsonrpc.Message
-		for dec.More() {
-			var raw json.RawMessage
-			if err := dec.Decode(&raw); err != nil {
-				return nil, err
-			}
-			m, err := jsonrpc2.DecodeMessage(raw)
-			if err != nil {
-				return nil, err
-			}
-			res = append(res, m)
-		}
-		return res, nil
-	}
-	// loadFeatures loads lists of named features from the archive file.
-	loadFeatures := func(data []byte) []string {
-		var feats []string
-		for line := range strings.Lines(string(data)) {
-			if f := strings.TrimSpace(line); f != "" {
-				feats = append(feats, f)
-			}
+		if err := test.Update("client", clientMessages); err != nil {
+			t.Fatal(err)
 		}
-		return feats
+	} else if diff := conformance.Diff(test.Client, clientMessages); diff != "" {
+		t.Errorf("Mismatching client messages (-want +got):\n%s", diff)
 	}
-
-	seen := make(map[string]bool) // catch accidentally duplicate files
-	for _, f := range test.archive.Files {
-		if seen[f.Name] {
-			return nil, fmt.Errorf("duplicate file name %q", f.Name)
-		}
-		seen[f.Name] = true
-		switch f.Name {
-		case "tools":
-			test.tools = loadFeatures(f.Data)
-		case "prompts":
-			test.prompts = loadFeatures(f.Data)
-		case "resources":
-			test.resources = loadFeatures(f.Data)
-		case "client":
-			test.client, err = decodeMessages(f.Data)
-			if err != nil {
-				return nil, fmt.Errorf("txtar archive %q contains bad -- client -- section: %v", path, err)
-			}
-		case "server":
-			test.server, err = decodeMessages(f.Data)
-			if err != nil {
-				return nil, fmt.Errorf("txtar archive %q contains bad -- server -- section: %v", path, err)
-			}
-		default:
-			return nil, fmt.Errorf("txtar archive %q contains unexpected file %q", path, f.Name)
-		}
-	}
-
-	return test, nil
 }
@@ -0,0 +1,206 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// A CursorCodec encodes the state of a paginated list, such as the position
+// of the last item listed, into the opaque cursors sent to clients, and
+// decodes them when clients send them back. See [ServerOptions.CursorCodec].
+//
+// Since cursors come from clients, Decode must reject cursors that it did not
+// produce. It should also reject cursors from an incompatible encoding, rather
+// than misinterpret them.
+type CursorCodec interface {
+	// Encode returns a cursor holding data.
+	Encode(data []byte) (string, error)
+	// Decode returns the data held by cursor, or an error if cursor is invalid
+	// or has expired.
+	Decode(cursor string) ([]byte, error)
+}
+
+// cursorVersion is the version of the encodings of [NewHMACCursorCodec] and
+// [NewUnsignedCursorCodec].
+const cursorVersion = "1"
+
+// defaultCursorCodec returns the codec of servers without a
+// [ServerOptions.CursorCodec]. Its key is random, and shared by all servers
+// of the process, so that servers created per request can continue each
+// other's lists.
+var defaultCursorCodec = sync.OnceValue(func() CursorCodec {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return NewHMACCursorCodec(key, 0)
+})
+
+// NewUnsignedCursorCodec returns a [CursorCodec] whose cursors are versioned
+// but not signed, so that any server can decode them, such as another replica
+// of a stateless server.
+//
+// Clients can forge such cursors, including the cursors of providers (see
+// [ToolProvider]), which then receive cursors chosen by the client. Prefer
+// [NewHMACCursorCodec] with a key shared by all replicas.
+func NewUnsignedCursorCodec() CursorCodec {
+	return plainCursorCodec{}
+}
+
+// plainCursorCodec is the codec of [NewUnsignedCursorCodec]. A cursor has the
+// form version.payload.
+type plainCursorCodec struct{}
+
+func (plainCursorCodec) Encode(data []byte) (string, error) {
+	return cursorVersion + "." + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func (plainCursorCodec) Decode(cursor string) ([]byte, error) {
+	version, payload, ok := strings.Cut(cursor, ".")
+	if !ok || strings.Contains(payload, ".") {
+		return nil, errors.New("malformed cursor")
+	}
+	if version != cursorVersion {
+		return nil, fmt.Errorf("unsupported cursor version %q", version)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	return data, nil
+}
+
+// NewHMACCursorCodec returns a [CursorCodec] whose cursors are signed with
+// HMAC-SHA256 using the given key, so that clients can neither forge nor
+// modify them. Its cursors are versioned, so that a later encoding can reject
+// them cleanly. If maxAge is positive, cursors expire after that duration.
+//
+// Servers that share pagination state, such as multiple processes serving the
+// same stateless endpoint, must use the same key. By default, servers sign
+// cursors with a random key shared by the servers of the process.
+//
+// NewHMACCursorCodec panics if key is empty.
+func NewHMACCursorCodec(key []byte, maxAge time.Duration) CursorCodec {
+	if len(key) == 0 {
+		panic("NewHMACCursorCodec: empty key")
+	}
+	return &hmacCursorCodec{key: key, maxAge: max(maxAge, 0)}
+}
+
+type hmacCursorCodec struct {
+	key    []byte
+	maxAge time.Duration // if non-zero, the lifetime of cursors
+}
+
+// cursorEnvelope is the signed content of a cursor.
+type cursorEnvelope struct {
+	Data    []byte `json:"d"`
+	Expires int64  `json:"exp,omitempty"` // Unix time; zero if the cursor doesn't expire
+}
+
+// A cursor has the form version.payload.signature, where payload is the
+// encoded cursorEnvelope and the signature covers version.payload.
+func (c *hmacCursorCodec) Encode(data []byte) (string, error) {
+	env := cursorEnvelope{Data: data}
+	if c.maxAge != 0 {
+		env.Expires = time.Now().Add(c.maxAge).Unix()
+	}
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return "", err
+	}
+	signed := cursorVersion + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(c.sign(signed)), nil
+}
+
+func (c *hmacCursorCodec) Decode(cursor string) ([]byte, error) {
+	version, rest, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, errors.New("malformed cursor")
+	}
+	if version != cursorVersion {
+		return nil, fmt.Errorf("unsupported cursor version %q", version)
+	}
+	encPayload, encSig, ok := strings.Cut(rest, ".")
+	if !ok {
+		return nil, errors.New("malformed cursor")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encSig)
+	if err != nil || !hmac.Equal(sig, c.sign(version+"."+encPayload)) {
+		return nil, errors.New("cursor signature mismatch")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	var env cursorEnvelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	if env.Expires != 0 && time.Now().Unix() > env.Expires {
+		return nil, errors.New("cursor expired")
+	}
+	return env.Data, nil
+}
+
+func (c *hmacCursorCodec) sign(s string) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
+
+// pageToken is the internal structure for the opaque pagination cursor.
+// It is encoded as JSON, and then by a [CursorCodec].
+type pageToken struct {
+	LastUID string `json:"last,omitempty"` // The unique ID of the last resource seen.
+
+	// For features listed by providers (see [Server.AddToolProvider]):
+	Provider       int    `json:"provider,omitempty"`       // 1 + the index of the provider, or 0 for the server's own features
+	ProviderCursor string `json:"providerCursor,omitempty"` // the provider's cursor for its next page
+}
+
+// encodeToken encodes token into an opaque pagination cursor with codec.
+func encodeToken(codec CursorCodec, token pageToken) (string, error) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode page token: %w", err)
+	}
+	return codec.Encode(data)
+}
+
+// decodeToken decodes a pagination cursor produced by encodeToken.
+// If the cursor is invalid, it returns an error with code
+// [jsonrpc.CodeInvalidParams], as the spec requires.
+func decodeToken(codec CursorCodec, cursor string) (pageToken, error) {
+	var token pageToken
+	data, err := codec.Decode(cursor)
+	if err == nil {
+		err = json.Unmarshal(data, &token)
+	}
+	if err != nil {
+		return pageToken{}, invalidCursorError(err)
+	}
+	return token, nil
+}
+
+// invalidCursorError returns the error for an invalid cursor.
+func invalidCursorError(err error) error {
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: fmt.Sprintf("invalid cursor: %v", err),
+	}
+}
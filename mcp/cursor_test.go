@@ -0,0 +1,127 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+func TestHMACCursorCodec(t *testing.T) {
+	codec := NewHMACCursorCodec([]byte("key"), time.Hour)
+	cursor, err := codec.Encode([]byte("state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := codec.Decode(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "state" {
+		t.Errorf("Decode(Encode(%q)) = %q", "state", data)
+	}
+
+	expired, err := (&hmacCursorCodec{key: []byte("key"), maxAge: -time.Hour}).Encode([]byte("state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, rest, _ := strings.Cut(cursor, ".")
+	payload, sig, _ := strings.Cut(rest, ".")
+	for _, tt := range []struct {
+		name, cursor, wantErr string
+	}{
+		{"garbage", "not-a-cursor", "malformed"},
+		{"version", "2." + rest, "unsupported cursor version"},
+		{"tampered", version + "." + payload + "x." + sig, "signature mismatch"},
+		{"wrong key", mustEncode(t, NewHMACCursorCodec([]byte("other"), 0), "state"), "signature mismatch"},
+		{"expired", expired, "expired"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := codec.Decode(tt.cursor); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Decode: got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func mustEncode(t *testing.T, codec CursorCodec, data string) string {
+	t.Helper()
+	cursor, err := codec.Encode([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cursor
+}
+
+func TestServerCursorCodec(t *testing.T) {
+	ctx := context.Background()
+	// Two servers with the same key can continue each other's lists.
+	newServer := func() *Server {
+		server := NewServer(testImpl, &ServerOptions{
+			PageSize:    1,
+			CursorCodec: NewHMACCursorCodec([]byte("shared key"), time.Minute),
+		})
+		for _, name := range []string{"a", "b"} {
+			AddTool(server, &Tool{Name: name}, func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+				return nil, nil, nil
+			})
+		}
+		return server
+	}
+	cs1, _, _ := basicClientServerConnection(t, nil, newServer(), nil)
+	cs2, _, _ := basicClientServerConnection(t, nil, newServer(), nil)
+	res, err := cs1.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor := res.NextCursor
+	res, err = cs2.ListTools(ctx, &ListToolsParams{Cursor: cursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Tools) != 1 || res.Tools[0].Name != "b" {
+		t.Errorf("second page: got %v, want tool b", res.Tools)
+	}
+
+	// A server with another key rejects the cursor, as does a server with
+	// the default codec.
+	for _, codec := range []CursorCodec{NewHMACCursorCodec([]byte("other key"), 0), nil} {
+		cs3, _, _ := basicClientServerConnection(t, nil, NewServer(testImpl, &ServerOptions{CursorCodec: codec}), nil)
+		_, err = cs3.ListTools(ctx, &ListToolsParams{Cursor: cursor})
+		var werr *jsonrpc.Error
+		if !errors.As(err, &werr) || werr.Code != jsonrpc.CodeInvalidParams || !strings.Contains(werr.Message, "invalid cursor") {
+			t.Errorf("got error %v, want invalid params error for the cursor", err)
+		}
+	}
+}
+
+func TestUnsignedCursorCodec(t *testing.T) {
+	// Unsigned cursors don't depend on the process, so that replicas of a
+	// server can continue each other's lists.
+	codec := NewUnsignedCursorCodec()
+	cursor := mustEncode(t, codec, "state")
+	if want := "1.c3RhdGU"; cursor != want {
+		t.Errorf("Encode(%q) = %q, want %q", "state", cursor, want)
+	}
+	data, err := codec.Decode(cursor)
+	if err != nil || string(data) != "state" {
+		t.Errorf("Decode(%q) = %q, %v, want %q", cursor, data, err, "state")
+	}
+	for _, bad := range []string{"state", "2.c3RhdGU", "1.!!", "1.c3RhdGU.c2ln"} {
+		if _, err := codec.Decode(bad); err == nil {
+			t.Errorf("Decode(%q) succeeded, want error", bad)
+		}
+	}
+
+	// The default codec rejects unsigned cursors.
+	if _, err := defaultCursorCodec().Decode(cursor); err == nil {
+		t.Errorf("default codec decoded unsigned cursor %q", cursor)
+	}
+}
@@ -0,0 +1,623 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// An ElicitAction is the user's response to an elicitation request.
+type ElicitAction string
+
+const (
+	// ElicitAccept means that the user submitted the form or confirmed the
+	// action.
+	ElicitAccept ElicitAction = "accept"
+	// ElicitDecline means that the user explicitly declined the action.
+	ElicitDecline ElicitAction = "decline"
+	// ElicitCancel means that the user dismissed the request without making an
+	// explicit choice.
+	ElicitCancel ElicitAction = "cancel"
+)
+
+// An ElicitOutcome is the result of a typed elicitation using [Elicit].
+type ElicitOutcome[T any] struct {
+	// Action is the user's response.
+	Action ElicitAction
+	// Value holds the submitted form data if Action is ElicitAccept, and is the
+	// zero value otherwise.
+	Value T
+	// Result is the underlying result returned by the client.
+	Result *ElicitResult
+}
+
+// Accepted reports whether the user submitted the form.
+func (o *ElicitOutcome[T]) Accepted() bool { return o.Action == ElicitAccept }
+
+// Elicit asks the client to present a form to the user, and decodes the
+// submitted data into a T.
+//
+// T must be a struct type. The requested schema is inferred from T, and must
+// satisfy the restrictions that the spec places on elicitation schemas: the
+// schema is flat, and each property has type string, number, integer, or
+// boolean. Properties are named as by encoding/json, and are required unless
+// they are pointers, are marked omitempty, or have a default. As with
+// [AddTool], property descriptions are read from the 'jsonschema' struct tag.
+//
+// Additional constraints are read from the 'elicit' struct tag, which is a
+// comma-separated list of key=value pairs. A backslash escapes the following
+// character, so that values can contain commas and '|' (in a struct tag,
+// the backslash itself must be written as \\). Supported keys are:
+//
+//   - title: the property title.
+//   - format: for strings, one of email, uri, date, or date-time.
+//   - enum: for strings, the allowed values, separated by '|'.
+//   - enumNames: display names for the enum values, separated by '|'.
+//   - min, max: for numbers, the minimum and maximum values; for strings, the
+//     minimum and maximum lengths.
+//   - default: the default value.
+//
+// For example:
+//
+//	type Order struct {
+//		Email    string `json:"email" elicit:"title=Email address,format=email"`
+//		Size     string `json:"size" elicit:"enum=s|m|l,enumNames=Small|Medium|Large,default=m"`
+//		Quantity int    `json:"quantity" elicit:"min=1,max=10"`
+//		Note     string `json:"note,omitempty" elicit:"title=Note\\, if any"`
+//		Gift     bool   `json:"gift,omitempty"`
+//	}
+//
+// Fields of type [time.Time] have type string with format date-time.
+//
+// Elicit returns an error if T does not satisfy these restrictions, if the
+// elicitation request fails, or if the accepted content cannot be decoded.
+func Elicit[T any](ctx context.Context, ss *ServerSession, message string) (*ElicitOutcome[T], error) {
+	schema, err := elicitSchemaFor(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	res, err := ss.Elicit(ctx, &ElicitParams{
+		Mode:            "form",
+		Message:         message,
+		RequestedSchema: schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := &ElicitOutcome[T]{Action: ElicitAction(res.Action), Result: res}
+	if out.Accepted() {
+		if err := remarshal(res.Content, &out.Value); err != nil {
+			return nil, fmt.Errorf("decoding elicitation result: %w", err)
+		}
+	}
+	return out, nil
+}
+
+// elicitSchemas caches the schemas inferred by elicitSchemaFor.
+var elicitSchemas sync.Map // reflect.Type -> *jsonschema.Schema, or error
+
+// elicitSchemaFor returns the elicitation schema for the struct type t, as
+// described by [Elicit].
+//
+// The result is shared and must not be modified.
+func elicitSchemaFor(t reflect.Type) (*jsonschema.Schema, error) {
+	if v, ok := elicitSchemas.Load(t); ok {
+		if err, ok := v.(error); ok {
+			return nil, err
+		}
+		return v.(*jsonschema.Schema), nil
+	}
+	schema, err := inferElicitSchema(t)
+	if err == nil {
+		// Apply the same rules as the client, so that invalid schemas are
+		// rejected before they are sent.
+		_, err = validateElicitSchema(schema)
+	}
+	if err != nil {
+		err = fmt.Errorf("elicitation schema for %v: %w", t, err)
+		elicitSchemas.Store(t, err)
+		return nil, err
+	}
+	elicitSchemas.Store(t, schema)
+	return schema, nil
+}
+
+func inferElicitSchema(t reflect.Type) (*jsonschema.Schema, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("type must be a struct, got %v", t.Kind())
+	}
+	schema := &jsonschema.Schema{
+		Type:       "object",
+		Properties: make(map[string]*jsonschema.Schema),
+	}
+	if err := addElicitProperties(schema, t); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// addElicitProperties adds a property to schema for each field of the struct
+// type t, flattening embedded structs as encoding/json does.
+func addElicitProperties(schema *jsonschema.Schema, t reflect.Type) error {
+	for field := range fieldsOf(t) {
+		name, omitempty, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		ft := field.Type
+		optional := omitempty
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+			optional = true
+		}
+		if field.Anonymous && ft.Kind() == reflect.Struct && ft != timeType && field.Tag.Get("json") == "" {
+			if err := addElicitProperties(schema, ft); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		prop, err := elicitProperty(name, ft, field.Tag)
+		if err != nil {
+			return err
+		}
+		schema.Properties[name] = prop
+		// Properties with defaults need not be provided.
+		if !optional && prop.Default == nil {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return nil
+}
+
+// fieldsOf yields the fields of the struct type t.
+func fieldsOf(t reflect.Type) func(func(reflect.StructField) bool) {
+	return func(yield func(reflect.StructField) bool) {
+		for i := range t.NumField() {
+			if !yield(t.Field(i)) {
+				return
+			}
+		}
+	}
+}
+
+// jsonFieldName reports the JSON name of field, and whether it is marked
+// omitempty. It reports false if the field is not marshaled.
+func jsonFieldName(field reflect.StructField) (name string, omitempty, ok bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitempty = true
+		}
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, omitempty, true
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// elicitProperty returns the schema for a property of type t, with the given
+// struct tag.
+func elicitProperty(name string, t reflect.Type, tag reflect.StructTag) (*jsonschema.Schema, error) {
+	prop := &jsonschema.Schema{Description: tag.Get("jsonschema")}
+	switch {
+	case t == timeType:
+		prop.Type = "string"
+		prop.Format = "date-time"
+	case t.Kind() == reflect.String:
+		prop.Type = "string"
+	case t.Kind() == reflect.Bool:
+		prop.Type = "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		prop.Type = "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		prop.Type = "number"
+	default:
+		return nil, fmt.Errorf("property %q has unsupported type %v, only strings, numbers, and booleans are allowed", name, t)
+	}
+	v, ok := tag.Lookup("elicit")
+	if !ok {
+		return prop, nil
+	}
+	for _, kv := range splitEscaped(v, ',') {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("property %q: elicit tag %q has no value", name, kv)
+		}
+		if err := setElicitConstraint(prop, key, value); err != nil {
+			return nil, fmt.Errorf("property %q: %w", name, err)
+		}
+	}
+	return prop, nil
+}
+
+// setElicitConstraint sets the constraint with the given key from the 'elicit'
+// struct tag on prop. The value may contain escapes.
+func setElicitConstraint(prop *jsonschema.Schema, key, rawValue string) error {
+	isString := prop.Type == "string"
+	value, err := unescape(rawValue)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %v", key, rawValue, err)
+	}
+	var list []string // for enum and enumNames, the values separated by '|'
+	for _, v := range splitEscaped(rawValue, '|') {
+		u, _ := unescape(v) // valid, since rawValue is
+		list = append(list, u)
+	}
+	switch key {
+	case "title":
+		prop.Title = value
+	case "format":
+		prop.Format = value
+	case "enum":
+		for _, e := range list {
+			prop.Enum = append(prop.Enum, e)
+		}
+	case "enumNames":
+		var names []any
+		for _, n := range list {
+			names = append(names, n)
+		}
+		if prop.Extra == nil {
+			prop.Extra = make(map[string]any)
+		}
+		prop.Extra["enumNames"] = names
+	case "min", "max":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", key, value)
+		}
+		switch {
+		case isString && key == "min":
+			prop.MinLength = jsonschema.Ptr(int(f))
+		case isString:
+			prop.MaxLength = jsonschema.Ptr(int(f))
+		case prop.Type == "boolean":
+			return fmt.Errorf("%s is not supported for booleans", key)
+		case key == "min":
+			prop.Minimum = &f
+		default:
+			prop.Maximum = &f
+		}
+	case "default":
+		var def any = value
+		if !isString {
+			// Numbers and booleans have the same JSON and Go syntax.
+			def = json.RawMessage(value)
+		}
+		data, err := json.Marshal(def)
+		if err != nil {
+			return fmt.Errorf("invalid default %q", value)
+		}
+		prop.Default = data
+	default:
+		return fmt.Errorf("unknown elicit tag key %q", key)
+	}
+	return nil
+}
+
+// splitEscaped splits s at each sep that is not escaped by a backslash.
+// The parts keep their escapes.
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++ // skip the escaped character
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescape removes the backslashes that escape characters in s.
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			if i == len(s) {
+				return "", errors.New("trailing backslash")
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String(), nil
+}
+
+// A URLElicitor coordinates URL mode elicitations. It mints elicitation IDs
+// and URLs, tracks pending elicitations, and notifies the client when they
+// are complete.
+//
+// A URLElicitor is also an [http.Handler] that serves the callback endpoint
+// for its elicitations: when the user's browser is directed to the URL of a
+// pending elicitation (for example, as the redirect URL at the end of an
+// OAuth or payment flow), [URLElicitorOptions.HandleCallback] verifies the
+// request, and if it succeeds, the elicitation is marked complete and the
+// session that started it is sent a notifications/elicitation/complete
+// notification.
+//
+// The URL of an elicitation is not a secret: it is shown to the user, and may
+// be fetched by others, such as the client or a link previewer. So the
+// callback must tie completion to the user who started the elicitation, as
+// the spec requires.
+//
+// A typical use is:
+//
+//	elicitor := mcp.NewURLElicitor("https://example.com/elicitation", &mcp.URLElicitorOptions{
+//		HandleCallback: verifyOAuthCallback,
+//	})
+//	http.Handle("/elicitation", elicitor)
+//	...
+//	// In a tool handler:
+//	p, err := elicitor.Start(req.Session, "Please authorize access to your calendar.")
+//	if err != nil {
+//		return nil, nil, err
+//	}
+//	return nil, nil, mcp.URLElicitationRequiredError([]*mcp.ElicitParams{p.Params()})
+type URLElicitor struct {
+	baseURL string
+	opts    URLElicitorOptions
+
+	mu      sync.Mutex
+	pending map[string]*PendingElicitation
+}
+
+// URLElicitorOptions configures a [URLElicitor].
+type URLElicitorOptions struct {
+	// Expiry is how long an elicitation remains pending before it is
+	// discarded. It defaults to 10 minutes.
+	Expiry time.Duration
+	// HandleCallback is called when the callback endpoint is visited for a
+	// pending elicitation, before the elicitation is marked complete. It must
+	// verify that the request comes from the user who started the
+	// elicitation (for example, by exchanging an OAuth authorization code, or
+	// by checking the user's login session), and may write the response.
+	// It is required.
+	//
+	// HandleCallback is not called concurrently for the same elicitation:
+	// while it runs, other requests for the elicitation fail with status 409
+	// Conflict.
+	//
+	// If HandleCallback returns an error, the elicitation is not completed,
+	// and, unless HandleCallback wrote a response, the error is reported with
+	// status 400 Bad Request.
+	//
+	// If HandleCallback doesn't write a response, a short plain-text
+	// confirmation is written.
+	HandleCallback func(http.ResponseWriter, *http.Request, *PendingElicitation) error
+}
+
+// ElicitationIDParam is the name of the query parameter holding the
+// elicitation ID in URLs minted by a [URLElicitor].
+const ElicitationIDParam = "elicitation"
+
+// defaultElicitationExpiry is the default value of
+// [URLElicitorOptions.Expiry].
+const defaultElicitationExpiry = 10 * time.Minute
+
+// NewURLElicitor returns a new URLElicitor.
+//
+// The baseURL is the URL at which the elicitor is served as an
+// [http.Handler]. The URL of each elicitation is baseURL, with the
+// elicitation ID added as the [ElicitationIDParam] query parameter.
+//
+// The first argument must not be empty or malformed, and opts must set
+// HandleCallback; otherwise, NewURLElicitor panics.
+func NewURLElicitor(baseURL string, opts *URLElicitorOptions) *URLElicitor {
+	if _, err := url.Parse(baseURL); baseURL == "" || err != nil {
+		panic(fmt.Sprintf("NewURLElicitor: invalid base URL %q", baseURL))
+	}
+	if opts == nil || opts.HandleCallback == nil {
+		panic("NewURLElicitor: missing HandleCallback")
+	}
+	e := &URLElicitor{
+		baseURL: baseURL,
+		opts:    *opts,
+		pending: make(map[string]*PendingElicitation),
+	}
+	if e.opts.Expiry <= 0 {
+		e.opts.Expiry = defaultElicitationExpiry
+	}
+	return e
+}
+
+// A PendingElicitation is a URL mode elicitation started by a [URLElicitor].
+type PendingElicitation struct {
+	// ID is the elicitation ID.
+	ID string
+	// URL is the URL to present to the user.
+	URL string
+	// Message is the message to present to the user.
+	Message string
+	// Session is the session that started the elicitation, which is notified
+	// when it is complete.
+	Session *ServerSession
+	// Expires is the time after which the elicitation is discarded.
+	Expires time.Time
+
+	done    chan struct{} // closed when complete
+	claimed bool          // a callback is in progress; guarded by URLElicitor.mu
+}
+
+// Params returns the parameters for requesting the elicitation from the
+// client, using either [ServerSession.Elicit] or
+// [URLElicitationRequiredError].
+func (p *PendingElicitation) Params() *ElicitParams {
+	return &ElicitParams{
+		Mode:          "url",
+		Message:       p.Message,
+		URL:           p.URL,
+		ElicitationID: p.ID,
+	}
+}
+
+// Done returns a channel that is closed when the elicitation is complete.
+func (p *PendingElicitation) Done() <-chan struct{} { return p.done }
+
+// Start starts a new elicitation on behalf of the given session, returning
+// the pending elicitation. The client must then be asked to visit its URL;
+// see [PendingElicitation.Params].
+//
+// The session must be non-nil: it is notified when the elicitation completes.
+func (e *URLElicitor) Start(ss *ServerSession, message string) (*PendingElicitation, error) {
+	if ss == nil {
+		return nil, errors.New("URLElicitor.Start: nil session")
+	}
+	id := randText()
+	u, _ := url.Parse(e.baseURL) // checked in NewURLElicitor
+	q := u.Query()
+	q.Set(ElicitationIDParam, id)
+	u.RawQuery = q.Encode()
+	now := time.Now()
+	p := &PendingElicitation{
+		ID:      id,
+		URL:     u.String(),
+		Message: message,
+		Session: ss,
+		Expires: now.Add(e.opts.Expiry),
+		done:    make(chan struct{}),
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// Discard expired elicitations, so that abandoned elicitations don't
+	// accumulate.
+	maps.DeleteFunc(e.pending, func(_ string, p *PendingElicitation) bool {
+		return now.After(p.Expires)
+	})
+	e.pending[id] = p
+	return p, nil
+}
+
+// Pending returns the pending elicitation with the given ID, or nil if there
+// is none, or it has expired.
+func (e *URLElicitor) Pending(id string) *PendingElicitation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p := e.pending[id]
+	if p != nil && time.Now().After(p.Expires) {
+		delete(e.pending, id)
+		return nil
+	}
+	return p
+}
+
+// ErrElicitationNotFound is returned by [URLElicitor.Complete] when there is
+// no pending elicitation with the given ID.
+var ErrElicitationNotFound = errors.New("elicitation not found or expired")
+
+// Complete marks the pending elicitation with the given ID as complete, and
+// notifies the session that started it.
+//
+// Complete can be used when completion is detected out of band, for example
+// by a payment provider's webhook, rather than by the callback endpoint.
+func (e *URLElicitor) Complete(ctx context.Context, id string) error {
+	e.mu.Lock()
+	p := e.pending[id]
+	delete(e.pending, id)
+	e.mu.Unlock()
+	if p == nil || time.Now().After(p.Expires) {
+		return ErrElicitationNotFound
+	}
+
+	close(p.done)
+	return p.Session.NotifyElicitationComplete(ctx, &ElicitationCompleteParams{ElicitationID: id})
+}
+
+// claim claims the pending elicitation with the given ID for a callback. It
+// returns the elicitation, or nil and the status to report if there is no such
+// elicitation or it is already claimed.
+func (e *URLElicitor) claim(id string) (*PendingElicitation, int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p := e.pending[id]
+	switch {
+	case p == nil || time.Now().After(p.Expires):
+		return nil, http.StatusNotFound
+	case p.claimed:
+		return nil, http.StatusConflict
+	}
+	p.claimed = true
+	return p, 0
+}
+
+// ServeHTTP implements the callback endpoint for the elicitor's elicitations.
+func (e *URLElicitor) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	id := req.URL.Query().Get(ElicitationIDParam)
+	p, status := e.claim(id)
+	if p == nil {
+		msg := ErrElicitationNotFound.Error()
+		if status == http.StatusConflict {
+			msg = "elicitation callback already in progress"
+		}
+		http.Error(w, msg, status)
+		return
+	}
+	rw := &trackingResponseWriter{ResponseWriter: w}
+	if err := e.opts.HandleCallback(rw, req, p); err != nil {
+		// Release the claim, so that the user can try again.
+		e.mu.Lock()
+		p.claimed = false
+		e.mu.Unlock()
+		if !rw.wrote {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	if err := e.Complete(req.Context(), id); err != nil && !errors.Is(err, ErrElicitationNotFound) {
+		// The elicitation is complete, but the client could not be notified
+		// (for example, because the session has ended). There's nothing more
+		// the user can do about it.
+		p.Session.server.opts.Logger.Error("notifying elicitation complete", "elicitation", id, "error", err)
+	}
+	if !rw.wrote {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "Done. You may close this window.")
+	}
+}
+
+// A trackingResponseWriter records whether a response was written.
+type trackingResponseWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *trackingResponseWriter) WriteHeader(code int) {
+	w.wrote = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *trackingResponseWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
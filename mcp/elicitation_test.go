@@ -6,11 +6,18 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 )
@@ -189,3 +196,269 @@ func TestElicitationCompleteNotification(t *testing.T) {
 		t.Fatal("timed out waiting for elicitation complete notification")
 	}
 }
+
+func TestElicitTyped(t *testing.T) {
+	ctx := context.Background()
+
+	type Contact struct {
+		Email string `json:"email" jsonschema:"where to reach you" elicit:"title=Email address,format=email"`
+		Phone string `json:"phone,omitempty"`
+	}
+	type Order struct {
+		Contact
+		Size     string    `json:"size" elicit:"enum=s|m|l,enumNames=Small|Medium|Large,default=m"`
+		Quantity int       `json:"quantity" elicit:"min=1,max=10"`
+		Gift     *bool     `json:"gift"`
+		When     time.Time `json:"when,omitzero"`
+		Note     string    `json:"note,omitempty" elicit:"title=Note\\, if any,enum=a\\|b|c\\\\d"`
+		internal int
+	}
+
+	t.Run("schema", func(t *testing.T) {
+		got, err := elicitSchemaFor(reflect.TypeFor[Order]())
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"email": {Type: "string", Title: "Email address", Description: "where to reach you", Format: "email"},
+				"phone": {Type: "string"},
+				"size": {
+					Type:    "string",
+					Enum:    []any{"s", "m", "l"},
+					Default: json.RawMessage(`"m"`),
+					Extra:   map[string]any{"enumNames": []any{"Small", "Medium", "Large"}},
+				},
+				"quantity": {Type: "integer", Minimum: jsonschema.Ptr(1.0), Maximum: jsonschema.Ptr(10.0)},
+				"gift":     {Type: "boolean"},
+				"when":     {Type: "string", Format: "date-time"},
+				"note":     {Type: "string", Title: "Note, if any", Enum: []any{"a|b", `c\d`}},
+			},
+			Required: []string{"email", "quantity"},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("schema mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("invalid schemas", func(t *testing.T) {
+		type nested struct {
+			Contact Contact `json:"contact"`
+		}
+		type badFormat struct {
+			S string `elicit:"format=phone"`
+		}
+		type badKey struct {
+			S string `elicit:"color=red"`
+		}
+		type badDefault struct {
+			N int `elicit:"default=many"`
+		}
+		type noValue struct {
+			S string `elicit:"title"`
+		}
+		type trailingBackslash struct {
+			S string `elicit:"title=x\\"`
+		}
+		for _, test := range []struct {
+			typ     reflect.Type
+			wantErr string
+		}{
+			{reflect.TypeFor[string](), "must be a struct"},
+			{reflect.TypeFor[nested](), `property "contact" has unsupported type`},
+			{reflect.TypeFor[badFormat](), `unsupported format "phone"`},
+			{reflect.TypeFor[badKey](), `unknown elicit tag key "color"`},
+			{reflect.TypeFor[badDefault](), `invalid default "many"`},
+			{reflect.TypeFor[noValue](), `elicit tag "title" has no value`},
+			{reflect.TypeFor[trailingBackslash](), "trailing backslash"},
+		} {
+			if _, err := elicitSchemaFor(test.typ); err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("elicitSchemaFor(%v) = %v, want error containing %q", test.typ, err, test.wantErr)
+			}
+		}
+	})
+
+	for _, action := range []ElicitAction{ElicitAccept, ElicitDecline, ElicitCancel} {
+		t.Run(string(action), func(t *testing.T) {
+			client := NewClient(testImpl, &ClientOptions{
+				ElicitationHandler: func(_ context.Context, req *ElicitRequest) (*ElicitResult, error) {
+					res := &ElicitResult{Action: string(action)}
+					if action == ElicitAccept {
+						res.Content = map[string]any{"email": "a@example.com", "quantity": 2, "gift": true}
+					}
+					return res, nil
+				},
+			})
+			_, ss, cleanup := basicClientServerConnection(t, client, nil, nil)
+			defer cleanup()
+
+			out, err := Elicit[Order](ctx, ss, "order details")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.Action != action {
+				t.Errorf("got action %q, want %q", out.Action, action)
+			}
+			var want Order
+			if action == ElicitAccept {
+				gift := true
+				want = Order{Contact: Contact{Email: "a@example.com"}, Size: "m", Quantity: 2, Gift: &gift}
+			}
+			if diff := cmp.Diff(want, out.Value, cmpopts.IgnoreUnexported(Order{})); diff != "" {
+				t.Errorf("value mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestURLElicitor(t *testing.T) {
+	ctx := context.Background()
+
+	completed := make(chan string, 10)
+	client := NewClient(testImpl, &ClientOptions{
+		ElicitationModes: []string{"url"},
+		ElicitationHandler: func(context.Context, *ElicitRequest) (*ElicitResult, error) {
+			return &ElicitResult{Action: "accept"}, nil
+		},
+		ElicitationCompleteHandler: func(_ context.Context, req *ElicitationCompleteNotificationRequest) {
+			completed <- req.Params.ElicitationID
+		},
+	})
+	_, ss, cleanup := basicClientServerConnection(t, client, nil, nil)
+	defer cleanup()
+
+	var elicitor *URLElicitor
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		elicitor.ServeHTTP(w, req)
+	}))
+	defer httpServer.Close()
+	var (
+		callbacks atomic.Int32
+		block     = make(chan struct{}) // closed to release callbacks with code=slow
+		entered   = make(chan struct{}, 1)
+	)
+	elicitor = NewURLElicitor(httpServer.URL+"/callback?app=test", &URLElicitorOptions{
+		HandleCallback: func(w http.ResponseWriter, req *http.Request, p *PendingElicitation) error {
+			callbacks.Add(1)
+			switch req.URL.Query().Get("code") {
+			case "bad":
+				return errors.New("invalid code")
+			case "slow":
+				entered <- struct{}{}
+				<-block
+			}
+			return nil
+		},
+	})
+
+	visit := func(u string) int {
+		t.Helper()
+		resp, err := http.Get(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	p, err := elicitor.Start(ss, "Please sign in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(p.URL, httpServer.URL+"/callback?") || !strings.Contains(p.URL, "app=test") {
+		t.Errorf("got URL %q, want callback URL with original query", p.URL)
+	}
+	if _, err := ss.Elicit(ctx, p.Params()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed callback doesn't complete the elicitation.
+	if got := visit(p.URL + "&code=bad"); got != http.StatusBadRequest {
+		t.Errorf("bad callback: got status %d, want %d", got, http.StatusBadRequest)
+	}
+	if elicitor.Pending(p.ID) == nil {
+		t.Fatal("elicitation no longer pending after failed callback")
+	}
+
+	// Concurrent callbacks for the same elicitation don't both run
+	// HandleCallback.
+	callbacks.Store(0)
+	slow := make(chan int)
+	go func() { slow <- visit(p.URL + "&code=slow") }()
+	<-entered
+	if got := visit(p.URL + "&code=good"); got != http.StatusConflict {
+		t.Errorf("concurrent callback: got status %d, want %d", got, http.StatusConflict)
+	}
+	close(block)
+	if got := <-slow; got != http.StatusOK {
+		t.Errorf("callback: got status %d, want %d", got, http.StatusOK)
+	}
+	if got := callbacks.Load(); got != 1 {
+		t.Errorf("HandleCallback called %d times, want 1", got)
+	}
+	select {
+	case <-p.Done():
+	default:
+		t.Error("elicitation not done after callback")
+	}
+	select {
+	case id := <-completed:
+		if id != p.ID {
+			t.Errorf("got completion for %q, want %q", id, p.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for elicitation complete notification")
+	}
+
+	// Completed elicitations can't be completed again.
+	if got := visit(p.URL); got != http.StatusNotFound {
+		t.Errorf("second callback: got status %d, want %d", got, http.StatusNotFound)
+	}
+	if err := elicitor.Complete(ctx, p.ID); !errors.Is(err, ErrElicitationNotFound) {
+		t.Errorf("Complete after callback = %v, want ErrElicitationNotFound", err)
+	}
+
+	// Out of band completion.
+	p2, err := elicitor.Start(ss, "Please pay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := elicitor.Complete(ctx, p2.ID); err != nil {
+		t.Fatal(err)
+	}
+	if id := <-completed; id != p2.ID {
+		t.Errorf("got completion for %q, want %q", id, p2.ID)
+	}
+
+	// Expired elicitations are discarded.
+	expiring := NewURLElicitor(httpServer.URL, &URLElicitorOptions{
+		Expiry:         time.Nanosecond,
+		HandleCallback: func(http.ResponseWriter, *http.Request, *PendingElicitation) error { return nil },
+	})
+	if _, err := expiring.Start(nil, "No session"); err == nil {
+		t.Error("Start with nil session succeeded, want error")
+	}
+	p3, err := expiring.Start(ss, "Too late")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if expiring.Pending(p3.ID) != nil {
+		t.Error("expired elicitation still pending")
+	}
+	if err := expiring.Complete(ctx, p3.ID); !errors.Is(err, ErrElicitationNotFound) {
+		t.Errorf("Complete after expiry = %v, want ErrElicitationNotFound", err)
+	}
+}
+
+func TestNewURLElicitorRequiresCallback(t *testing.T) {
+	// Without a callback, anyone who fetches the URL would complete the
+	// elicitation.
+	defer func() {
+		if recover() == nil {
+			t.Error("NewURLElicitor without HandleCallback did not panic")
+		}
+	}()
+	NewURLElicitor("https://example.com/elicitation", nil)
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// This file defines how the errors of server handlers are reported to clients.
+//
+// An error returned by a tool handler is reported in one of two ways:
+//   - as a tool error: a successful response whose [CallToolResult] has
+//     IsError set, so that the model can see the error and react to it; or
+//   - as a protocol error: a JSON-RPC error response, for errors the model
+//     can't help with, such as an unknown tool or invalid arguments.
+//
+// The rules are:
+//   - A [*jsonrpc.Error] is a protocol error, with its code and message.
+//   - A [*ToolError] is a tool error.
+//   - Other errors from a [ToolHandlerFor] are tool errors, and other errors
+//     from a [ToolHandler] are protocol errors.
+//
+// A server's [ErrorRegistry] can change how errors are reported, by mapping
+// them to a *jsonrpc.Error or *ToolError. The registry also applies to the
+// errors of other methods, which are always protocol errors.
+//
+// A handler that panics fails the request with an internal error. The panic
+// and its stack trace are logged with [ServerOptions.Logger].
+
+// retryableMetaKey is the key in [CallToolResult.Meta] marking a tool error
+// as retryable.
+const retryableMetaKey = "go.modelcontextprotocol.io/retryable"
+
+// mappedErrorKey is the context key for an *error holding the error that a
+// handler has already mapped with the server's error registry, so that
+// [ServerSession.handle] doesn't map it again.
+type mappedErrorKey struct{}
+
+// A ToolError is an error that a tool handler reports to the model as the
+// result of the call, rather than as a JSON-RPC error. See
+// [CallToolResult.SetError].
+type ToolError struct {
+	// Err is the underlying error. Its text is the content of the result,
+	// unless Content is set.
+	Err error
+	// Content is the content of the result. If nil, the content is the text
+	// of the error.
+	Content []Content
+	// Details, if non-nil, is the structured content of the result, giving
+	// details of the error.
+	Details any
+	// Retryable reports whether the call may succeed if retried unchanged.
+	// If set, the result's _meta has "go.modelcontextprotocol.io/retryable":
+	// true.
+	Retryable bool
+}
+
+func (e *ToolError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	for _, c := range e.Content {
+		if t, ok := c.(*TextContent); ok {
+			return t.Text
+		}
+	}
+	return "tool error"
+}
+
+func (e *ToolError) Unwrap() error { return e.Err }
+
+// An ErrorRegistry maps the errors of server handlers to the errors reported
+// to clients. See [ServerOptions.ErrorRegistry].
+//
+// Mappings are tried in the order in which they were registered, and the
+// first that applies is used. Errors that are already a [*jsonrpc.Error] are
+// not mapped.
+//
+// The zero ErrorRegistry has no mappings and is ready to use. It is safe for
+// concurrent use.
+type ErrorRegistry struct {
+	mu       sync.Mutex
+	mappings []func(error) error // each returns nil if it doesn't apply
+}
+
+// MapCode reports errors that match target, as reported by [errors.Is], as
+// JSON-RPC errors with the given code and the text of the error.
+func (r *ErrorRegistry) MapCode(target error, code int64) {
+	r.add(func(err error) error {
+		if !errors.Is(err, target) {
+			return nil
+		}
+		return &jsonrpc.Error{Code: code, Message: err.Error()}
+	})
+}
+
+// MapToolError reports errors that match target, as reported by [errors.Is],
+// as tool errors with the given retryable flag. For methods other than
+// tools/call, such errors are reported as they would be without the mapping.
+func (r *ErrorRegistry) MapToolError(target error, retryable bool) {
+	r.add(func(err error) error {
+		if !errors.Is(err, target) {
+			return nil
+		}
+		return &ToolError{Err: err, Retryable: retryable}
+	})
+}
+
+// MapErrorType registers a mapping for errors of type E, as found by
+// [errors.As]. The function f returns the error to report, typically a
+// [*jsonrpc.Error] or a [*ToolError], or nil to leave the error unmapped.
+func MapErrorType[E error](r *ErrorRegistry, f func(E) error) {
+	r.add(func(err error) error {
+		var e E
+		if !errors.As(err, &e) {
+			return nil
+		}
+		return f(e)
+	})
+}
+
+func (r *ErrorRegistry) add(m func(error) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mappings = append(r.mappings, m)
+}
+
+// mapError returns the error to report for err: the result of the first
+// mapping that applies, or err itself. It reports whether a mapping applied.
+// It is safe to call on a nil registry.
+func (r *ErrorRegistry) mapError(err error) (error, bool) {
+	if r == nil || err == nil {
+		return err, false
+	}
+	if _, ok := err.(*jsonrpc.Error); ok {
+		return err, false
+	}
+	r.mu.Lock()
+	mappings := r.mappings
+	r.mu.Unlock()
+	for _, m := range mappings {
+		if mapped := m(err); mapped != nil {
+			return mapped, true
+		}
+	}
+	return err, false
+}
+
+// mapErrorOnce is like mapError, but returns err unchanged if it is the error
+// recorded by setMapped in ctx.
+func (r *ErrorRegistry) mapErrorOnce(ctx context.Context, err error) error {
+	if p, ok := ctx.Value(mappedErrorKey{}).(*error); ok && *p != nil && sameError(*p, err) {
+		return err
+	}
+	mapped, _ := r.mapError(err)
+	return mapped
+}
+
+// setMapped records in ctx that err has been mapped, if ctx was prepared by
+// [ServerSession.handle].
+func setMapped(ctx context.Context, err error) {
+	if p, ok := ctx.Value(mappedErrorKey{}).(*error); ok {
+		*p = err
+	}
+}
+
+// sameError reports whether a and b are the same error value, without
+// panicking on errors of uncomparable types.
+func sameError(a, b error) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// reportToolError applies the server's error registry to the outcome of a
+// tool call, whether the handler returned an error or reported it in its
+// result. Errors that map to tool errors are reported in the result, and
+// other mapped errors as protocol errors.
+func (s *Server) reportToolError(res *CallToolResult, err error) (*CallToolResult, error) {
+	herr := err
+	if herr == nil && res != nil && res.IsError {
+		herr = res.err
+	}
+	if herr == nil {
+		return res, nil
+	}
+	mapped, ok := s.opts.ErrorRegistry.mapError(herr)
+	var terr *ToolError
+	if errors.As(mapped, &terr) {
+		if err == nil && !ok {
+			return res, nil // already reported in the result
+		}
+		res = new(CallToolResult)
+		res.SetError(mapped)
+		return res, nil
+	}
+	if !ok {
+		return res, err
+	}
+	return nil, mapped
+}
@@ -5,13 +5,18 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"strings"
 	"sync/atomic"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 )
 
@@ -351,3 +356,144 @@ func TestURLElicitationRequired(t *testing.T) {
 		})
 	})
 }
+
+type quotaError struct{ limit int }
+
+func (e *quotaError) Error() string { return fmt.Sprintf("quota of %d exceeded", e.limit) }
+
+type countedError struct{}
+
+func (*countedError) Error() string { return "counted" }
+
+// sliceError is an error whose dynamic type is not comparable.
+type sliceError []string
+
+func (e sliceError) Error() string { return strings.Join(e, ", ") }
+
+func TestUncomparableToolError(t *testing.T) {
+	ctx := context.Background()
+	for _, registry := range []*ErrorRegistry{nil, new(ErrorRegistry)} {
+		server := NewServer(testImpl, &ServerOptions{ErrorRegistry: registry})
+		AddTool(server, &Tool{Name: "slice"}, func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+			return nil, nil, sliceError{"a", "b"}
+		})
+		cs, _, _ := basicClientServerConnection(t, nil, server, nil)
+		res, err := cs.CallTool(ctx, &CallToolParams{Name: "slice"})
+		if err != nil {
+			t.Fatalf("registry %v: CallTool: %v", registry, err)
+		}
+		if !res.IsError || res.Content[0].(*TextContent).Text != "a, b" {
+			t.Errorf("registry %v: got %+v, want tool error %q", registry, res, "a, b")
+		}
+	}
+}
+
+func TestErrorRegistry(t *testing.T) {
+	ctx := context.Background()
+	var (
+		errBusy    = errors.New("busy")
+		errMissing = errors.New("missing")
+	)
+	var registry ErrorRegistry
+	registry.MapToolError(errBusy, true)
+	registry.MapCode(errMissing, CodeResourceNotFound)
+	MapErrorType(&registry, func(e *quotaError) error {
+		return &ToolError{Err: e, Details: map[string]any{"limit": e.limit}}
+	})
+	// This mapping would apply again to its own result.
+	var mappings atomic.Int32
+	MapErrorType(&registry, func(e *countedError) error {
+		mappings.Add(1)
+		return fmt.Errorf("mapped: %w", e)
+	})
+
+	var logBuf bytes.Buffer
+	server := NewServer(testImpl, &ServerOptions{
+		ErrorRegistry: &registry,
+		Logger:        slog.New(slog.NewTextHandler(&logBuf, nil)),
+	})
+	tools := map[string]error{
+		"busy":    fmt.Errorf("try later: %w", errBusy),
+		"missing": errMissing,
+		"quota":   &quotaError{limit: 3},
+		"plain":   errTestFailure,
+		"custom":  &ToolError{Content: []Content{&TextContent{Text: "custom"}}},
+	}
+	for name, err := range tools {
+		AddTool(server, &Tool{Name: name}, func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+			return nil, nil, err
+		})
+	}
+	server.AddTool(&Tool{Name: "raw", InputSchema: &jsonschema.Schema{Type: "object"}}, func(context.Context, *CallToolRequest) (*CallToolResult, error) {
+		return nil, &ToolError{Err: errTestFailure}
+	})
+	server.AddTool(&Tool{Name: "counted", InputSchema: &jsonschema.Schema{Type: "object"}}, func(context.Context, *CallToolRequest) (*CallToolResult, error) {
+		return nil, &countedError{}
+	})
+	server.AddTool(&Tool{Name: "panic", InputSchema: &jsonschema.Schema{Type: "object"}}, func(context.Context, *CallToolRequest) (*CallToolResult, error) {
+		panic("oops")
+	})
+	server.AddPrompt(&Prompt{Name: "missing"}, func(context.Context, *GetPromptRequest) (*GetPromptResult, error) {
+		return nil, errMissing
+	})
+	cs, _, _ := basicClientServerConnection(t, nil, server, nil)
+
+	call := func(name string) (*CallToolResult, error) {
+		return cs.CallTool(ctx, &CallToolParams{Name: name})
+	}
+	wantCode := func(err error, code int64) {
+		t.Helper()
+		var werr *jsonrpc.Error
+		if !errors.As(err, &werr) || werr.Code != code {
+			t.Errorf("got error %v, want code %d", err, code)
+		}
+	}
+	wantToolError := func(name, text string, retryable bool) *CallToolResult {
+		t.Helper()
+		res, err := call(name)
+		if err != nil {
+			t.Fatalf("calling %s: %v", name, err)
+		}
+		if !res.IsError || res.Content[0].(*TextContent).Text != text {
+			t.Errorf("calling %s: got %+v, want tool error %q", name, res, text)
+		}
+		if got := res.Meta[retryableMetaKey] == true; got != retryable {
+			t.Errorf("calling %s: got retryable %t, want %t", name, got, retryable)
+		}
+		return res
+	}
+
+	wantToolError("busy", "try later: busy", true)
+	wantToolError("plain", errTestFailure.Error(), false)
+	wantToolError("custom", "custom", false)
+	wantToolError("raw", errTestFailure.Error(), false)
+	res := wantToolError("quota", "quota of 3 exceeded", false)
+	if diff := cmp.Diff(map[string]any{"limit": 3.0}, res.StructuredContent); diff != "" {
+		t.Errorf("quota details mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err := call("missing")
+	wantCode(err, CodeResourceNotFound)
+	_, err = cs.GetPrompt(ctx, &GetPromptParams{Name: "missing"})
+	wantCode(err, CodeResourceNotFound)
+
+	// Errors are mapped only once.
+	_, err = call("counted")
+	var werr *jsonrpc.Error
+	if !errors.As(err, &werr) || werr.Message != "mapped: counted" {
+		t.Errorf("calling counted: got error %v, want %q", err, "mapped: counted")
+	}
+	if got := mappings.Load(); got != 1 {
+		t.Errorf("counted error mapped %d times, want 1", got)
+	}
+
+	// A panic fails only its request.
+	_, err = call("panic")
+	wantCode(err, jsonrpc.CodeInternalError)
+	if !strings.Contains(logBuf.String(), "oops") || !strings.Contains(logBuf.String(), "stack=") {
+		t.Errorf("panic not logged with its stack trace: %s", logBuf.String())
+	}
+	if err := cs.Ping(ctx, nil); err != nil {
+		t.Errorf("ping after panic: %v", err)
+	}
+}
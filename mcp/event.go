@@ -60,16 +60,31 @@ func writeEvent(w io.Writer, evt Event) (int, error) {
 	return n, err
 }
 
-// scanEvents iterates SSE events in the given scanner. The iterated error is
-// terminal: if encountered, the stream is corrupt or broken and should no
+// scanEvents iterates SSE events in the given reader, with the default limit
+// on event size (see [scanEventsLimit]).
+func scanEvents(r io.Reader) iter.Seq2[Event, error] {
+	return scanEventsLimit(r, DefaultMaxMessageSize)
+}
+
+// scanEventsLimit iterates SSE events in the given reader.
+//
+// If maxSize is positive, events whose data exceeds maxSize bytes are skipped,
+// and reported with an [oversizedError] holding the start of their data.
+// The accompanying event has the other fields of the oversized event, but no
+// data. Iteration may continue after such an error. Any other iterated error
+// is terminal: if encountered, the stream is corrupt or broken and should no
 // longer be used.
 //
 // TODO(rfindley): consider a different API here that makes failure modes more
 // apparent.
-func scanEvents(r io.Reader) iter.Seq2[Event, error] {
-	scanner := bufio.NewScanner(r)
-	const maxTokenSize = 1 * 1024 * 1024 // 1 MiB max line size
-	scanner.Buffer(nil, maxTokenSize)
+func scanEventsLimit(r io.Reader, maxSize int64) iter.Seq2[Event, error] {
+	br := bufio.NewReader(r)
+	// Field names and spacing don't count toward the size of an event, so
+	// allow some slack for them in line lengths.
+	maxLine := maxSize
+	if maxLine > 0 {
+		maxLine += 64
+	}
 
 	// TODO: investigate proper behavior when events are out of order, or have
 	// non-standard names.
@@ -91,8 +106,9 @@ func scanEvents(r io.Reader) iter.Seq2[Event, error] {
 		//  - Lines starting with ":" are ignored.
 		//  - Records are terminated with two consecutive newlines.
 		var (
-			evt     Event
-			dataBuf *bytes.Buffer // if non-nil, preceding field was also data
+			evt       Event
+			dataBuf   *bytes.Buffer // if non-nil, preceding field was also data
+			oversized []byte        // if non-nil, the start of the data of an oversized event
 		)
 		flushData := func() {
 			if dataBuf != nil {
@@ -100,15 +116,37 @@ func scanEvents(r io.Reader) iter.Seq2[Event, error] {
 				dataBuf = nil
 			}
 		}
-		for scanner.Scan() {
-			line := scanner.Bytes()
-			if len(line) == 0 {
-				flushData()
+		// flushEvent yields the current event, if any, and reports whether to
+		// continue.
+		flushEvent := func() bool {
+			flushData()
+			if oversized != nil {
+				e := evt
+				e.Data = nil
+				err := &oversizedError{limit: maxSize, prefix: oversized}
+				evt, oversized = Event{}, nil
+				return yield(e, err)
+			}
+			if !evt.Empty() && !yield(evt, nil) {
+				return false
+			}
+			evt = Event{}
+			return true
+		}
+		for {
+			line, truncated, err := readLimitedLine(br, maxLine)
+			if err != nil && err != io.EOF {
+				yield(Event{}, err)
+				return
+			}
+			if len(line) == 0 && !truncated {
+				if err == io.EOF {
+					break
+				}
 				// \n\n is the record delimiter
-				if !evt.Empty() && !yield(evt, nil) {
+				if !flushEvent() {
 					return
 				}
-				evt = Event{}
 				continue
 			}
 			before, after, found := bytes.Cut(line, []byte{':'})
@@ -127,6 +165,9 @@ func scanEvents(r io.Reader) iter.Seq2[Event, error] {
 			case bytes.Equal(before, retryKey):
 				evt.Retry = strings.TrimSpace(string(after))
 			case bytes.Equal(before, dataKey):
+				if oversized != nil {
+					break // skip the rest of the data
+				}
 				data := bytes.TrimSpace(after)
 				if dataBuf != nil {
 					dataBuf.WriteByte('\n')
@@ -135,20 +176,43 @@ func scanEvents(r io.Reader) iter.Seq2[Event, error] {
 					dataBuf = new(bytes.Buffer)
 					dataBuf.Write(data)
 				}
+				if maxSize > 0 && (truncated || int64(dataBuf.Len()) > maxSize) {
+					oversized = dataBuf.Bytes()
+					dataBuf = nil
+				}
 			}
-		}
-		if err := scanner.Err(); err != nil {
-			if errors.Is(err, bufio.ErrTooLong) {
-				err = fmt.Errorf("event exceeded max line length of %d", maxTokenSize)
+			if err == io.EOF {
+				break
 			}
-			if !yield(Event{}, err) {
-				return
+		}
+		flushEvent()
+	}
+}
+
+// readLimitedLine reads a line from r, without its line ending. If max is
+// positive and the line is longer than max bytes, only its first max bytes are
+// returned, the rest is discarded, and truncated is set. At the end of the
+// input, the last line is returned with io.EOF.
+func readLimitedLine(r *bufio.Reader, max int64) (line []byte, truncated bool, err error) {
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if max <= 0 || int64(len(line)) < max {
+			if max > 0 && int64(len(line)+len(chunk)) > max {
+				chunk = chunk[:max-int64(len(line))]
+				truncated = true
 			}
+			line = append(line, chunk...)
+		} else {
+			truncated = true
+		}
+		if err == bufio.ErrBufferFull {
+			continue
 		}
-		flushData()
-		if !evt.Empty() {
-			yield(evt, nil)
+		if err == nil {
+			line = bytes.TrimSuffix(line, []byte("\n"))
+			line = bytes.TrimSuffix(line, []byte("\r"))
 		}
+		return line, truncated, err
 	}
 }
 
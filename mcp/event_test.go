@@ -6,6 +6,7 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
@@ -102,6 +103,35 @@ func TestScanEvents(t *testing.T) {
 	}
 }
 
+func TestScanEventsLimit(t *testing.T) {
+	long := strings.Repeat("x", 5000) // longer than a bufio buffer
+	input := "id: 1\ndata: short\n\n" +
+		"id: 2\ndata: " + long + "\n\n" +
+		"id: 3\ndata: 0123456789\ndata: 0123456789\n\n" +
+		"id: 4\ndata: last\n\n"
+	var ids, data []string
+	var errs int
+	for evt, err := range scanEventsLimit(strings.NewReader(input), 16) {
+		if err != nil {
+			if !errors.Is(err, ErrMessageTooLarge) {
+				t.Fatalf("scanEventsLimit() returned unexpected error: %v", err)
+			}
+			errs++
+		}
+		ids = append(ids, evt.ID)
+		data = append(data, string(evt.Data))
+	}
+	if want := []string{"1", "2", "3", "4"}; !slices.Equal(ids, want) {
+		t.Errorf("event IDs = %v, want %v", ids, want)
+	}
+	if want := []string{"short", "", "", "last"}; !slices.Equal(data, want) {
+		t.Errorf("event data = %q, want %q", data, want)
+	}
+	if errs != 2 {
+		t.Errorf("got %d oversized errors, want 2", errs)
+	}
+}
+
 func TestMemoryEventStoreState(t *testing.T) {
 	ctx := context.Background()
 
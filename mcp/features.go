@@ -8,6 +8,7 @@ import (
 	"iter"
 	"maps"
 	"slices"
+	"strings"
 )
 
 // This file contains implementations that are common to all features.
@@ -26,6 +27,15 @@ type featureSet[T any] struct {
 	sortedKeys []string // lazily computed; nil after add or remove
 }
 
+// A featureList is a read-only view of features ordered by unique ID: a
+// featureSet, or an overlay of one set on another (see [sessionSet]).
+type featureList[T any] interface {
+	all() iter.Seq[T]
+	above(uid string) iter.Seq[T]
+	get(uid string) (T, bool)
+	id(T) string
+}
+
 // newFeatureSet creates a new featureSet for features of type T.
 // The argument function should return the unique ID for a single feature.
 func newFeatureSet[T any](uniqueIDFunc func(T) string) *featureSet[T] {
@@ -68,6 +78,9 @@ func (s *featureSet[T]) get(uid string) (T, bool) {
 	return t, ok
 }
 
+// id returns the unique ID of f.
+func (s *featureSet[T]) id(f T) string { return s.uniqueID(f) }
+
 // len returns the number of features in the set.
 func (s *featureSet[T]) len() int { return len(s.features) }
 
@@ -112,3 +125,47 @@ func (s *featureSet[T]) yieldFrom(index int, yield func(T) bool) {
 		}
 	}
 }
+
+// A featureSlice is a featureList held in a slice sorted by unique ID.
+type featureSlice[T any] struct {
+	features []T
+	uniqueID func(T) string
+}
+
+// snapshot returns the features of fs for which visible returns true, as a
+// list that remains valid after the server's lock is released.
+func snapshot[T any](fs featureList[T], visible func(T) bool) featureSlice[T] {
+	l := featureSlice[T]{uniqueID: fs.id}
+	for f := range fs.all() {
+		if visible(f) {
+			l.features = append(l.features, f)
+		}
+	}
+	return l
+}
+
+func (l featureSlice[T]) all() iter.Seq[T] { return slices.Values(l.features) }
+
+func (l featureSlice[T]) above(uid string) iter.Seq[T] {
+	i, found := l.search(uid)
+	if found {
+		i++
+	}
+	return slices.Values(l.features[i:])
+}
+
+func (l featureSlice[T]) get(uid string) (T, bool) {
+	if i, found := l.search(uid); found {
+		return l.features[i], true
+	}
+	var zero T
+	return zero, false
+}
+
+func (l featureSlice[T]) id(f T) string { return l.uniqueID(f) }
+
+func (l featureSlice[T]) search(uid string) (int, bool) {
+	return slices.BinarySearchFunc(l.features, uid, func(f T, uid string) int {
+		return strings.Compare(l.uniqueID(f), uid)
+	})
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/internal/jsonrpc2"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+const (
+	// DefaultMaxMessageSize is the default maximum size of an incoming
+	// message on HTTP transports, in bytes. It is also the default maximum
+	// size of an HTTP request body for server transports. Transports over
+	// byte streams, such as [IOTransport], have no limit by default.
+	DefaultMaxMessageSize = 16 << 20
+
+	// DefaultMaxBatchSize is the default maximum number of messages in an
+	// incoming JSON-RPC batch on HTTP transports.
+	DefaultMaxBatchSize = 100
+)
+
+// ErrMessageTooLarge is the error for a message that exceeds the configured
+// maximum size. A call whose response exceeds the client's maximum message
+// size fails with an error wrapping ErrMessageTooLarge.
+var ErrMessageTooLarge = errors.New("message too large")
+
+// resolveLimit returns the limit to use for a configured value n: def if n is
+// zero, or no limit (represented as 0) if n is negative.
+func resolveLimit[T int | int64](n, def T) T {
+	switch {
+	case n == 0:
+		return def
+	case n < 0:
+		return 0
+	}
+	return n
+}
+
+// An oversizedError reports a message that exceeds the size limit.
+type oversizedError struct {
+	limit  int64
+	prefix []byte // the start of the message, for recovering its ID
+}
+
+func (e *oversizedError) Error() string {
+	return fmt.Sprintf("%v: exceeds maximum size of %d bytes", ErrMessageTooLarge, e.limit)
+}
+
+func (e *oversizedError) Unwrap() error { return ErrMessageTooLarge }
+
+// peekMessage reports what it can about the JSON-RPC message starting with
+// prefix, which may be truncated: its ID, and whether it is a response.
+// The ID is invalid if it can't be determined.
+func peekMessage(prefix []byte) (id jsonrpc.ID, isResponse bool) {
+	dec := json.NewDecoder(bytes.NewReader(prefix))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return id, false
+	}
+	for {
+		key, err := dec.Token()
+		if err != nil {
+			return id, isResponse
+		}
+		switch key {
+		case "id":
+			var v any
+			if err := dec.Decode(&v); err != nil {
+				return id, isResponse
+			}
+			id, _ = jsonrpc.MakeID(v)
+		case "result", "error":
+			isResponse = true
+			if id.IsValid() {
+				return id, true
+			}
+			fallthrough
+		default:
+			// Skip the value. If it is truncated, we're done.
+			var v json.RawMessage
+			if err := dec.Decode(&v); err != nil {
+				return id, isResponse
+			}
+		}
+	}
+}
+
+// oversizedResponse returns the response to report for the oversized message
+// described by err. If local is set, the message was a response to a local
+// call, and the returned response should be delivered locally. Otherwise, the
+// returned response, if non-nil, should be sent to the peer: it answers an
+// oversized request with the request's ID. Other oversized messages, such as
+// notifications and requests whose ID follows the truncated prefix, can't be
+// answered, and the response is nil.
+func oversizedResponse(err *oversizedError) (resp *jsonrpc.Response, local bool) {
+	id, isResponse := peekMessage(err.prefix)
+	if !id.IsValid() {
+		return nil, false
+	}
+	if isResponse {
+		return &jsonrpc.Response{ID: id, Error: err}, true
+	}
+	return &jsonrpc.Response{ID: id, Error: jsonrpc2.NewError(jsonrpc.CodeInvalidRequest, err.Error())}, false
+}
+
+// errReadLimit is returned by a limitedReader that has reached its limit.
+var errReadLimit = errors.New("read limit exceeded")
+
+// A limitedReader is a reader that fails with errReadLimit once it has read
+// max bytes, if max is positive.
+type limitedReader struct {
+	r   io.Reader
+	n   int64 // bytes read
+	max int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.max > 0 {
+		if r.n >= r.max {
+			return 0, errReadLimit
+		}
+		if rem := r.max - r.n; int64(len(p)) > rem {
+			p = p[:rem]
+		}
+	}
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// limitBody limits the size of the body of req to the given number of bytes,
+// if positive.
+func limitBody(w http.ResponseWriter, req *http.Request, limit int64) {
+	if limit > 0 && req.Body != nil {
+		req.Body = http.MaxBytesReader(w, req.Body, limit)
+	}
+}
+
+// bodyTooLarge reports whether err is the result of reading a body limited
+// by [limitBody] past its limit. If so, it replies to the request with 413
+// Content Too Large.
+func bodyTooLarge(w http.ResponseWriter, err error) bool {
+	var maxErr *http.MaxBytesError
+	if !errors.As(err, &maxErr) {
+		return false
+	}
+	http.Error(w, fmt.Sprintf("request body exceeds maximum size of %d bytes", maxErr.Limit), http.StatusRequestEntityTooLarge)
+	return true
+}
+
+// batchTooLarge returns an error if a batch of n messages exceeds the limit,
+// if positive.
+func batchTooLarge(n, limit int) error {
+	if limit > 0 && n > limit {
+		return fmt.Errorf("%w: batch of %d messages exceeds maximum of %d", ErrMessageTooLarge, n, limit)
+	}
+	return nil
+}
@@ -85,4 +85,31 @@
 //	}
 //	...
 //	mcp.AddTool(server, &mcp.Tool{Name: "double"}, double)
+//
+// # Origin and Host validation
+//
+// The HTTP handlers ([StreamableHTTPHandler] and [SSEHandler]) validate the
+// Origin and Host headers of requests, to protect against DNS rebinding
+// attacks, and support CORS for browser-based clients. Their options
+// configure this with three fields:
+//
+// AllowedOrigins lists the origins, such as "https://example.com", from which
+// requests are accepted. Requests from other origins are rejected with 403
+// Forbidden. The value "*" allows any origin. Listed origins may also make
+// cross-origin requests: the handler answers CORS preflight requests from
+// them, and exposes the ExposedHeaders to them. If AllowedOrigins is nil, a
+// handler listening on a loopback address only accepts requests from loopback
+// origins (such as http://localhost:3000), and other handlers accept requests
+// from any origin. Requests without an Origin header are always accepted.
+//
+// AllowedHosts lists the values of the Host header that are accepted, such as
+// "example.com" or "example.com:8080". A host without a port matches any
+// port. Requests for other hosts are rejected with 403 Forbidden. The value
+// "*" allows any host. If AllowedHosts is nil, a handler listening on a
+// loopback address only accepts requests for loopback hosts (such as
+// localhost:8080), and other handlers accept requests for any host.
+//
+// ExposedHeaders are the response headers exposed to cross-origin requests
+// from AllowedOrigins. If nil, the Mcp-Session-Id and Mcp-Protocol-Version
+// headers are exposed.
 package mcp
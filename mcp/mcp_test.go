@@ -639,6 +639,41 @@ func basicClientServerConnection(t *testing.T, client *Client, server *Server, c
 	}
 }
 
+// A toolsWatcher is a client connection that counts the tools/list_changed
+// notifications it receives.
+type toolsWatcher struct {
+	cs      *ClientSession
+	ss      *ServerSession
+	changed chan struct{} // receives a value for each notification
+	count   atomic.Int32  // number of notifications
+}
+
+// watchTools connects a client with the given name to server, as
+// [basicClientServerConnection] does, and returns a watcher for its
+// notifications.
+func watchTools(t *testing.T, server *Server, name string) *toolsWatcher {
+	t.Helper()
+	w := &toolsWatcher{changed: make(chan struct{}, 10)}
+	client := NewClient(&Implementation{Name: name, Version: "v1.0.0"}, &ClientOptions{
+		ToolListChangedHandler: func(context.Context, *ToolListChangedRequest) {
+			w.count.Add(1)
+			w.changed <- struct{}{}
+		},
+	})
+	w.cs, w.ss, _ = basicClientServerConnection(t, client, server, nil)
+	return w
+}
+
+// awaitChange waits for a tools/list_changed notification.
+func (w *toolsWatcher) awaitChange(t *testing.T) {
+	t.Helper()
+	select {
+	case <-w.changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for tools/list_changed")
+	}
+}
+
 func TestServerClosing(t *testing.T) {
 	cs, ss, cleanup := basicConnection(t, func(s *Server) {
 		AddTool(s, greetTool(), sayHi)
@@ -2114,7 +2149,7 @@ func TestToolErrorMiddleware(t *testing.T) {
 			res, err := h(ctx, method, req)
 			if err == nil {
 				if ctr, ok := res.(*CallToolResult); ok {
-					middleErr = ctr.getError()
+					middleErr = ctr.GetError()
 				}
 			}
 			return res, err
@@ -2151,7 +2186,7 @@ func TestToolErrorMiddleware(t *testing.T) {
 		t.Fatal("want error, got none")
 	}
 	// Clients can't see the error, because it isn't marshaled.
-	if err := res.getError(); err != nil {
+	if err := res.GetError(); err != nil {
 		t.Fatalf("got %v, want nil", err)
 	}
 	if middleErr != errTestFailure {
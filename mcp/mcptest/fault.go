@@ -0,0 +1,512 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcptest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Faults configures the faults injected into one direction of a
+// [FaultTransport] connection. Each probability is in the range [0, 1], and
+// is applied independently to each message.
+type Faults struct {
+	// Drop is the probability that a message is silently dropped.
+	Drop float64
+	// Delay is the probability that a message is delayed, by a random
+	// duration up to MaxDelay.
+	Delay    float64
+	MaxDelay time.Duration
+	// Duplicate is the probability that a message is delivered twice.
+	Duplicate float64
+	// Reorder is the probability that a message is held back, and delivered
+	// after the next message. A held message is delivered anyway after
+	// MaxDelay (or 10ms, if MaxDelay is zero) if no other message follows.
+	Reorder float64
+	// Corrupt is the probability that a message is corrupted, by replacing its
+	// params or result with a JSON value of the wrong type. Corrupted messages
+	// are well-formed JSON-RPC, so they exercise the peer's handling of
+	// invalid messages rather than breaking the connection.
+	Corrupt float64
+}
+
+// A FaultTransport is an [mcp.Transport] that delegates to another transport,
+// injecting faults into its connection for resilience testing. Faults are
+// chosen using a pseudo-random generator with the given seed, so that a
+// failing test can be reproduced, up to the scheduling of goroutines.
+//
+// Since a FaultTransport connection hides the underlying connection from the
+// session, use it with in-memory, command, or stdio transports. To inject
+// faults into the streamable HTTP transport, use a [FaultRoundTripper].
+type FaultTransport struct {
+	Transport mcp.Transport
+	Seed      uint64
+	// Read and Write configure the faults for incoming and outgoing messages.
+	Read, Write Faults
+	// If CloseAfter is positive, the connection is closed after that many
+	// messages (in either direction) have been delivered.
+	CloseAfter int
+}
+
+// Connect connects the underlying transport, and returns a connection that
+// injects faults.
+func (t *FaultTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	delegate, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := &faultConn{
+		delegate: delegate,
+		t:        t,
+		rand:     rand.New(rand.NewPCG(t.Seed, 0)),
+		incoming: make(chan faultMsg),
+		closed:   make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+type faultConn struct {
+	delegate mcp.Connection
+	t        *FaultTransport
+
+	incoming  chan faultMsg // shaped incoming messages
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	writeMu    sync.Mutex // serializes writes, for reordering
+	heldWrites []jsonrpc.Message
+	heldTimer  *time.Timer
+
+	mu        sync.Mutex
+	rand      *rand.Rand
+	delivered int
+}
+
+type faultMsg struct {
+	msg jsonrpc.Message
+	err error
+}
+
+func (c *faultConn) SessionID() string { return c.delegate.SessionID() }
+
+// chance reports whether an event with probability p occurs.
+func (c *faultConn) chance(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rand.Float64() < p
+}
+
+// delay returns a random delay up to f.MaxDelay, if one should be applied.
+func (c *faultConn) delay(f *Faults) time.Duration {
+	if !c.chance(f.Delay) || f.MaxDelay <= 0 {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Duration(c.rand.Int64N(int64(f.MaxDelay))) + 1
+}
+
+// count records a delivered message, and reports whether the connection
+// should be closed after it.
+func (c *faultConn) count() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.delivered++
+	return c.t.CloseAfter > 0 && c.delivered >= c.t.CloseAfter
+}
+
+// shape applies the message faults of f to msg, returning the messages to
+// deliver, and whether to hold them back.
+func (c *faultConn) shape(f *Faults, msg jsonrpc.Message) (msgs []jsonrpc.Message, reorder bool) {
+	if c.chance(f.Drop) {
+		return nil, false
+	}
+	if c.chance(f.Corrupt) {
+		msg = corrupt(msg)
+	}
+	msgs = []jsonrpc.Message{msg}
+	if c.chance(f.Duplicate) {
+		msgs = append(msgs, msg)
+	}
+	return msgs, c.chance(f.Reorder)
+}
+
+// readLoop reads from the delegate, applying faults, and delivers the
+// results to c.incoming.
+func (c *faultConn) readLoop() {
+	raw := make(chan faultMsg)
+	go func() {
+		for {
+			msg, err := c.delegate.Read(context.Background())
+			select {
+			case raw <- faultMsg{msg, err}:
+			case <-c.closed:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	deliver := func(m faultMsg) bool {
+		select {
+		case c.incoming <- m:
+			if m.err == nil && c.count() {
+				c.Close()
+			}
+			return true
+		case <-c.closed:
+			return false
+		}
+	}
+	f := &c.t.Read
+	var held []jsonrpc.Message
+	var heldTimer <-chan time.Time
+	for {
+		var m faultMsg
+		select {
+		case m = <-raw:
+		case <-heldTimer:
+			for _, h := range held {
+				if !deliver(faultMsg{msg: h}) {
+					return
+				}
+			}
+			held, heldTimer = nil, nil
+			continue
+		case <-c.closed:
+			return
+		}
+		if m.err != nil {
+			deliver(m)
+			return
+		}
+		if d := c.delay(f); d > 0 {
+			time.Sleep(d)
+		}
+		msgs, reorder := c.shape(f, m.msg)
+		if reorder && held == nil {
+			held, heldTimer = msgs, time.After(holdTime(f))
+			continue
+		}
+		for _, msg := range append(msgs, held...) {
+			if !deliver(faultMsg{msg: msg}) {
+				return
+			}
+		}
+		held, heldTimer = nil, nil
+	}
+}
+
+func (c *faultConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case m := <-c.incoming:
+		return m.msg, m.err
+	case <-c.closed:
+		return nil, io.EOF
+	}
+}
+
+func (c *faultConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	select {
+	case <-c.closed:
+		return mcp.ErrConnectionClosed
+	default:
+	}
+	f := &c.t.Write
+	if d := c.delay(f); d > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+	msgs, reorder := c.shape(f, msg)
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if reorder && c.holdWrites(msgs) {
+		return nil
+	}
+	held := c.releaseWrites()
+	for _, msg := range append(msgs, held...) {
+		if err := c.deliverWrite(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliverWrite writes msg to the delegate. It must be called with
+// c.writeMu held.
+func (c *faultConn) deliverWrite(ctx context.Context, msg jsonrpc.Message) error {
+	select {
+	case <-c.closed:
+		return mcp.ErrConnectionClosed
+	default:
+	}
+	if err := c.delegate.Write(ctx, msg); err != nil {
+		return err
+	}
+	if c.count() {
+		c.Close()
+	}
+	return nil
+}
+
+func (c *faultConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		err = c.delegate.Close()
+	})
+	return err
+}
+
+// holdWrites holds msgs for reordering, if no messages are already held,
+// and reports whether it did so. Held messages are written by the next
+// call to Write, or after a timeout. It must be called with c.writeMu held.
+func (c *faultConn) holdWrites(msgs []jsonrpc.Message) bool {
+	if c.heldWrites != nil {
+		return false
+	}
+	c.heldWrites = msgs
+	c.heldTimer = time.AfterFunc(holdTime(&c.t.Write), func() {
+		c.writeMu.Lock()
+		defer c.writeMu.Unlock()
+		for _, msg := range c.releaseWrites() {
+			c.deliverWrite(context.Background(), msg)
+		}
+	})
+	return true
+}
+
+// releaseWrites returns and clears the held messages. It must be called with
+// c.writeMu held.
+func (c *faultConn) releaseWrites() []jsonrpc.Message {
+	if c.heldTimer != nil {
+		c.heldTimer.Stop()
+		c.heldTimer = nil
+	}
+	msgs := c.heldWrites
+	c.heldWrites = nil
+	return msgs
+}
+
+func holdTime(f *Faults) time.Duration {
+	if f.MaxDelay > 0 {
+		return f.MaxDelay
+	}
+	return 10 * time.Millisecond
+}
+
+// corruptValue replaces the params or result of a corrupted message.
+var corruptValue = json.RawMessage(`"\u0000corrupt"`)
+
+// corrupt returns a copy of msg with its params or result replaced by a
+// value of the wrong type.
+func corrupt(msg jsonrpc.Message) jsonrpc.Message {
+	switch msg := msg.(type) {
+	case *jsonrpc.Request:
+		m := *msg
+		m.Params = corruptValue
+		return &m
+	case *jsonrpc.Response:
+		m := *msg
+		if m.Error == nil {
+			m.Result = corruptValue
+		}
+		return &m
+	}
+	return msg
+}
+
+// A FaultRoundTripper is an [http.RoundTripper] that delegates to another
+// round tripper, injecting faults into HTTP exchanges. It can be used as the
+// transport of the HTTP client of an [mcp.StreamableClientTransport], to
+// exercise its retry and stream resumption logic.
+//
+// Faults are chosen using a pseudo-random generator with the given seed.
+// Each probability is in the range [0, 1], and is applied independently to
+// each request.
+type FaultRoundTripper struct {
+	// Transport is the underlying round tripper. If nil,
+	// [http.DefaultTransport] is used.
+	Transport http.RoundTripper
+	Seed      uint64
+
+	// ServerError is the probability that a request is answered with a
+	// server error, without being sent. The status is chosen from
+	// StatusCodes, or is 503 if StatusCodes is empty.
+	ServerError float64
+	StatusCodes []int
+	// Timeout is the probability that a request fails with a timeout error,
+	// without being sent.
+	Timeout float64
+	// Disconnect is the probability that a server-sent event stream in a
+	// response is cut short, after a random number of events (at least one)
+	// carrying data, up to MaxEvents. If MaxEvents is zero, it is 1.
+	Disconnect float64
+	MaxEvents  int
+
+	once sync.Once
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// An injectedTimeout is the error returned for an injected timeout.
+type injectedTimeout struct{}
+
+func (injectedTimeout) Error() string   { return "mcptest: injected timeout" }
+func (injectedTimeout) Timeout() bool   { return true }
+func (injectedTimeout) Temporary() bool { return true }
+
+func (rt *FaultRoundTripper) chance(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.rand.Float64() < p
+}
+
+func (rt *FaultRoundTripper) intN(n int) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.rand.IntN(n)
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (rt *FaultRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.once.Do(func() { rt.rand = rand.New(rand.NewPCG(rt.Seed, 0)) })
+	if rt.chance(rt.Timeout) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, injectedTimeout{}
+	}
+	if rt.chance(rt.ServerError) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		code := http.StatusServiceUnavailable
+		if len(rt.StatusCodes) > 0 {
+			code = rt.StatusCodes[rt.intN(len(rt.StatusCodes))]
+		}
+		return &http.Response{
+			Status:     fmt.Sprintf("%d %s", code, http.StatusText(code)),
+			StatusCode: code,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": {"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader("mcptest: injected server error")),
+			Request:    req,
+		}, nil
+	}
+	transport := rt.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") && rt.chance(rt.Disconnect) {
+		max := max(rt.MaxEvents, 1)
+		resp.Body = &cutBody{body: resp.Body, events: 1 + rt.intN(max)}
+	}
+	return resp, nil
+}
+
+// A cutBody is a server-sent event stream that fails after a number of
+// events carrying data.
+//
+// As in the SSE spec, lines may end in "\r\n", "\n" or "\r".
+type cutBody struct {
+	body   io.ReadCloser
+	events int // remaining events before the cut
+
+	lineStart []byte // start of the current line, up to len("data:")
+	hasData   bool   // whether the current event has data
+	afterCR   bool   // whether the last line ended in '\r', which may be followed by '\n'
+	cutNext   bool   // whether to cut after a '\n' completing the last line, or else before the next byte
+	cut       bool
+}
+
+func (b *cutBody) Read(p []byte) (int, error) {
+	if b.cut {
+		return 0, io.ErrUnexpectedEOF
+	}
+	n, err := b.body.Read(p)
+	for i := 0; i < n; i++ {
+		c := p[i]
+		if b.afterCR {
+			b.afterCR = false
+			if c == '\n' { // the second half of "\r\n"
+				if b.cutNext {
+					return b.cutAt(i + 1)
+				}
+				continue
+			}
+			if b.cutNext {
+				return b.cutAt(i)
+			}
+		}
+		if c != '\n' && c != '\r' {
+			if len(b.lineStart) < len("data:") {
+				b.lineStart = append(b.lineStart, c)
+			}
+			continue
+		}
+		b.afterCR = c == '\r'
+		if len(b.lineStart) == 0 { // blank line: end of event
+			if b.hasData {
+				b.events--
+				if b.events == 0 {
+					if b.afterCR {
+						b.cutNext = true
+						continue
+					}
+					return b.cutAt(i + 1)
+				}
+			}
+			b.hasData = false
+		} else if string(b.lineStart) == "data:" {
+			b.hasData = true
+		}
+		b.lineStart = b.lineStart[:0]
+	}
+	return n, err
+}
+
+// cutAt cuts the stream, returning the first n bytes that were read.
+func (b *cutBody) cutAt(n int) (int, error) {
+	b.cut = true
+	b.body.Close()
+	if n == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return n, nil
+}
+
+func (b *cutBody) Close() error {
+	return b.body.Close()
+}
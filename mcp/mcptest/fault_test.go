@@ -0,0 +1,168 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcptest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/modelcontextprotocol/go-sdk/mcp/mcptest"
+)
+
+func TestFaultTransport(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "testServer", Version: "v1.0.0"}, nil)
+	client := mcp.NewClient(&mcp.Implementation{Name: "testClient", Version: "v1.0.0"}, nil)
+
+	t.Run("delay and reorder", func(t *testing.T) {
+		ct, st := mcp.NewInMemoryTransports()
+		ss, err := server.Connect(ctx, st, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ss.Close()
+		faults := mcptest.Faults{Delay: 0.5, MaxDelay: time.Millisecond, Reorder: 0.5}
+		cs, err := client.Connect(ctx, &mcptest.FaultTransport{Transport: ct, Seed: 1, Read: faults, Write: faults}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cs.Close()
+		for range 10 {
+			if err := cs.Ping(ctx, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	t.Run("close after", func(t *testing.T) {
+		ct, st := mcp.NewInMemoryTransports()
+		ss, err := server.Connect(ctx, st, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ss.Close()
+		// Initialization takes three messages, so the connection closes
+		// immediately after.
+		cs, err := client.Connect(ctx, &mcptest.FaultTransport{Transport: ct, CloseAfter: 3}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs.Wait()
+		if err := cs.Ping(ctx, nil); err == nil {
+			t.Error("Ping succeeded after connection was closed")
+		}
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		ct, st := mcp.NewInMemoryTransports()
+		ss, err := server.Connect(ctx, &mcptest.FaultTransport{Transport: st, Read: mcptest.Faults{Corrupt: 1}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ss.Close()
+		// The server can't make sense of the initialize request.
+		if _, err := client.Connect(ctx, ct, nil); err == nil {
+			t.Error("Connect succeeded with corrupted messages")
+		}
+	})
+}
+
+func TestFaultRoundTripper(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "testServer", Version: "v1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "work"}, func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		for i := range 3 {
+			if err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{ProgressToken: req.Params.GetProgressToken(), Progress: float64(i)}); err != nil {
+				return nil, nil, err
+			}
+		}
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	httpServer := mcptest.NewStreamableServer(t, server, &mcp.StreamableHTTPOptions{EventStore: mcp.NewMemoryEventStore(nil)})
+
+	var progress atomic.Int32
+	client := mcp.NewClient(&mcp.Implementation{Name: "testClient", Version: "v1.0.0"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(context.Context, *mcp.ProgressNotificationClientRequest) {
+			progress.Add(1)
+		},
+	})
+	// Every event stream is cut after its first event, and many requests
+	// fail, so the client must retry requests and resume streams.
+	rt := &mcptest.FaultRoundTripper{
+		Transport:   httpServer.Client().Transport,
+		Seed:        1,
+		ServerError: 0.3,
+		Disconnect:  1,
+	}
+	cs, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:    httpServer.URL,
+		HTTPClient:  &http.Client{Transport: rt},
+		RetryPolicy: &mcp.RetryPolicy{InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, MaxAttempts: 20},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	params := &mcp.CallToolParams{Name: "work"}
+	params.SetProgressToken("tok")
+	if _, err := cs.CallTool(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if got := progress.Load(); got != 3 {
+		t.Errorf("got %d progress notifications, want 3", got)
+	}
+}
+
+// A staticRoundTripper responds to every request with an event stream
+// containing body.
+type staticRoundTripper string
+
+func (body staticRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+		Request:    req,
+	}, nil
+}
+
+func TestFaultRoundTripperCutsEvents(t *testing.T) {
+	// Streams are cut after the first event carrying data, whichever line
+	// endings they use.
+	for _, eol := range []string{"\n", "\r\n", "\r"} {
+		stream := strings.ReplaceAll(": comment\n\nid: 1\ndata: one\n\ndata: two\n\n", "\n", eol)
+		want := strings.ReplaceAll(": comment\n\nid: 1\ndata: one\n\n", "\n", eol)
+		for _, oneByte := range []bool{false, true} {
+			rt := &mcptest.FaultRoundTripper{Transport: staticRoundTripper(stream), Disconnect: 1}
+			req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var r io.Reader = resp.Body
+			if oneByte {
+				r = iotest.OneByteReader(r)
+			}
+			got, err := io.ReadAll(r)
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("eol %q, oneByte=%t: got error %v, want io.ErrUnexpectedEOF", eol, oneByte, err)
+			}
+			if string(got) != want {
+				t.Errorf("eol %q, oneByte=%t: got %q, want %q", eol, oneByte, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/modelcontextprotocol/go-sdk/mcp/conformance"
+	"golang.org/x/tools/txtar"
+)
+
+// UpdateEnv is the environment variable that makes [Harness.CheckGolden]
+// update golden files rather than compare against them, when set to a
+// non-empty value.
+const UpdateEnv = "MCPTEST_UPDATE"
+
+// Traffic returns the JSON-RPC messages sent by the client and by the server
+// so far, in the order the client sent or received them.
+func (h *Harness) Traffic() (client, server []json.RawMessage) {
+	h.t.Helper()
+	client, server, err := h.trace.messages()
+	if err != nil {
+		h.t.Fatalf("mcptest: reading traffic: %v", err)
+	}
+	return client, server
+}
+
+// CheckGolden compares the JSON-RPC traffic between the client and server
+// with the golden file at path, reporting any differences with t.Error.
+//
+// The golden file is a txtar archive in the format used by the conformance
+// package, with "client" and "server" files holding the messages sent by each
+// side, so that it can also be used as a conformance test. If the
+// MCPTEST_UPDATE environment variable is set (see [UpdateEnv]), CheckGolden
+// writes the traffic to the golden file instead, preserving its comment:
+//
+//	MCPTEST_UPDATE=1 go test ./...
+//
+// CheckGolden should be called once the traffic is complete: for example,
+// after awaiting any expected notifications. Since the messages from each
+// side are compared in order, the traffic must be deterministic.
+func (h *Harness) CheckGolden(path string) {
+	h.t.Helper()
+	client, server := h.Traffic()
+	update := os.Getenv(UpdateEnv) != ""
+	test, err := conformance.Load(path)
+	if errors.Is(err, fs.ErrNotExist) && update {
+		test = &conformance.Test{Path: path, Archive: &txtar.Archive{}}
+	} else if err != nil {
+		h.t.Fatalf("mcptest: loading golden file: %v (set %s=1 to create it)", err, UpdateEnv)
+	}
+	if update {
+		if err := test.Update("client", client); err != nil {
+			h.t.Fatal(err)
+		}
+		if err := test.Update("server", server); err != nil {
+			h.t.Fatal(err)
+		}
+		return
+	}
+	if diff := conformance.Diff(test.Client, client); diff != "" {
+		h.t.Errorf("mcptest: mismatching client messages in %s (-want +got):\n%s", path, diff)
+	}
+	if diff := conformance.Diff(test.Server, server); diff != "" {
+		h.t.Errorf("mcptest: mismatching server messages in %s (-want +got):\n%s", path, diff)
+	}
+}
+
+// A trace holds the recording of the client's connection, as written by an
+// [mcp.RecordingTransport].
+type trace struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (tr *trace) Write(p []byte) (int, error) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return tr.buf.Write(p)
+}
+
+// messages returns the recorded messages sent and received by the client.
+func (tr *trace) messages() (sent, received []json.RawMessage, err error) {
+	tr.mu.Lock()
+	data := bytes.Clone(tr.buf.Bytes())
+	tr.mu.Unlock()
+	msgs, err := mcp.ReadRecording(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, m := range msgs {
+		if m.Direction == mcp.RecordSent {
+			sent = append(sent, m.Message)
+		} else {
+			received = append(received, m.Message)
+		}
+	}
+	return sent, received, nil
+}
@@ -0,0 +1,26 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcptest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// NewStreamableServer starts an [httptest.Server] that serves server over the
+// streamable HTTP transport at its root URL, configured by opts. The server is
+// closed when the test completes.
+//
+// Clients can connect to it using an [mcp.StreamableClientTransport] with the
+// server's URL as its Endpoint, and its Client as HTTPClient.
+func NewStreamableServer(t testing.TB, server *mcp.Server, opts *mcp.StreamableHTTPOptions) *httptest.Server {
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, opts)
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+	return httpServer
+}
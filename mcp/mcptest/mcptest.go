@@ -0,0 +1,292 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package mcptest provides utilities for testing MCP servers and clients.
+//
+// The central type is the [Harness], which connects a [mcp.Server] to a
+// client over in-memory transports in a single call. The harness records the
+// JSON-RPC traffic between the two (see [Harness.CheckGolden]) and the
+// notifications received by the client (see [Harness.AwaitNotifications]).
+// Requests from the server to the client, such as sampling and elicitation,
+// are answered by scripted responders (see [Script]).
+//
+// For testing clients, or servers served over HTTP, [NewStreamableServer]
+// serves a server over the streamable transport using [httptest].
+//
+// For resilience testing, [FaultTransport] and [FaultRoundTripper] inject
+// faults into connections and HTTP exchanges.
+package mcptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Options configures a [Harness].
+type Options struct {
+	// ClientOptions configures the client. Its handlers are used, except where
+	// they are replaced by the scripts below. The harness adds its own
+	// middleware to record notifications.
+	ClientOptions *mcp.ClientOptions
+
+	// If set, Sampling answers sampling/createMessage requests from the server.
+	Sampling *Sampler
+	// If set, Elicitation answers elicitation/create requests from the server.
+	Elicitation *Elicitor
+	// If set, Roots answers roots/list requests from the server, in place of
+	// the client's roots.
+	Roots *RootsLister
+
+	// WaitTimeout bounds how long [Harness.AwaitNotifications] waits.
+	// If zero, it is 10 seconds.
+	WaitTimeout time.Duration
+}
+
+// A Harness is a connected client and server, for use in tests.
+//
+// The client records all notifications it receives, and all JSON-RPC
+// messages it sends and receives.
+type Harness struct {
+	Client        *mcp.Client
+	ClientSession *mcp.ClientSession
+	ServerSession *mcp.ServerSession
+
+	t           testing.TB
+	waitTimeout time.Duration
+	trace       *trace
+
+	mu            sync.Mutex
+	notifications []Notification
+	notified      chan struct{} // closed and replaced when a notification arrives
+}
+
+// A Notification is a notification received by the client.
+type Notification struct {
+	Method string
+	Params mcp.Params
+}
+
+// New connects server to a new client over in-memory transports, and returns
+// a harness for the connection. The sessions are closed when the test
+// completes. New calls t.Fatal if the connection fails.
+func New(t testing.TB, server *mcp.Server, opts *Options) *Harness {
+	t.Helper()
+	if opts == nil {
+		opts = &Options{}
+	}
+	h := &Harness{
+		t:           t,
+		waitTimeout: opts.WaitTimeout,
+		trace:       &trace{},
+		notified:    make(chan struct{}),
+	}
+	if h.waitTimeout <= 0 {
+		h.waitTimeout = 10 * time.Second
+	}
+
+	var copts mcp.ClientOptions
+	if opts.ClientOptions != nil {
+		copts = *opts.ClientOptions
+	}
+	if opts.Sampling != nil {
+		copts.CreateMessageHandler = func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			return opts.Sampling.next(req.Params)
+		}
+	}
+	if opts.Elicitation != nil {
+		copts.ElicitationHandler = func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return opts.Elicitation.next(req.Params)
+		}
+	}
+	h.Client = mcp.NewClient(&mcp.Implementation{Name: "mcptest", Version: "v1.0.0"}, &copts)
+	h.Client.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "roots/list" && opts.Roots != nil {
+				params, _ := req.GetParams().(*mcp.ListRootsParams)
+				return opts.Roots.next(params)
+			}
+			if strings.HasPrefix(method, "notifications/") {
+				h.record(method, req.GetParams())
+			}
+			return next(ctx, method, req)
+		}
+	})
+
+	ctx := context.Background()
+	ct, st := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatalf("mcptest: server connection failed: %v", err)
+	}
+	cs, err := h.Client.Connect(ctx, &mcp.RecordingTransport{Transport: ct, Writer: h.trace}, nil)
+	if err != nil {
+		ss.Close()
+		t.Fatalf("mcptest: client connection failed: %v", err)
+	}
+	h.ClientSession = cs
+	h.ServerSession = ss
+	t.Cleanup(func() {
+		cs.Close()
+		ss.Wait()
+	})
+	return h
+}
+
+func (h *Harness) record(method string, params mcp.Params) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.notifications = append(h.notifications, Notification{method, params})
+	close(h.notified)
+	h.notified = make(chan struct{})
+}
+
+// Notifications returns the notifications with the given method received by
+// the client so far, in order. If method is "", it returns all notifications.
+func (h *Harness) Notifications(method string) []Notification {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.matching(method)
+}
+
+func (h *Harness) matching(method string) []Notification {
+	var ns []Notification
+	for _, n := range h.notifications {
+		if method == "" || n.Method == method {
+			ns = append(ns, n)
+		}
+	}
+	return ns
+}
+
+// AwaitNotifications waits until the client has received at least n
+// notifications with the given method, such as
+// "notifications/tools/list_changed", and returns all such notifications.
+// It calls t.Fatal if they do not arrive within the harness's wait timeout.
+func (h *Harness) AwaitNotifications(method string, n int) []Notification {
+	h.t.Helper()
+	timer := time.NewTimer(h.waitTimeout)
+	defer timer.Stop()
+	for {
+		h.mu.Lock()
+		ns, notified := h.matching(method), h.notified
+		h.mu.Unlock()
+		if len(ns) >= n {
+			return ns
+		}
+		select {
+		case <-notified:
+		case <-timer.C:
+			h.t.Fatalf("mcptest: timed out waiting for %d %q notifications (got %d)", n, method, len(ns))
+		}
+	}
+}
+
+// LogMessages returns the params of the notifications/message notifications
+// received by the client so far.
+func (h *Harness) LogMessages() []*mcp.LoggingMessageParams {
+	return paramsOf[*mcp.LoggingMessageParams](h.Notifications("notifications/message"))
+}
+
+// Progress returns the params of the notifications/progress notifications
+// received by the client so far.
+func (h *Harness) Progress() []*mcp.ProgressNotificationParams {
+	return paramsOf[*mcp.ProgressNotificationParams](h.Notifications("notifications/progress"))
+}
+
+func paramsOf[P mcp.Params](ns []Notification) []P {
+	var ps []P
+	for _, n := range ns {
+		if p, ok := n.Params.(P); ok {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// A Script is a scripted responder to requests from the server, such as
+// sampling requests. It responds to each request with the next queued result
+// or error, and records the request params.
+//
+// It is an error for the server to send more requests than there are queued
+// responses.
+type Script[P, R any] struct {
+	mu        sync.Mutex
+	responses []scriptResponse[R]
+	requests  []P
+}
+
+type scriptResponse[R any] struct {
+	result R
+	err    error
+}
+
+// A Sampler is a scripted responder to sampling requests.
+type Sampler = Script[*mcp.CreateMessageParams, *mcp.CreateMessageResult]
+
+// An Elicitor is a scripted responder to elicitation requests.
+type Elicitor = Script[*mcp.ElicitParams, *mcp.ElicitResult]
+
+// A RootsLister is a scripted responder to roots/list requests.
+type RootsLister = Script[*mcp.ListRootsParams, *mcp.ListRootsResult]
+
+// NewScript returns a new Script that responds with the given results, in
+// order.
+func NewScript[P, R any](results ...R) *Script[P, R] {
+	s := &Script[P, R]{}
+	s.Respond(results...)
+	return s
+}
+
+// Respond queues responses with the given results.
+func (s *Script[P, R]) Respond(results ...R) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range results {
+		s.responses = append(s.responses, scriptResponse[R]{result: r})
+	}
+}
+
+// Fail queues an error response.
+func (s *Script[P, R]) Fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = append(s.responses, scriptResponse[R]{err: err})
+}
+
+// Requests returns the params of the requests received so far.
+func (s *Script[P, R]) Requests() []P {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]P(nil), s.requests...)
+}
+
+// Remaining reports the number of queued responses that have not been sent.
+func (s *Script[P, R]) Remaining() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.responses)
+}
+
+// errScriptExhausted is returned when a script has no more responses.
+var errScriptExhausted = errors.New("mcptest: no scripted response")
+
+func (s *Script[P, R]) next(params P) (R, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, params)
+	if len(s.responses) == 0 {
+		var zero R
+		return zero, fmt.Errorf("%w for request %d", errScriptExhausted, len(s.requests))
+	}
+	r := s.responses[0]
+	s.responses = s.responses[1:]
+	return r.result, r.err
+}
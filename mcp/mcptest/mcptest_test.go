@@ -0,0 +1,131 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcptest_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/modelcontextprotocol/go-sdk/mcp/mcptest"
+)
+
+func TestHarness(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "testServer", Version: "v1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "ask"}, func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		ss := req.Session
+		if err := ss.Log(ctx, &mcp.LoggingMessageParams{Level: "info", Data: "asking"}); err != nil {
+			return nil, nil, err
+		}
+		if token := req.Params.GetProgressToken(); token != nil {
+			if err := ss.NotifyProgress(ctx, &mcp.ProgressNotificationParams{ProgressToken: token, Progress: 1, Total: 2}); err != nil {
+				return nil, nil, err
+			}
+		}
+		sampled, err := ss.CreateMessage(ctx, &mcp.CreateMessageParams{Messages: []*mcp.SamplingMessage{{Role: "user", Content: &mcp.TextContent{Text: "hi"}}}})
+		if err != nil {
+			return nil, nil, err
+		}
+		elicited, err := ss.Elicit(ctx, &mcp.ElicitParams{Message: "name?"})
+		if err != nil {
+			return nil, nil, err
+		}
+		roots, err := ss.ListRoots(ctx, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		text := sampled.Content.(*mcp.TextContent).Text + " " + string(elicited.Action) + " " + roots.Roots[0].URI
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
+	})
+	mcp.AddTool(server, &mcp.Tool{Name: "addTool"}, func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		mcp.AddTool(server, &mcp.Tool{Name: "added"}, func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{}, nil, nil
+		})
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	sampling := mcptest.NewScript[*mcp.CreateMessageParams](&mcp.CreateMessageResult{Model: "m", Role: "assistant", Content: &mcp.TextContent{Text: "hello"}})
+	elicitation := mcptest.NewScript[*mcp.ElicitParams](&mcp.ElicitResult{Action: "decline"})
+	roots := mcptest.NewScript[*mcp.ListRootsParams](&mcp.ListRootsResult{Roots: []*mcp.Root{{URI: "file:///scripted"}}})
+	h := mcptest.New(t, server, &mcptest.Options{
+		Sampling:    sampling,
+		Elicitation: elicitation,
+		Roots:       roots,
+	})
+	cs := h.ClientSession
+	if err := cs.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+
+	params := &mcp.CallToolParams{Name: "ask"}
+	params.SetProgressToken("tok")
+	res, err := cs.CallTool(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Content[0].(*mcp.TextContent).Text, "hello decline file:///scripted"; got != want {
+		t.Errorf("CallTool returned %q, want %q", got, want)
+	}
+	if got := sampling.Requests(); len(got) != 1 || sampling.Remaining() != 0 {
+		t.Errorf("sampling script got %d requests, with %d responses remaining; want 1, 0", len(got), sampling.Remaining())
+	}
+
+	h.AwaitNotifications("notifications/message", 1)
+	if diff := cmp.Diff([]*mcp.LoggingMessageParams{{Level: "info", Data: "asking"}}, h.LogMessages()); diff != "" {
+		t.Errorf("log messages mismatch (-want +got):\n%s", diff)
+	}
+	h.AwaitNotifications("notifications/progress", 1)
+	if got := h.Progress(); len(got) != 1 || got[0].Progress != 1 {
+		t.Errorf("got progress %v, want one notification with progress 1", got)
+	}
+	h.CheckGolden(filepath.Join("testdata", "harness.txtar"))
+
+	// The script is exhausted, so a second call fails.
+	res, err = cs.CallTool(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsError {
+		t.Error("CallTool with exhausted script succeeded unexpectedly")
+	}
+
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "addTool"}); err != nil {
+		t.Fatal(err)
+	}
+	h.AwaitNotifications("notifications/tools/list_changed", 1)
+}
+
+func TestScript(t *testing.T) {
+	s := mcptest.NewScript[*mcp.ListRootsParams, *mcp.ListRootsResult]()
+	errFail := errors.New("fail")
+	s.Fail(errFail)
+	server := mcp.NewServer(&mcp.Implementation{Name: "testServer", Version: "v1.0.0"}, nil)
+	h := mcptest.New(t, server, &mcptest.Options{Roots: s})
+	if _, err := h.ServerSession.ListRoots(context.Background(), nil); err == nil {
+		t.Error("ListRoots succeeded unexpectedly")
+	}
+	if got := len(s.Requests()); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+func TestNewStreamableServer(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "testServer", Version: "v1.0.0"}, nil)
+	httpServer := mcptest.NewStreamableServer(t, server, nil)
+	client := mcp.NewClient(&mcp.Implementation{Name: "testClient", Version: "v1.0.0"}, nil)
+	cs, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: httpServer.URL, HTTPClient: httpServer.Client()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	if err := cs.Ping(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+}
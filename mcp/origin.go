@@ -0,0 +1,168 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// This file implements validation of the Origin and Host headers of incoming
+// HTTP requests, to protect against DNS rebinding attacks, as well as CORS
+// support for browser-based clients.
+//
+// [§2.0.1] of the spec (2025-06-18) states:
+//
+// "Servers MUST validate the Origin header on all incoming connections to
+// prevent DNS rebinding attacks. When running locally, servers SHOULD bind
+// only to localhost (127.0.0.1) rather than all network interfaces."
+//
+// [§2.0.1]: https://modelcontextprotocol.io/specification/2025-06-18/basic/transports#security-warning
+
+// defaultExposedHeaders are the response headers exposed to browser-based
+// clients by default.
+var defaultExposedHeaders = []string{sessionIDHeader, protocolVersionHeader}
+
+// corsAllowedHeaders are the request headers allowed in CORS requests.
+var corsAllowedHeaders = []string{
+	"Accept",
+	"Authorization",
+	"Content-Type",
+	"Last-Event-ID",
+	protocolVersionHeader,
+	sessionIDHeader,
+}
+
+// An httpGuard validates the Origin and Host headers of incoming requests,
+// and handles CORS, as described in "Origin and Host validation" in the
+// package documentation.
+type httpGuard struct {
+	origins []string // allowed origins; nil means the default
+	hosts   []string // allowed hosts; nil means the default
+	exposed []string // headers exposed to CORS requests
+	methods string   // methods allowed in CORS requests
+}
+
+func newHTTPGuard(origins, hosts, exposed []string, methods string) *httpGuard {
+	if exposed == nil {
+		exposed = defaultExposedHeaders
+	}
+	return &httpGuard{origins: origins, hosts: hosts, exposed: exposed, methods: methods}
+}
+
+// check validates req, and reports whether it should be served.
+//
+// If the request is rejected, check replies with 403 Forbidden. If it is a CORS
+// preflight request, check replies to it. Either way, check returns false.
+func (g *httpGuard) check(w http.ResponseWriter, req *http.Request) bool {
+	loopback := isLoopbackServer(req)
+	if !g.hostAllowed(req.Host, loopback) {
+		http.Error(w, "Forbidden: invalid Host header", http.StatusForbidden)
+		return false
+	}
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		// Not a browser request, or a same-origin request from an older
+		// browser.
+		return true
+	}
+	if !g.originAllowed(origin, loopback) {
+		http.Error(w, "Forbidden: invalid Origin header", http.StatusForbidden)
+		return false
+	}
+	// Only origins configured explicitly may make cross-origin requests.
+	if g.origins == nil {
+		return true
+	}
+	h := w.Header()
+	h.Set("Access-Control-Allow-Origin", origin)
+	h.Add("Vary", "Origin")
+	if req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != "" {
+		h.Set("Access-Control-Allow-Methods", g.methods)
+		h.Set("Access-Control-Allow-Headers", strings.Join(corsAllowedHeaders, ", "))
+		h.Set("Access-Control-Max-Age", "86400")
+		w.WriteHeader(http.StatusNoContent)
+		return false
+	}
+	if len(g.exposed) > 0 {
+		h.Set("Access-Control-Expose-Headers", strings.Join(g.exposed, ", "))
+	}
+	return true
+}
+
+// hostAllowed reports whether requests for the given host are allowed.
+//
+// By default, servers listening on a loopback address only accept requests
+// for loopback hosts, so that a malicious page cannot reach them through a
+// domain that resolves to the loopback address (DNS rebinding). Other servers
+// accept requests for any host.
+func (g *httpGuard) hostAllowed(host string, loopback bool) bool {
+	if g.hosts == nil {
+		return !loopback || isLoopbackHost(hostname(host))
+	}
+	for _, h := range g.hosts {
+		if h == "*" || strings.EqualFold(h, host) {
+			return true
+		}
+		// A host without a port matches any port.
+		if _, _, err := net.SplitHostPort(h); err != nil && strings.EqualFold(hostname(h), hostname(host)) {
+			return true
+		}
+	}
+	return false
+}
+
+// originAllowed reports whether requests from the given origin are allowed.
+//
+// By default, servers listening on a loopback address only accept requests
+// from loopback origins. Other servers accept requests from any origin, but
+// do not allow cross-origin requests.
+func (g *httpGuard) originAllowed(origin string, loopback bool) bool {
+	if g.origins == nil {
+		if !loopback {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && isLoopbackHost(u.Hostname())
+	}
+	return slices.ContainsFunc(g.origins, func(o string) bool {
+		return o == "*" || strings.EqualFold(strings.TrimSuffix(o, "/"), origin)
+	})
+}
+
+// isLoopbackServer reports whether the server received req on a loopback
+// address.
+func isLoopbackServer(req *http.Request) bool {
+	addr, ok := req.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	if !ok {
+		return false
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isLoopbackHost reports whether host names the loopback interface.
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// hostname returns the host of a Host header, without its port or brackets.
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return strings.Trim(host, "[]")
+}
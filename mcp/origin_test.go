@@ -0,0 +1,118 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPGuard(t *testing.T) {
+	loopback := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}
+	public := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 8080}
+	tests := []struct {
+		name           string
+		origins, hosts []string
+		local          net.Addr
+		host, origin   string
+		want           bool
+	}{
+		{"loopback default", nil, nil, loopback, "localhost:8080", "", true},
+		{"loopback IP host", nil, nil, loopback, "127.0.0.1:8080", "", true},
+		{"loopback IPv6 host", nil, nil, loopback, "[::1]:8080", "", true},
+		{"rebound host", nil, nil, loopback, "evil.example:8080", "", false},
+		{"loopback origin", nil, nil, loopback, "localhost:8080", "http://localhost:3000", true},
+		{"remote origin", nil, nil, loopback, "localhost:8080", "https://evil.example", false},
+		{"null origin", nil, nil, loopback, "localhost:8080", "null", false},
+		{"public default", nil, nil, public, "example.com", "https://evil.example", true},
+		{"allowed origin", []string{"https://app.example"}, nil, public, "example.com", "https://app.example", true},
+		{"disallowed origin", []string{"https://app.example"}, nil, public, "example.com", "https://evil.example", false},
+		{"any origin", []string{"*"}, nil, loopback, "localhost", "https://evil.example", true},
+		{"allowed host", nil, []string{"example.com"}, public, "example.com:443", "", true},
+		{"allowed host and port", nil, []string{"example.com:8080"}, public, "example.com:8080", "", true},
+		{"wrong port", nil, []string{"example.com:8080"}, public, "example.com:443", "", false},
+		{"disallowed host", nil, []string{"example.com"}, public, "evil.example", "", false},
+		{"any host", nil, []string{"*"}, loopback, "evil.example", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newHTTPGuard(tt.origins, tt.hosts, nil, "GET, POST")
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, tt.local))
+			req.Host = tt.host
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			if got := g.check(w, req); got != tt.want {
+				t.Errorf("check() = %t, want %t", got, tt.want)
+			}
+			if !tt.want && w.Code != http.StatusForbidden {
+				t.Errorf("rejected request got status %d, want %d", w.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestStreamableCORS(t *testing.T) {
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return NewServer(testImpl, nil) }, &StreamableHTTPOptions{
+		AllowedOrigins: []string{"https://app.example"},
+	})
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	// Preflight requests from allowed origins are answered.
+	req, err := http.NewRequest(http.MethodOptions, httpServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Origin", "https://app.example")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("preflight status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://app.example" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, "https://app.example")
+	}
+
+	// Other origins are forbidden.
+	req.Header.Set("Origin", "https://evil.example")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("preflight from other origin: status = %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+
+	// Session headers are exposed to the allowed origin.
+	client := NewClient(testImpl, nil)
+	cs, err := client.Connect(context.Background(), &StreamableClientTransport{
+		Endpoint: httpServer.URL,
+		HTTPClient: &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("Origin", "https://app.example")
+			resp, err := http.DefaultTransport.RoundTrip(req)
+			if err == nil && req.Method == http.MethodPost {
+				if got := resp.Header.Get("Access-Control-Expose-Headers"); got != "Mcp-Session-Id, Mcp-Protocol-Version" {
+					t.Errorf("Access-Control-Expose-Headers = %q", got)
+				}
+			}
+			return resp, err
+		})},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs.Close()
+}
@@ -0,0 +1,259 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultProgressInterval is the default minimum interval between progress
+// notifications sent by a [Progress].
+const defaultProgressInterval = 100 * time.Millisecond
+
+// A Progress reports the progress of a request to the client that made it,
+// using "notifications/progress".
+//
+// Obtain a Progress from [ServerRequest.Progress]. Progress is measured as a
+// fraction between 0 and 1, and is sent to the client with a total of 1.
+//
+// Reports that do not increase progress are dropped, as the spec requires
+// progress to increase with each notification. Reports made more frequently
+// than [ServerOptions.ProgressInterval] are coalesced: only the most recent
+// one is sent when the interval elapses.
+//
+// When the handler for the request returns successfully, any pending report
+// is flushed and a final notification reporting completion is sent, so
+// handlers need not report completion themselves.
+//
+// If the client did not provide a progress token, all methods of Progress
+// are no-ops.
+type Progress struct {
+	r *progressReporter // nil if there is no progress token
+
+	// This Progress reports on the range [base, base+scale] of the root.
+	base, scale float64
+	next        float64 // start of the next sub-range, in local units; guarded by r.mu
+}
+
+// progressReporter holds the state shared by a Progress and its sub-reporters.
+type progressReporter struct {
+	ctx      context.Context // the request context, which routes notifications
+	session  *ServerSession
+	token    any
+	interval time.Duration
+
+	mu       sync.Mutex
+	used     bool    // Progress was called, so completion should be reported
+	done     bool    // the handler has returned
+	started  bool    // a report has been accepted
+	last     float64 // most recently accepted progress
+	lastSent time.Time
+	pending  *ProgressNotificationParams // accepted but not yet sent
+	timer    *time.Timer                 // non-nil if a flush is scheduled
+}
+
+// newProgressReporter returns a reporter for the request with the given
+// params, or nil if the request has no progress token.
+func newProgressReporter(ctx context.Context, ss *ServerSession, params Params) *progressReporter {
+	if params == nil {
+		return nil
+	}
+	token := getProgressToken(params)
+	if token == nil {
+		return nil
+	}
+	interval := ss.server.opts.ProgressInterval
+	if interval == 0 {
+		interval = defaultProgressInterval
+	}
+	return &progressReporter{ctx: ctx, session: ss, token: token, interval: interval}
+}
+
+// Report reports that the fraction of work done is progress, which is clamped
+// to [0, 1]. The message, if non-empty, describes the current state of the work.
+func (p *Progress) Report(progress float64, message string) {
+	if p.r == nil {
+		return
+	}
+	p.r.report(p.base+min(max(progress, 0), 1)*p.scale, message)
+}
+
+// Sub returns a Progress that reports on the next weight fraction of p's
+// range. Successive calls to Sub allocate successive ranges, so that, for
+// example,
+//
+//	download := p.Sub(0.3)
+//	process := p.Sub(0.7)
+//
+// reports the download as the first 30% of p, and processing as the remaining
+// 70%. The weight is clamped to the part of p's range that has not already
+// been allocated.
+func (p *Progress) Sub(weight float64) *Progress {
+	if p.r == nil {
+		return &Progress{}
+	}
+	p.r.mu.Lock()
+	defer p.r.mu.Unlock()
+	start := p.next
+	p.next = min(start+max(weight, 0), 1)
+	return &Progress{
+		r:     p.r,
+		base:  p.base + start*p.scale,
+		scale: (p.next - start) * p.scale,
+	}
+}
+
+func (r *progressReporter) report(progress float64, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	progress = min(progress, 1) // guard against rounding in nested ranges
+	if r.done || (r.started && progress <= r.last) {
+		return
+	}
+	r.started = true
+	r.last = progress
+	r.pending = &ProgressNotificationParams{
+		ProgressToken: r.token,
+		Message:       message,
+		Progress:      progress,
+		Total:         1,
+	}
+	if r.timer != nil {
+		return // a flush is already scheduled
+	}
+	if wait := r.interval - time.Since(r.lastSent); r.interval > 0 && wait > 0 {
+		r.timer = time.AfterFunc(wait, r.flush)
+		return
+	}
+	r.sendLocked()
+}
+
+func (r *progressReporter) flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timer = nil
+	if !r.done {
+		r.sendLocked()
+	}
+}
+
+// finish is called when the request handler returns with the given error.
+// It flushes any pending report and, if the handler succeeded, reports
+// completion.
+func (r *progressReporter) finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.done = true
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	if err == nil && r.used && (!r.started || r.last < 1) {
+		msg := ""
+		if r.pending != nil {
+			msg = r.pending.Message
+		}
+		r.last = 1
+		r.pending = &ProgressNotificationParams{ProgressToken: r.token, Message: msg, Progress: 1, Total: 1}
+	}
+	r.sendLocked()
+}
+
+// sendLocked sends the pending report, if any.
+func (r *progressReporter) sendLocked() {
+	if r.pending == nil {
+		return
+	}
+	params := r.pending
+	r.pending = nil
+	r.lastSent = time.Now()
+	if err := r.session.NotifyProgress(r.ctx, params); err != nil {
+		r.session.server.opts.Logger.Error("sending progress", "token", r.token, "error", err)
+	}
+}
+
+// progressListeners routes incoming progress notifications to listeners for
+// individual outgoing requests, keyed by progress token.
+type progressListeners struct {
+	mu    sync.Mutex
+	m     map[any][]*progressListener
+	owned map[any]bool // tokens with an exclusive listener
+}
+
+type progressListener struct {
+	f func(*ProgressNotificationParams)
+}
+
+// listen calls f for each progress notification with the given token, until
+// the returned function is called. f must not block.
+func (l *progressListeners) listen(token any, f func(*ProgressNotificationParams)) (stop func()) {
+	key := normalizeProgressToken(token)
+	pl := &progressListener{f}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.m == nil {
+		l.m = make(map[any][]*progressListener)
+	}
+	l.m[key] = append(l.m[key], pl)
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.m[key] = slices.DeleteFunc(l.m[key], func(pl2 *progressListener) bool { return pl2 == pl })
+		if len(l.m[key]) == 0 {
+			delete(l.m, key)
+		}
+	}
+}
+
+// listenExclusive is like listen, but reports false without listening if
+// another exclusive listener for the token is active. It lets callers that
+// own a progress token detect that the token is already in use.
+func (l *progressListeners) listenExclusive(token any, f func(*ProgressNotificationParams)) (stop func(), ok bool) {
+	key := normalizeProgressToken(token)
+	l.mu.Lock()
+	if l.owned[key] {
+		l.mu.Unlock()
+		return nil, false
+	}
+	if l.owned == nil {
+		l.owned = make(map[any]bool)
+	}
+	l.owned[key] = true
+	l.mu.Unlock()
+	stopListening := l.listen(token, f)
+	return func() {
+		stopListening()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.owned, key)
+	}, true
+}
+
+// dispatch calls the listeners for the notification's token, if any.
+func (l *progressListeners) dispatch(params *ProgressNotificationParams) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, pl := range l.m[normalizeProgressToken(params.ProgressToken)] {
+		pl.f(params)
+	}
+}
+
+// normalizeProgressToken returns a map key for the progress token t.
+// Integer tokens are converted to float64, as they are after a round trip
+// through JSON.
+func normalizeProgressToken(t any) any {
+	switch t := t.(type) {
+	case int:
+		return float64(t)
+	case int32:
+		return float64(t)
+	case int64:
+		return float64(t)
+	}
+	return t
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	type report struct {
+		progress float64
+		message  string
+	}
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		token    any
+		handler  func(*Progress)
+		want     []report
+	}{
+		{
+			name:    "no token",
+			handler: func(p *Progress) { p.Report(0.5, "half") },
+			want:    nil,
+		},
+		{
+			name:     "monotonic",
+			interval: -1,
+			token:    "tok",
+			handler: func(p *Progress) {
+				p.Report(0.5, "a")
+				p.Report(0.4, "dropped")
+				p.Report(0.5, "dropped")
+				p.Report(0.6, "b")
+			},
+			want: []report{{0.5, "a"}, {0.6, "b"}, {1, ""}},
+		},
+		{
+			name:     "sub",
+			interval: -1,
+			token:    1,
+			handler: func(p *Progress) {
+				download := p.Sub(0.3)
+				process := p.Sub(0.7)
+				download.Report(0.5, "downloading")
+				download.Report(1, "downloaded")
+				process.Report(0.5, "processing")
+				process.Sub(0.5).Report(0.5, "dropped") // 0.475
+			},
+			want: []report{{0.15, "downloading"}, {0.3, "downloaded"}, {0.65, "processing"}, {1, ""}},
+		},
+		{
+			name:     "coalesce",
+			interval: time.Hour,
+			token:    "tok",
+			handler: func(p *Progress) {
+				p.Report(0.1, "a")
+				p.Report(0.2, "b")
+				p.Report(0.3, "c")
+			},
+			want: []report{{0.1, "a"}, {1, "c"}},
+		},
+		{
+			name:     "complete",
+			interval: -1,
+			token:    "tok",
+			handler: func(p *Progress) {
+				p.Report(1, "done")
+				p.Report(1, "dropped")
+			},
+			want: []report{{1, "done"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := NewServer(testImpl, &ServerOptions{ProgressInterval: test.interval})
+			AddTool(server, &Tool{Name: "work"}, func(ctx context.Context, req *CallToolRequest, _ any) (*CallToolResult, any, error) {
+				test.handler(req.Progress())
+				return &CallToolResult{}, nil, nil
+			})
+
+			reports := make(chan report, 10)
+			client := NewClient(testImpl, &ClientOptions{
+				ProgressNotificationHandler: func(_ context.Context, req *ProgressNotificationClientRequest) {
+					if req.Params.Total != 1 {
+						t.Errorf("got total %v, want 1", req.Params.Total)
+					}
+					reports <- report{req.Params.Progress, req.Params.Message}
+				},
+			})
+			st, ct := NewInMemoryTransports()
+			ss, err := server.Connect(ctx, st, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ss.Close()
+			cs, err := client.Connect(ctx, ct, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cs.Close()
+
+			params := &CallToolParams{Name: "work"}
+			if test.token != nil {
+				params.Meta = Meta{progressTokenKey: test.token}
+			}
+			if _, err := cs.CallTool(ctx, params); err != nil {
+				t.Fatal(err)
+			}
+
+			// Notifications may be handled after the response is received, so
+			// wait for as many as are expected.
+			var got []report
+			for range test.want {
+				select {
+				case r := <-reports:
+					got = append(got, r)
+				case <-time.After(5 * time.Second):
+					t.Fatalf("got %v, timed out waiting for %v", got, test.want)
+				}
+			}
+			select {
+			case r := <-reports:
+				t.Fatalf("got unexpected report %v after %v", r, got)
+			default:
+			}
+			for i := range got {
+				if math.Abs(got[i].progress-test.want[i].progress) > 1e-9 || got[i].message != test.want[i].message {
+					t.Errorf("report #%d: got %v, want %v", i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
@@ -14,4 +14,5 @@ type PromptHandler func(context.Context, *GetPromptRequest) (*GetPromptResult, e
 type serverPrompt struct {
 	prompt  *Prompt
 	handler PromptHandler
+	scopes  []string // see [Server.RequirePromptScopes]
 }
@@ -12,6 +12,7 @@ package mcp
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 )
 
@@ -109,24 +110,44 @@ type CallToolResult struct {
 	// the Content field.
 	IsError bool `json:"isError,omitempty"`
 
-	// The error passed to setError, if any.
+	// The error passed to SetError, if any.
 	// It is not marshaled, and therefore it is only visible on the server.
-	// Its only use is in server sending middleware, where it can be accessed
-	// with getError.
+	// Its only use is in server middleware, where it can be accessed
+	// with GetError.
 	err error
 }
 
-// TODO(#64): consider exposing setError (and getError), by adding an error
-// field on CallToolResult.
-func (r *CallToolResult) setError(err error) {
-	r.Content = []Content{&TextContent{Text: err.Error()}}
+// SetError makes r report err as a tool error: it sets IsError, and sets
+// Content to the text of err.
+//
+// If err is or wraps a [*ToolError], the result is built from its fields
+// instead: see [ToolError].
+func (r *CallToolResult) SetError(err error) {
 	r.IsError = true
 	r.err = err
+	var terr *ToolError
+	if !errors.As(err, &terr) {
+		r.Content = []Content{&TextContent{Text: err.Error()}}
+		return
+	}
+	r.Content = terr.Content
+	if r.Content == nil {
+		r.Content = []Content{&TextContent{Text: terr.Error()}}
+	}
+	if terr.Details != nil {
+		r.StructuredContent = terr.Details
+	}
+	if terr.Retryable {
+		if r.Meta == nil {
+			r.Meta = Meta{}
+		}
+		r.Meta[retryableMetaKey] = true
+	}
 }
 
-// getError returns the error set with setError, or nil if none.
+// GetError returns the error set with SetError, or nil if none.
 // This function always returns nil on clients.
-func (r *CallToolResult) getError() error {
+func (r *CallToolResult) GetError() error {
 	return r.err
 }
 
@@ -882,8 +903,8 @@ type SamplingCapabilities struct{}
 //
 // If neither Form nor URL is set, the 'Form' capabilitiy is assumed.
 type ElicitationCapabilities struct {
-	Form *FormElicitationCapabilities
-	URL  *URLElicitationCapabilities
+	Form *FormElicitationCapabilities `json:"form,omitempty"`
+	URL  *URLElicitationCapabilities  `json:"url,omitempty"`
 }
 
 // FormElicitationCapabilities describes capabilities for form elicitation.
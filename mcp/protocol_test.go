@@ -68,6 +68,13 @@ func TestParamsMeta(t *testing.T) {
 	p.SetProgressToken(int(1))
 	p.SetProgressToken(int32(1))
 	p.SetProgressToken(int64(1))
+
+	// SetProgressToken works on params without a Meta.
+	p3 := &CallToolParams{Name: "n"}
+	p3.SetProgressToken("t")
+	if g := p3.GetProgressToken(); g != "t" {
+		t.Errorf("got %v, want `t`", g)
+	}
 }
 
 func TestCompleteReference(t *testing.T) {
@@ -531,3 +538,29 @@ func TestContentUnmarshal(t *testing.T) {
 	var gotpm PromptMessage
 	roundtrip(pm, &gotpm)
 }
+
+func TestElicitationCapabilitiesJSON(t *testing.T) {
+	for _, test := range []struct {
+		caps ElicitationCapabilities
+		want string
+	}{
+		{ElicitationCapabilities{}, `{}`},
+		{ElicitationCapabilities{Form: &FormElicitationCapabilities{}}, `{"form":{}}`},
+		{ElicitationCapabilities{Form: &FormElicitationCapabilities{}, URL: &URLElicitationCapabilities{}}, `{"form":{},"url":{}}`},
+	} {
+		data, err := json.Marshal(test.caps)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); got != test.want {
+			t.Errorf("json.Marshal(%+v) = %s, want %s", test.caps, got, test.want)
+		}
+		var got ElicitationCapabilities
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.caps, got); diff != "" {
+			t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
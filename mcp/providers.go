@@ -0,0 +1,300 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// This file implements providers: sources of features that are not held in
+// memory by the server, such as a catalog of resources in a database.
+
+// A ToolProvider provides tools to a server, in addition to the tools added
+// with [Server.AddTool]. See [Server.AddToolProvider].
+//
+// A provider must be safe for concurrent use.
+type ToolProvider interface {
+	// List returns a page of tools, starting at the given cursor, and the
+	// cursor for the next page. The cursor of the first page is "", and List
+	// returns a next cursor of "" on the last page. The provider chooses the
+	// size of its pages, and the form of its cursors.
+	//
+	// The server sends the provider's cursors to clients inside its own
+	// cursors, and passes them back to List. They are authenticated by the
+	// server's [ServerOptions.CursorCodec], which by default signs them, so
+	// that List only receives cursors it returned. A server with a
+	// [NewUnsignedCursorCodec] passes cursors chosen by clients, which List
+	// must then treat as untrusted input.
+	List(ctx context.Context, cursor string) (tools []*Tool, nextCursor string, err error)
+	// Get returns the tool with the given name and its handler, or a nil tool
+	// and a nil error if there is no such tool. A tool returned without a
+	// handler is an error.
+	Get(ctx context.Context, name string) (*Tool, ToolHandler, error)
+}
+
+// A PromptProvider provides prompts to a server, in addition to the prompts
+// added with [Server.AddPrompt]. Its methods are like those of [ToolProvider].
+type PromptProvider interface {
+	List(ctx context.Context, cursor string) (prompts []*Prompt, nextCursor string, err error)
+	Get(ctx context.Context, name string) (*Prompt, PromptHandler, error)
+}
+
+// A ResourceProvider provides resources to a server, in addition to the
+// resources added with [Server.AddResource]. Its methods are like those of
+// [ToolProvider], but Get takes the URI of a resource.
+type ResourceProvider interface {
+	List(ctx context.Context, cursor string) (resources []*Resource, nextCursor string, err error)
+	Get(ctx context.Context, uri string) (*Resource, ResourceHandler, error)
+}
+
+// AddToolProvider adds a provider of tools to the server.
+//
+// The tools of the server's providers are listed after its own tools, one
+// provider page per list page, in the order in which the providers were added.
+// A provided tool with the same name as one of the server's own tools is
+// ignored. Calls to tools that the server does not have are looked up with
+// the providers' Get methods, in order.
+//
+// Provided tools are subject to [ServerOptions.ToolFilter], but not to
+// required scopes (see [Server.RequireToolScopes]). They are not validated:
+// the provider is responsible for their schemas, handlers and authorization.
+//
+// When the tools of a provider change, call [Server.NotifyToolListChanged].
+func (s *Server) AddToolProvider(p ToolProvider) {
+	s.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
+		func() bool { s.toolProviders = append(s.toolProviders, toolProvider{p}); return true })
+}
+
+// AddPromptProvider adds a provider of prompts to the server, as
+// [Server.AddToolProvider] does for tools.
+//
+// When the prompts of a provider change, call [Server.NotifyPromptListChanged].
+func (s *Server) AddPromptProvider(p PromptProvider) {
+	s.changeAndNotify(notificationPromptListChanged, &PromptListChangedParams{},
+		func() bool { s.promptProviders = append(s.promptProviders, promptProvider{p}); return true })
+}
+
+// AddResourceProvider adds a provider of resources to the server, as
+// [Server.AddToolProvider] does for tools. Resources are looked up with the
+// providers after the server's own resources and resource templates.
+//
+// When the resources of a provider change, call
+// [Server.NotifyResourceListChanged].
+func (s *Server) AddResourceProvider(p ResourceProvider) {
+	s.changeAndNotify(notificationResourceListChanged, &ResourceListChangedParams{},
+		func() bool { s.resourceProviders = append(s.resourceProviders, resourceProvider{p}); return true })
+}
+
+// NotifyToolListChanged notifies all sessions that the list of tools has
+// changed, for reasons the server can't see: for example, because the tools
+// of a [ToolProvider] or the results of [ServerOptions.ToolFilter] changed.
+func (s *Server) NotifyToolListChanged() {
+	s.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{}, func() bool { return true })
+}
+
+// NotifyPromptListChanged notifies all sessions that the list of prompts has
+// changed, as [Server.NotifyToolListChanged] does for tools.
+func (s *Server) NotifyPromptListChanged() {
+	s.changeAndNotify(notificationPromptListChanged, &PromptListChangedParams{}, func() bool { return true })
+}
+
+// NotifyResourceListChanged notifies all sessions that the list of resources
+// has changed, as [Server.NotifyToolListChanged] does for tools.
+func (s *Server) NotifyResourceListChanged() {
+	s.changeAndNotify(notificationResourceListChanged, &ResourceListChangedParams{}, func() bool { return true })
+}
+
+// A provider is the common form of the provider interfaces, which provides
+// features of type T.
+type provider[T any] interface {
+	list(ctx context.Context, cursor string) ([]T, string, error)
+	get(ctx context.Context, id string) (T, bool, error)
+}
+
+type toolProvider struct{ p ToolProvider }
+
+func (p toolProvider) list(ctx context.Context, cursor string) ([]*serverTool, string, error) {
+	tools, next, err := p.p.List(ctx, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	sts := make([]*serverTool, len(tools))
+	for i, t := range tools {
+		sts[i] = &serverTool{tool: t}
+	}
+	return sts, next, nil
+}
+
+func (p toolProvider) get(ctx context.Context, name string) (*serverTool, bool, error) {
+	t, h, err := p.p.Get(ctx, name)
+	if err != nil || t == nil {
+		return nil, false, err
+	}
+	if h == nil {
+		return nil, false, fmt.Errorf("tool provider returned tool %q without a handler", name)
+	}
+	return &serverTool{tool: t, handler: h}, true, nil
+}
+
+type promptProvider struct{ p PromptProvider }
+
+func (p promptProvider) list(ctx context.Context, cursor string) ([]*serverPrompt, string, error) {
+	prompts, next, err := p.p.List(ctx, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	sps := make([]*serverPrompt, len(prompts))
+	for i, pr := range prompts {
+		sps[i] = &serverPrompt{prompt: pr}
+	}
+	return sps, next, nil
+}
+
+func (p promptProvider) get(ctx context.Context, name string) (*serverPrompt, bool, error) {
+	pr, h, err := p.p.Get(ctx, name)
+	if err != nil || pr == nil {
+		return nil, false, err
+	}
+	if h == nil {
+		return nil, false, fmt.Errorf("prompt provider returned prompt %q without a handler", name)
+	}
+	return &serverPrompt{prompt: pr, handler: h}, true, nil
+}
+
+type resourceProvider struct{ p ResourceProvider }
+
+func (p resourceProvider) list(ctx context.Context, cursor string) ([]*serverResource, string, error) {
+	resources, next, err := p.p.List(ctx, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	srs := make([]*serverResource, len(resources))
+	for i, r := range resources {
+		srs[i] = &serverResource{resource: r}
+	}
+	return srs, next, nil
+}
+
+func (p resourceProvider) get(ctx context.Context, uri string) (*serverResource, bool, error) {
+	r, h, err := p.p.Get(ctx, uri)
+	if err != nil || r == nil {
+		return nil, false, err
+	}
+	if h == nil {
+		return nil, false, fmt.Errorf("resource provider returned resource %q without a handler", uri)
+	}
+	return &serverResource{resource: r, handler: h}, true, nil
+}
+
+// getProvided returns the feature with the given ID from the first of
+// providers that has it.
+func getProvided[T any](ctx context.Context, providers []provider[T], id string) (T, bool, error) {
+	for _, p := range providers {
+		if f, ok, err := p.get(ctx, id); err != nil || ok {
+			return f, ok, err
+		}
+	}
+	var zero T
+	return zero, false, nil
+}
+
+// paginateProvided is like [paginateVisible], but lists the features of
+// providers after the server's own features.
+//
+// The sources function returns the server's own features and its providers.
+// It and visible are called with s.mu held. Providers and filter, one of the
+// feature filters of [ServerOptions] (or nil), are called without it: the
+// server's own features are listed from a snapshot if there is a filter.
+//
+// The server's own features are paginated as by paginateVisible. Then each
+// page lists one page of a provider, with the cursor recording the provider
+// and its own cursor, so that cursors remain stable as long as the providers'
+// cursors are.
+func paginateProvided[P listParams, R listResult[T], T any](ctx context.Context, s *Server, sources func() (featureList[T], []provider[T]), visible, filter func(T) bool, params P, res R, setFunc func(R, []T)) (R, error) {
+	var zero R
+	codec := s.opts.CursorCodec
+	var token pageToken
+	if c := params.cursorPtr(); c != nil && *c != "" {
+		var err error
+		if token, err = decodeToken(codec, *c); err != nil {
+			return zero, err
+		}
+	}
+
+	var (
+		n    int // number of the server's own features listed
+		err  error
+		snap featureList[T]
+	)
+	setOwn := func(res R, features []T) {
+		n = len(features)
+		setFunc(res, features)
+	}
+	s.mu.Lock()
+	fs, providers := sources()
+	if token.Provider == 0 {
+		if filter == nil {
+			res, err = paginateVisible(codec, fs, visible, s.opts.PageSize, params, res, setOwn)
+		} else {
+			snap = snapshot(fs, visible)
+		}
+	}
+	s.mu.Unlock()
+	if snap != nil {
+		res, err = paginateVisible(codec, snap, filter, s.opts.PageSize, params, res, setOwn)
+	}
+	if token.Provider == 0 {
+		if err != nil || *res.nextCursorPtr() != "" || len(providers) == 0 {
+			return res, err
+		}
+		// The server's own features are exhausted: continue with the first
+		// provider, on this page if it is empty.
+		token = pageToken{Provider: 1}
+		if n > 0 {
+			return setNextToken[R, T](codec, res, token)
+		}
+	}
+	if token.Provider < 0 || token.Provider > len(providers) {
+		return zero, invalidCursorError(fmt.Errorf("no provider %d", token.Provider))
+	}
+
+	provided, next, err := providers[token.Provider-1].list(ctx, token.ProviderCursor)
+	if err != nil {
+		return zero, err
+	}
+	var features []T
+	s.mu.Lock()
+	for _, f := range provided {
+		// The server's own features take precedence.
+		if _, ok := fs.get(fs.id(f)); !ok && visible(f) {
+			features = append(features, f)
+		}
+	}
+	s.mu.Unlock()
+	if filter != nil {
+		features = slices.DeleteFunc(features, func(f T) bool { return !filter(f) })
+	}
+	setFunc(res, features)
+	switch {
+	case next != "":
+		return setNextToken[R, T](codec, res, pageToken{Provider: token.Provider, ProviderCursor: next})
+	case token.Provider < len(providers):
+		return setNextToken[R, T](codec, res, pageToken{Provider: token.Provider + 1})
+	}
+	return res, nil
+}
+
+// setNextToken sets the next cursor of res to the encoding of token.
+func setNextToken[R listResult[T], T any](codec CursorCodec, res R, token pageToken) (R, error) {
+	cursor, err := encodeToken(codec, token)
+	if err != nil {
+		var zero R
+		return zero, err
+	}
+	*res.nextCursorPtr() = cursor
+	return res, nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// catalog is a provider of the tools and resources named by its names, which
+// it lists in pages of three.
+type catalog struct {
+	names []string
+}
+
+func (c *catalog) page(cursor string) (names []string, next string, err error) {
+	start := 0
+	if cursor != "" {
+		if start, err = strconv.Atoi(cursor); err != nil {
+			return nil, "", fmt.Errorf("bad cursor %q", cursor)
+		}
+	}
+	end := min(start+3, len(c.names))
+	if end < len(c.names) {
+		next = strconv.Itoa(end)
+	}
+	return c.names[start:end], next, nil
+}
+
+func (c *catalog) List(_ context.Context, cursor string) ([]*Tool, string, error) {
+	names, next, err := c.page(cursor)
+	var tools []*Tool
+	for _, name := range names {
+		tools = append(tools, &Tool{Name: name, InputSchema: &jsonschema.Schema{Type: "object"}})
+	}
+	return tools, next, err
+}
+
+func (c *catalog) Get(_ context.Context, name string) (*Tool, ToolHandler, error) {
+	if !slices.Contains(c.names, name) {
+		return nil, nil, nil
+	}
+	if name == "unhandled" {
+		return &Tool{Name: name}, nil, nil // a provider bug
+	}
+	return &Tool{Name: name, InputSchema: &jsonschema.Schema{Type: "object"}}, func(context.Context, *CallToolRequest) (*CallToolResult, error) {
+		return &CallToolResult{Content: []Content{&TextContent{Text: "provided " + name}}}, nil
+	}, nil
+}
+
+type resourceCatalog struct{ catalog }
+
+func (c *resourceCatalog) List(_ context.Context, cursor string) ([]*Resource, string, error) {
+	names, next, err := c.page(cursor)
+	var resources []*Resource
+	for _, name := range names {
+		resources = append(resources, &Resource{URI: "db:///" + name, Name: name})
+	}
+	return resources, next, err
+}
+
+func (c *resourceCatalog) Get(_ context.Context, uri string) (*Resource, ResourceHandler, error) {
+	if uri == "db:///broken" {
+		return nil, nil, fmt.Errorf("database unavailable")
+	}
+	for _, name := range c.names {
+		if uri == "db:///"+name {
+			return &Resource{URI: uri, Name: name}, func(context.Context, *ReadResourceRequest) (*ReadResourceResult, error) {
+				return &ReadResourceResult{Contents: []*ResourceContents{{Text: name}}}, nil
+			}, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+func TestProviders(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, &ServerOptions{
+		PageSize: 2,
+		ToolFilter: func(_ *ServerSession, t *Tool) bool {
+			return t.Name != "hidden"
+		},
+	})
+	handler := func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+		return &CallToolResult{Content: []Content{&TextContent{Text: "static"}}}, nil, nil
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		AddTool(server, &Tool{Name: name}, handler)
+	}
+	// The second provider has a tool with the same name as a static tool, and
+	// a tool hidden by the filter.
+	server.AddToolProvider(&catalog{names: []string{"p1", "p2", "p3", "p4"}})
+	server.AddToolProvider(&catalog{names: []string{"a", "hidden", "q1", "unhandled"}})
+	server.AddResourceProvider(&resourceCatalog{catalog{names: []string{"row1", "row2"}}})
+
+	changed := make(chan struct{}, 10)
+	client := NewClient(testImpl, &ClientOptions{
+		ToolListChangedHandler: func(context.Context, *ToolListChangedRequest) { changed <- struct{}{} },
+	})
+	cs, _, _ := basicClientServerConnection(t, client, server, nil)
+
+	var got []string
+	pages := 0
+	var cursor string
+	for {
+		res, err := cs.ListTools(ctx, &ListToolsParams{Cursor: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		for _, tool := range res.Tools {
+			got = append(got, tool.Name)
+		}
+		if res.NextCursor == "" {
+			break
+		}
+		cursor = res.NextCursor
+	}
+	want := []string{"a", "b", "c", "p1", "p2", "p3", "p4", "q1", "unhandled"}
+	if !slices.Equal(got, want) {
+		t.Errorf("listed tools %v, want %v", got, want)
+	}
+	// Pages: [a b] [c] [p1 p2 p3] [p4] [q1] [unhandled].
+	if pages != 6 {
+		t.Errorf("got %d pages, want 6", pages)
+	}
+
+	for name, want := range map[string]string{"a": "static", "p2": "provided p2", "q1": "provided q1"} {
+		res, err := cs.CallTool(ctx, &CallToolParams{Name: name})
+		if err != nil {
+			t.Fatalf("calling %s: %v", name, err)
+		}
+		if got := res.Content[0].(*TextContent).Text; got != want {
+			t.Errorf("calling %s: got %q, want %q", name, got, want)
+		}
+	}
+	for _, name := range []string{"hidden", "nonexistent", "unhandled"} {
+		if _, err := cs.CallTool(ctx, &CallToolParams{Name: name}); err == nil {
+			t.Errorf("calling %s succeeded, want error", name)
+		}
+	}
+	// A provided tool without a handler is reported, rather than panicking.
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "unhandled"}); err == nil || !strings.Contains(err.Error(), "without a handler") {
+		t.Errorf("calling unhandled: got error %v, want missing handler error", err)
+	}
+
+	resources, err := cs.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources.Resources) != 2 || resources.NextCursor != "" {
+		t.Errorf("listed resources %v, next cursor %q; want two resources", resources.Resources, resources.NextCursor)
+	}
+	rres, err := cs.ReadResource(ctx, &ReadResourceParams{URI: "db:///row2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rres.Contents[0]; got.Text != "row2" || got.URI != "db:///row2" {
+		t.Errorf("reading row2: got %+v", got)
+	}
+	if _, err := cs.ReadResource(ctx, &ReadResourceParams{URI: "db:///broken"}); err == nil {
+		t.Error("reading a broken resource succeeded, want error")
+	}
+
+	// A cursor naming a nonexistent provider is invalid.
+	bad, err := encodeToken(defaultCursorCodec(), pageToken{Provider: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.ListTools(ctx, &ListToolsParams{Cursor: bad}); err == nil {
+		t.Error("listing with a bad provider cursor succeeded, want error")
+	}
+
+	server.NotifyToolListChanged()
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for tools/list_changed")
+	}
+}
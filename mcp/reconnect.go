@@ -0,0 +1,439 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/internal/jsonrpc2"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// ReconnectOptions configures automatic reconnection of a [ClientSession].
+//
+// When the connection of a reconnecting session fails (for example, because a
+// [CommandTransport] subprocess exited, or a streamable server forgot the
+// session), the session connects again using the same [Transport], and
+// re-initializes. Resource subscriptions are restored, and the server is
+// notified that the client's roots may have changed.
+//
+// Requests that were in flight when the connection failed are retried on the
+// new connection if they are idempotent: list and read methods, and calls to
+// tools annotated as read-only or idempotent (as reported by the most recent
+// [ClientSession.ListTools]). Other requests fail. Requests made while
+// reconnecting wait for reconnection to finish.
+type ReconnectOptions struct {
+	// MaxAttempts is the maximum number of consecutive attempts to reconnect.
+	// If it is exceeded, the session is closed.
+	// If zero, defaults to 5.
+	MaxAttempts int
+	// InitialDelay is the delay before the second attempt to reconnect.
+	// The first attempt is made immediately. Subsequent delays double, up to
+	// MaxDelay.
+	// If zero, defaults to 1s.
+	InitialDelay time.Duration
+	// MaxDelay is the maximum delay between attempts to reconnect.
+	// If zero, defaults to 30s.
+	MaxDelay time.Duration
+	// MaxRetries is the maximum number of times an idempotent request is
+	// retried when the connection fails while it is in flight. Each retry
+	// waits for the session to reconnect.
+	// If zero, defaults to 1. If negative, requests are not retried.
+	MaxRetries int
+	// If non-nil, EventHandler is called as reconnection progresses, so that
+	// hosts can report it to users. It is called synchronously, and must not
+	// block.
+	EventHandler func(ReconnectEvent)
+}
+
+// ReconnectEventKind is the kind of a [ReconnectEvent].
+type ReconnectEventKind int
+
+const (
+	// The connection failed, and the session is reconnecting.
+	ReconnectStarted ReconnectEventKind = iota
+	// An attempt to reconnect failed. Another may follow.
+	ReconnectAttemptFailed
+	// The session reconnected, and is ready to use.
+	Reconnected
+	// Reconnection failed, and the session is closed.
+	ReconnectFailed
+)
+
+func (k ReconnectEventKind) String() string {
+	switch k {
+	case ReconnectStarted:
+		return "ReconnectStarted"
+	case ReconnectAttemptFailed:
+		return "ReconnectAttemptFailed"
+	case Reconnected:
+		return "Reconnected"
+	case ReconnectFailed:
+		return "ReconnectFailed"
+	}
+	return fmt.Sprintf("ReconnectEventKind(%d)", int(k))
+}
+
+// A ReconnectEvent describes the progress of reconnecting a [ClientSession].
+type ReconnectEvent struct {
+	Kind ReconnectEventKind
+	// Attempt is the number of the attempt to reconnect, starting at 1.
+	// It is zero for ReconnectStarted.
+	Attempt int
+	// Err is the reason the connection failed, for ReconnectStarted, or the
+	// reason the attempt failed, otherwise.
+	Err error
+}
+
+// reconnector holds the state of a ClientSession that reconnects
+// automatically. Its fields are guarded by the session's mu.
+type reconnector struct {
+	opts      ReconnectOptions
+	transport Transport
+
+	reconnecting chan struct{}                          // non-nil while reconnecting; closed when done
+	closing      bool                                   // Close was called
+	retired      map[*jsonrpc2.Connection]chan struct{} // closed when the connection is done
+
+	closeOnce sync.Once
+	closed    chan struct{} // closed when the session is closed for good
+	err       error         // reason the session closed, if not Close
+
+	subscriptions   map[string]bool // subscribed resource URIs
+	idempotentTools map[string]bool // tools that may be retried
+}
+
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// reconnectKey is the context key marking the requests that a session makes
+// while reconnecting, which must not wait for reconnection.
+type reconnectKey struct{}
+
+// startReconnecting makes cs reconnect automatically using t.
+func (cs *ClientSession) startReconnecting(t Transport, opts *ReconnectOptions) {
+	rc := &reconnector{
+		opts:            *opts,
+		transport:       t,
+		retired:         make(map[*jsonrpc2.Connection]chan struct{}),
+		closed:          make(chan struct{}),
+		subscriptions:   make(map[string]bool),
+		idempotentTools: make(map[string]bool),
+	}
+	if rc.opts.MaxAttempts <= 0 {
+		rc.opts.MaxAttempts = 5
+	}
+	if rc.opts.InitialDelay <= 0 {
+		rc.opts.InitialDelay = 1 * time.Second
+	}
+	if rc.opts.MaxDelay <= 0 {
+		rc.opts.MaxDelay = 30 * time.Second
+	}
+	if rc.opts.MaxRetries == 0 {
+		rc.opts.MaxRetries = 1
+	}
+	cs.mu.Lock()
+	cs.rc = rc
+	cs.mu.Unlock()
+}
+
+// connDone is called when conn is done. It reports whether the session is
+// reconnecting (or will reconnect), and so should remain open.
+func (cs *ClientSession) connDone(conn *jsonrpc2.Connection) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	rc := cs.rc
+	if rc == nil {
+		return false
+	}
+	if ch, ok := rc.retired[conn]; ok {
+		close(ch)
+	} else {
+		rc.retired[conn] = closedChan
+	}
+	if rc.closing {
+		return false
+	}
+	if conn != cs.conn || rc.reconnecting != nil {
+		return true // a stale connection, or a failed attempt to reconnect
+	}
+	rc.reconnecting = make(chan struct{})
+	go cs.reconnect(conn)
+	return true
+}
+
+// connDoneChan returns a channel that is closed when conn is done.
+func (cs *ClientSession) connDoneChan(conn *jsonrpc2.Connection) <-chan struct{} {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	ch, ok := cs.rc.retired[conn]
+	if !ok {
+		ch = make(chan struct{})
+		cs.rc.retired[conn] = ch
+	}
+	return ch
+}
+
+// reconnect reconnects the session after its connection, old, failed.
+func (cs *ClientSession) reconnect(old *jsonrpc2.Connection) {
+	rc := cs.rc
+	cause := old.Wait()
+	if cause == nil {
+		cause = ErrConnectionClosed
+	}
+	cs.reconnectEvent(ReconnectEvent{Kind: ReconnectStarted, Err: cause})
+
+	var err error
+	delay := rc.opts.InitialDelay
+	for attempt := 1; attempt <= rc.opts.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(delay):
+			case <-rc.closed:
+			}
+			delay = min(delay*2, rc.opts.MaxDelay)
+		}
+		if cs.isClosing() {
+			cs.doneReconnecting()
+			return
+		}
+		if err = cs.reconnectOnce(); err == nil {
+			cs.doneReconnecting()
+			cs.reconnectEvent(ReconnectEvent{Kind: Reconnected, Attempt: attempt})
+			return
+		}
+		cs.reconnectEvent(ReconnectEvent{Kind: ReconnectAttemptFailed, Attempt: attempt, Err: err})
+	}
+	cs.client.removeSession(cs)
+	cs.finish(fmt.Errorf("%w: reconnecting: %v", ErrConnectionClosed, err))
+	cs.doneReconnecting()
+	cs.reconnectEvent(ReconnectEvent{Kind: ReconnectFailed, Attempt: rc.opts.MaxAttempts, Err: err})
+}
+
+// doneReconnecting releases requests waiting for reconnection.
+func (cs *ClientSession) doneReconnecting() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	close(cs.rc.reconnecting)
+	cs.rc.reconnecting = nil
+}
+
+// reconnectOnce makes a single attempt to reconnect and restore the session.
+func (cs *ClientSession) reconnectOnce() error {
+	rc := cs.rc
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), reconnectKey{}, true))
+	defer cancel()
+	go func() {
+		select {
+		case <-rc.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if _, err := connect(ctx, rc.transport, &reconnectBinder{cs: cs}, (*clientSessionState)(nil), nil); err != nil {
+		return err
+	}
+	if cs.isClosing() {
+		_ = cs.closeConn()
+		return ErrConnectionClosed
+	}
+	if err := cs.initialize(ctx); err != nil {
+		_ = cs.closeConn()
+		return err
+	}
+	cs.mu.Lock()
+	var uris []string
+	for uri := range rc.subscriptions {
+		uris = append(uris, uri)
+	}
+	cs.mu.Unlock()
+	for _, uri := range uris {
+		if err := cs.Subscribe(ctx, &SubscribeParams{URI: uri}); err != nil {
+			_ = cs.closeConn()
+			return fmt.Errorf("resubscribing to %q: %w", uri, err)
+		}
+	}
+	c := cs.client
+	c.mu.Lock()
+	hasRoots := c.roots.len() > 0
+	c.mu.Unlock()
+	if hasRoots {
+		req := newClientRequest(cs, orZero[Params](&RootsListChangedParams{}))
+		if err := handleNotify(ctx, notificationRootsListChanged, req); err != nil {
+			_ = cs.closeConn()
+			return err
+		}
+	}
+	return nil
+}
+
+func (cs *ClientSession) reconnectEvent(e ReconnectEvent) {
+	if h := cs.rc.opts.EventHandler; h != nil {
+		h(e)
+	}
+}
+
+func (cs *ClientSession) isClosing() bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.rc.closing
+}
+
+// finish marks a reconnecting session as closed for good, for the given
+// reason.
+func (cs *ClientSession) finish(err error) {
+	cs.rc.closeOnce.Do(func() {
+		cs.rc.err = err
+		close(cs.rc.closed)
+	})
+}
+
+// sendReconnecting sends a request or notification on a reconnecting session.
+//
+// If the session is reconnecting, it waits for reconnection to finish. If the
+// connection fails while a request is in flight, and the request is
+// idempotent, it is retried once the session has reconnected, up to
+// [ReconnectOptions.MaxRetries] times.
+func (cs *ClientSession) sendReconnecting(ctx context.Context, method string, info methodInfo, params Params) (Result, error) {
+	rc := cs.rc
+	for retries := 0; ; retries++ {
+		conn, err := cs.awaitReconnect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Notifications don't have results.
+		if strings.HasPrefix(method, "notifications/") {
+			return nil, conn.Notify(ctx, method, params)
+		}
+		res := info.newResult()
+		err = call(ctx, conn, method, params, res)
+		if err == nil {
+			return res, nil
+		}
+		var wireErr *jsonrpc.Error
+		if errors.As(err, &wireErr) || ctx.Err() != nil || retries >= rc.opts.MaxRetries || !cs.retryable(method, params) {
+			return nil, err
+		}
+		// The connection failed. Wait for it to be done, at which point the
+		// session is reconnecting, and retry.
+		select {
+		case <-cs.connDoneChan(conn):
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
+// awaitReconnect waits for any reconnection in progress to finish, and
+// returns the connection to use.
+func (cs *ClientSession) awaitReconnect(ctx context.Context) (*jsonrpc2.Connection, error) {
+	for {
+		cs.mu.Lock()
+		conn, rc := cs.conn, cs.rc
+		reconnecting := rc.reconnecting
+		cs.mu.Unlock()
+		select {
+		case <-rc.closed:
+			if rc.err != nil {
+				return nil, rc.err
+			}
+			return conn, nil // let the closed connection report the error
+		default:
+		}
+		if reconnecting == nil || ctx.Value(reconnectKey{}) != nil {
+			return conn, nil
+		}
+		select {
+		case <-reconnecting:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryable reports whether a request may be retried after the connection
+// fails.
+func (cs *ClientSession) retryable(method string, params Params) bool {
+	switch method {
+	case methodPing, methodListTools, methodListPrompts, methodGetPrompt,
+		methodListResources, methodListResourceTemplates, methodReadResource,
+		methodComplete, methodSubscribe, methodUnsubscribe, methodSetLevel:
+		return true
+	case methodCallTool:
+		var name string
+		switch p := params.(type) {
+		case *CallToolParams:
+			name = p.Name
+		case *CallToolParamsRaw:
+			name = p.Name
+		}
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+		return cs.rc.idempotentTools[name]
+	}
+	return false
+}
+
+// noteTools records which of the listed tools may be retried.
+func (cs *ClientSession) noteTools(tools []*Tool) {
+	if cs.rc == nil {
+		return
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for _, t := range tools {
+		if a := t.Annotations; a != nil && (a.ReadOnlyHint || a.IdempotentHint) {
+			cs.rc.idempotentTools[t.Name] = true
+		} else {
+			delete(cs.rc.idempotentTools, t.Name)
+		}
+	}
+}
+
+// noteSubscription records a resource subscription, to be restored when
+// reconnecting.
+func (cs *ClientSession) noteSubscription(uri string, subscribed bool) {
+	if cs.rc == nil {
+		return
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if subscribed {
+		cs.rc.subscriptions[uri] = true
+	} else {
+		delete(cs.rc.subscriptions, uri)
+	}
+}
+
+// A reconnectBinder binds a new connection to an existing, reconnecting
+// ClientSession.
+type reconnectBinder struct {
+	cs   *ClientSession
+	conn *jsonrpc2.Connection
+}
+
+func (b *reconnectBinder) bind(mcpConn Connection, conn *jsonrpc2.Connection, _ *clientSessionState, _ func()) *ClientSession {
+	b.conn = conn
+	b.cs.mu.Lock()
+	b.cs.conn, b.cs.mcpConn = conn, mcpConn
+	b.cs.state = clientSessionState{}
+	b.cs.mu.Unlock()
+	return b.cs
+}
+
+func (b *reconnectBinder) disconnect(cs *ClientSession) {
+	if !cs.connDone(b.conn) {
+		cs.client.removeSession(cs)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A killableTransport connects to a server over a new in-memory pipe each
+// time it is connected, and can break the current pipe.
+type killableTransport struct {
+	server *Server
+
+	mu       sync.Mutex
+	conn     net.Conn // server side of the current pipe
+	connects int
+	fail     bool // if set, Connect fails
+}
+
+func (t *killableTransport) Connect(ctx context.Context) (Connection, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.fail {
+		return nil, errors.New("connection refused")
+	}
+	c1, c2 := net.Pipe()
+	if _, err := t.server.Connect(ctx, &InMemoryTransport{c1}, nil); err != nil {
+		return nil, err
+	}
+	t.conn = c1
+	t.connects++
+	return newIOConn(c2), nil
+}
+
+func (t *killableTransport) kill() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.conn.Close()
+}
+
+func TestReconnect(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		mu            sync.Mutex
+		subscriptions []string
+		rootsChanged  int
+	)
+	server := NewServer(testImpl, &ServerOptions{
+		SubscribeHandler: func(_ context.Context, req *SubscribeRequest) error {
+			mu.Lock()
+			defer mu.Unlock()
+			subscriptions = append(subscriptions, req.Params.URI)
+			return nil
+		},
+		UnsubscribeHandler: func(context.Context, *UnsubscribeRequest) error { return nil },
+		RootsListChangedHandler: func(context.Context, *RootsListChangedRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			rootsChanged++
+		},
+	})
+	transport := &killableTransport{server: server}
+
+	// Each of these tools breaks the connection the first time it is called.
+	var calls atomic.Int32
+	flaky := func(ctx context.Context, req *CallToolRequest, _ any) (*CallToolResult, any, error) {
+		if calls.Add(1) == 1 {
+			transport.kill()
+			<-ctx.Done()
+		}
+		return &CallToolResult{}, nil, nil
+	}
+	AddTool(server, &Tool{Name: "idempotent", Annotations: &ToolAnnotations{IdempotentHint: true}}, flaky)
+	AddTool(server, &Tool{Name: "other"}, flaky)
+	// This tool breaks the connection every time it is called.
+	var brokenCalls atomic.Int32
+	AddTool(server, &Tool{Name: "broken", Annotations: &ToolAnnotations{ReadOnlyHint: true}}, func(ctx context.Context, req *CallToolRequest, _ any) (*CallToolResult, any, error) {
+		brokenCalls.Add(1)
+		transport.kill()
+		<-ctx.Done()
+		return nil, nil, ctx.Err()
+	})
+
+	var events []ReconnectEventKind
+	client := NewClient(testImpl, nil)
+	client.AddRoots(&Root{URI: "file:///root"})
+	cs, err := client.Connect(ctx, transport, &ClientSessionOptions{
+		Reconnect: &ReconnectOptions{
+			InitialDelay: time.Millisecond,
+			EventHandler: func(e ReconnectEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				events = append(events, e.Kind)
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	if _, err := cs.ListTools(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Subscribe(ctx, &SubscribeParams{URI: "test://resource"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("retry", func(t *testing.T) {
+		calls.Store(0)
+		if _, err := cs.CallTool(ctx, &CallToolParams{Name: "idempotent"}); err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if want := []string{"test://resource", "test://resource"}; !slices.Equal(subscriptions, want) {
+			t.Errorf("got subscriptions %v, want %v", subscriptions, want)
+		}
+		if rootsChanged != 1 {
+			t.Errorf("got %d roots/list_changed notifications, want 1", rootsChanged)
+		}
+		if want := []ReconnectEventKind{ReconnectStarted, Reconnected}; !slices.Equal(events, want) {
+			t.Errorf("got events %v, want %v", events, want)
+		}
+	})
+
+	t.Run("no retry", func(t *testing.T) {
+		calls.Store(0)
+		if _, err := cs.CallTool(ctx, &CallToolParams{Name: "other"}); err == nil {
+			t.Fatal("CallTool succeeded unexpectedly")
+		}
+		// The session is still usable.
+		if _, err := cs.CallTool(ctx, &CallToolParams{Name: "other"}); err != nil {
+			t.Fatalf("CallTool after reconnection failed: %v", err)
+		}
+		transport.mu.Lock()
+		connects := transport.connects
+		transport.mu.Unlock()
+		if got, want := connects, 3; got != want {
+			t.Errorf("got %d connections, want %d", got, want)
+		}
+	})
+
+	t.Run("retry limit", func(t *testing.T) {
+		// The call is retried once, though reconnection succeeds every time.
+		if _, err := cs.CallTool(ctx, &CallToolParams{Name: "broken"}); err == nil {
+			t.Fatal("CallTool succeeded unexpectedly")
+		}
+		if got, want := brokenCalls.Load(), int32(2); got != want {
+			t.Errorf("got %d calls, want %d", got, want)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		transport.mu.Lock()
+		transport.fail = true
+		transport.mu.Unlock()
+		transport.kill()
+		if err := cs.Wait(); !errors.Is(err, ErrConnectionClosed) {
+			t.Errorf("Wait() = %v, want ErrConnectionClosed", err)
+		}
+		if err := cs.Ping(ctx, nil); !errors.Is(err, ErrConnectionClosed) {
+			t.Errorf("Ping() = %v, want ErrConnectionClosed", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if got := events[len(events)-1]; got != ReconnectFailed {
+			t.Errorf("last event = %v, want ReconnectFailed", got)
+		}
+	})
+}
+
+func TestReconnectStreamable(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, nil)
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	events := make(chan ReconnectEventKind, 10)
+	client := NewClient(testImpl, nil)
+	cs, err := client.Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL}, &ClientSessionOptions{
+		Reconnect: &ReconnectOptions{
+			InitialDelay: time.Millisecond,
+			EventHandler: func(e ReconnectEvent) { events <- e.Kind },
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	oldID := cs.ID()
+
+	// Expire the session on the server, which then responds to its requests
+	// with 404 Not Found.
+	for ss := range server.Sessions() {
+		ss.Close()
+	}
+	// The ping fails with the expired session, and is retried on a new one.
+	if err := cs.Ping(ctx, nil); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if id := cs.ID(); id == "" || id == oldID {
+		t.Errorf("session ID after reconnecting = %q, want a new ID (old %q)", id, oldID)
+	}
+	for _, want := range []ReconnectEventKind{ReconnectStarted, Reconnected} {
+		select {
+		case got := <-events:
+			if got != want {
+				t.Errorf("got event %v, want %v", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %v", want)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/internal/jsonrpc2"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// Directions of a [RecordedMessage].
+const (
+	RecordSent     = "send" // sent by the recording party
+	RecordReceived = "recv" // received by the recording party
+)
+
+// A RecordedMessage is a JSON-RPC message recorded by a [RecordingTransport].
+type RecordedMessage struct {
+	// Direction is [RecordSent] or [RecordReceived].
+	Direction string `json:"dir"`
+	// Time is the time since the connection was established.
+	Time time.Duration `json:"time"`
+	// Message is the JSON-RPC message, or batch of messages.
+	Message json.RawMessage `json:"message"`
+}
+
+// A RecordingTransport is a [Transport] that delegates to another transport,
+// recording the messages sent and received on the connection.
+//
+// Each message is written to Writer as a [RecordedMessage], one JSON value
+// per line. Use [ReadRecording] to read the recording, and [ReplayTransport]
+// to play it back.
+//
+// If a message can't be recorded, recording stops, and the connection's Close
+// method returns the error, so that an incomplete recording isn't mistaken
+// for a complete one. The messages themselves are unaffected.
+type RecordingTransport struct {
+	Transport Transport
+	Writer    io.Writer
+}
+
+// Connect connects the underlying transport, returning a [Connection] that
+// records messages to the configured destination.
+func (t *RecordingTransport) Connect(ctx context.Context) (Connection, error) {
+	delegate, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn := &recordingConn{delegate: delegate, w: t.Writer, start: time.Now()}
+	// Preserve the ability of the delegate to observe the session state.
+	switch delegate.(type) {
+	case clientConnection:
+		return &recordingClientConn{conn}, nil
+	case serverConnection:
+		return &recordingServerConn{conn}, nil
+	}
+	return conn, nil
+}
+
+type recordingConn struct {
+	delegate Connection
+	start    time.Time
+
+	mu  sync.Mutex
+	w   io.Writer
+	err error // the first recording error
+}
+
+type recordingClientConn struct{ *recordingConn }
+
+func (c *recordingClientConn) sessionUpdated(state clientSessionState) {
+	c.delegate.(clientConnection).sessionUpdated(state)
+}
+
+type recordingServerConn struct{ *recordingConn }
+
+func (c *recordingServerConn) sessionUpdated(state ServerSessionState) {
+	c.delegate.(serverConnection).sessionUpdated(state)
+}
+
+func (c *recordingConn) SessionID() string { return c.delegate.SessionID() }
+
+func (c *recordingConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	msg, err := c.delegate.Read(ctx)
+	if err == nil {
+		c.record(RecordReceived, msg)
+	}
+	return msg, err
+}
+
+func (c *recordingConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	// Record before writing, so that a request is recorded before its
+	// response.
+	c.record(RecordSent, msg)
+	return c.delegate.Write(ctx, msg)
+}
+
+func (c *recordingConn) Close() error {
+	err := c.delegate.Close()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return errors.Join(err, fmt.Errorf("recording: %w", c.err))
+	}
+	return err
+}
+
+func (c *recordingConn) record(dir string, msg jsonrpc.Message) {
+	data, encErr := jsonrpc2.EncodeMessage(msg)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return // recording has stopped
+	}
+	if encErr != nil {
+		c.err = encErr
+		return
+	}
+	// Take the time under the lock, so that times are ordered.
+	line, err := json.Marshal(RecordedMessage{Direction: dir, Time: time.Since(c.start), Message: data})
+	if err == nil {
+		_, err = c.w.Write(append(line, '\n'))
+	}
+	c.err = err
+}
+
+// ReadRecording reads the messages written by a [RecordingTransport].
+func ReadRecording(r io.Reader) ([]*RecordedMessage, error) {
+	var msgs []*RecordedMessage
+	dec := json.NewDecoder(r)
+	for {
+		var m RecordedMessage
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				return msgs, nil
+			}
+			return nil, fmt.Errorf("reading recording: %w", err)
+		}
+		if m.Direction != RecordSent && m.Direction != RecordReceived {
+			return nil, fmt.Errorf("reading recording: message %d has invalid direction %q", len(msgs), m.Direction)
+		}
+		msgs = append(msgs, &m)
+	}
+}
+
+// A ReplayTransport is a [Transport] that plays back one side of a recording
+// made by a [RecordingTransport], so that the other side can be tested
+// without its original peer.
+//
+// The connection plays the peer of the recording party: it sends the
+// received messages of the recording, in response to the sent messages. For
+// example, a recording of a client session with a server can be used to
+// replay that server's messages to a client under test.
+//
+// Each message from the party under test is matched with the first
+// unmatched sent message in the recording with the same method and params,
+// ignoring _meta. Responses are matched by ID. Each received message in the
+// recording is played once the last sent message preceding it has been
+// matched, along with the request that it answers, if it is a response.
+// The IDs of responses are rewritten to match the request under test. If a
+// call cannot be matched, it is answered with an error.
+//
+// Timing in the recording is ignored: messages are played as soon as
+// possible.
+type ReplayTransport struct {
+	Recording []*RecordedMessage
+}
+
+// Connect implements the [Transport] interface.
+func (t *ReplayTransport) Connect(context.Context) (Connection, error) {
+	c := &replayConn{
+		ready:  make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+	lastSent := -1
+	for i, rm := range t.Recording {
+		msgs, _, err := readBatch(rm.Message)
+		if err != nil {
+			return nil, fmt.Errorf("recorded message %d: %w", i, err)
+		}
+		for _, msg := range msgs {
+			if rm.Direction == RecordSent {
+				c.sent = append(c.sent, &replaySent{msg: msg})
+				continue
+			}
+			rr := &replayReceived{msg: msg, after: lastSent, request: -1}
+			if resp, ok := msg.(*jsonrpc.Response); ok {
+				rr.request = c.findSentCall(resp.ID)
+			}
+			c.received = append(c.received, rr)
+		}
+		if rm.Direction == RecordSent {
+			lastSent = len(c.sent) - 1
+		}
+	}
+	c.mu.Lock()
+	c.playReady()
+	c.mu.Unlock()
+	return c, nil
+}
+
+type replayConn struct {
+	ready     chan struct{} // signaled when the queue is non-empty
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	mu       sync.Mutex
+	sent     []*replaySent
+	received []*replayReceived
+	queue    []jsonrpc.Message // messages to be read
+}
+
+// replaySent is a sent message in the recording.
+type replaySent struct {
+	msg     jsonrpc.Message
+	matched bool
+	liveID  jsonrpc.ID // for calls, the ID of the matching call under test
+}
+
+// replayReceived is a received message in the recording.
+type replayReceived struct {
+	msg     jsonrpc.Message
+	after   int // index of the last sent message preceding msg, or -1
+	request int // for responses, the index of the call it answers, or -1
+	played  bool
+}
+
+// findSentCall returns the index of the last sent call with the given ID,
+// or -1.
+func (c *replayConn) findSentCall(id jsonrpc.ID) int {
+	for i := len(c.sent) - 1; i >= 0; i-- {
+		if req, ok := c.sent[i].msg.(*jsonrpc.Request); ok && req.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *replayConn) SessionID() string { return "" }
+
+func (c *replayConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	for {
+		c.mu.Lock()
+		if len(c.queue) > 0 {
+			msg := c.queue[0]
+			c.queue = c.queue[1:]
+			if len(c.queue) > 0 {
+				c.signal()
+			}
+			c.mu.Unlock()
+			return msg, nil
+		}
+		c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.closed:
+			return nil, io.EOF
+		case <-c.ready:
+		}
+	}
+}
+
+func (c *replayConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	select {
+	case <-c.closed:
+		return ErrConnectionClosed
+	default:
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := c.match(msg)
+	if i < 0 {
+		if req, ok := msg.(*jsonrpc.Request); ok && req.IsCall() {
+			err := jsonrpc2.NewError(jsonrpc.CodeInternalError, fmt.Sprintf("no recorded %q request with matching params", req.Method))
+			resp, _ := jsonrpc2.NewResponse(req.ID, nil, err)
+			c.push(resp)
+		}
+		return nil
+	}
+	c.sent[i].matched = true
+	if req, ok := msg.(*jsonrpc.Request); ok {
+		c.sent[i].liveID = req.ID
+	}
+	c.playReady()
+	return nil
+}
+
+// match returns the index of the first unmatched sent message in the
+// recording that matches msg, or -1.
+func (c *replayConn) match(msg jsonrpc.Message) int {
+	for i, s := range c.sent {
+		if s.matched {
+			continue
+		}
+		switch msg := msg.(type) {
+		case *jsonrpc.Request:
+			if req, ok := s.msg.(*jsonrpc.Request); ok && req.Method == msg.Method && req.IsCall() == msg.IsCall() && sameParams(req.Params, msg.Params) {
+				return i
+			}
+		case *jsonrpc.Response:
+			if resp, ok := s.msg.(*jsonrpc.Response); ok && resp.ID == msg.ID {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// playReady queues the received messages in the recording that are ready to
+// be played, in order.
+func (c *replayConn) playReady() {
+	matched := func(i int) bool { return i < 0 || c.sent[i].matched }
+	for _, r := range c.received {
+		if r.played || !matched(r.after) || !matched(r.request) {
+			continue
+		}
+		r.played = true
+		msg := r.msg
+		if resp, ok := msg.(*jsonrpc.Response); ok && r.request >= 0 {
+			resp2 := *resp
+			resp2.ID = c.sent[r.request].liveID
+			msg = &resp2
+		}
+		c.push(msg)
+	}
+}
+
+// push queues msg to be read. It must be called with c.mu held.
+func (c *replayConn) push(msg jsonrpc.Message) {
+	c.queue = append(c.queue, msg)
+	c.signal()
+}
+
+func (c *replayConn) signal() {
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (c *replayConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+// sameParams reports whether the JSON params x and y are equivalent,
+// ignoring _meta.
+func sameParams(x, y json.RawMessage) bool {
+	decode := func(data json.RawMessage) (any, error) {
+		var v any
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &v); err != nil {
+				return nil, err
+			}
+		}
+		if m, ok := v.(map[string]any); ok {
+			delete(m, "_meta")
+		}
+		if v == nil {
+			v = map[string]any{}
+		}
+		return v, nil
+	}
+	vx, errx := decode(x)
+	vy, erry := decode(y)
+	if errx != nil || erry != nil {
+		return false
+	}
+	return reflect.DeepEqual(vx, vy)
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	ctx := context.Background()
+
+	// Record a session with a server that makes a sampling request.
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "greet"}, func(ctx context.Context, req *CallToolRequest, args struct{ Name string }) (*CallToolResult, any, error) {
+		res, err := req.Session.CreateMessage(ctx, &CreateMessageParams{Messages: []*SamplingMessage{{Role: "user", Content: &TextContent{Text: args.Name}}}})
+		if err != nil {
+			return nil, nil, err
+		}
+		return &CallToolResult{Content: []Content{&TextContent{Text: "hi " + res.Content.(*TextContent).Text}}}, nil, nil
+	})
+	newClient := func(sample string) *Client {
+		return NewClient(testImpl, &ClientOptions{
+			CreateMessageHandler: func(context.Context, *CreateMessageRequest) (*CreateMessageResult, error) {
+				return &CreateMessageResult{Model: "m", Role: "assistant", Content: &TextContent{Text: sample}}, nil
+			},
+		})
+	}
+	ct, st := NewInMemoryTransports()
+	ss, err := server.Connect(ctx, st, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	cs, err := newClient("gopher").Connect(ctx, &RecordingTransport{Transport: ct, Writer: &buf}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "x"}}); err != nil {
+		t.Fatal(err)
+	}
+	cs.Close()
+	ss.Wait()
+
+	recording, err := ReadRecording(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recording) == 0 || recording[0].Direction != RecordSent {
+		t.Fatalf("recording does not start with a sent message: %v", recording)
+	}
+
+	// Replay the server side to a new client. The recorded tool result is
+	// played once the client has answered the sampling request, even though
+	// its answer differs.
+	cs, err = newClient("other").Connect(ctx, &ReplayTransport{Recording: recording}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	res, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Content[0].(*TextContent).Text, "hi gopher"; got != want {
+		t.Errorf("replayed CallTool result = %q, want %q", got, want)
+	}
+
+	// Calls that were not recorded fail.
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "greet", Arguments: map[string]any{"Name": "y"}}); err == nil {
+		t.Error("unrecorded CallTool succeeded unexpectedly")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestRecordingError(t *testing.T) {
+	// A failure to record doesn't affect the session, but is reported by Close.
+	ctx := context.Background()
+	ct, st := NewInMemoryTransports()
+	if _, err := NewServer(testImpl, nil).Connect(ctx, st, nil); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := NewClient(testImpl, nil).Connect(ctx, &RecordingTransport{Transport: ct, Writer: failingWriter{}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Ping(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Close(); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Close() = %v, want recording error", err)
+	}
+}
@@ -24,12 +24,14 @@ import (
 type serverResource struct {
 	resource *Resource
 	handler  ResourceHandler
+	scopes   []string // see [Server.RequireResourceScopes]
 }
 
 // A serverResourceTemplate associates a ResourceTemplate with its handler.
 type serverResourceTemplate struct {
 	resourceTemplate *ResourceTemplate
 	handler          ResourceHandler
+	scopes           []string // see [Server.RequireResourceScopes]
 }
 
 // A ResourceHandler is a function that reads a resource.
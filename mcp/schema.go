@@ -0,0 +1,227 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// A SchemaRegistry customizes the JSON schemas that [AddTool] infers from Go
+// types, and caches them, so that tools sharing types infer them only once.
+// See [ServerOptions.SchemaRegistry].
+//
+// A customized type is described by its customized schema wherever it
+// appears, as with [jsonschema.ForOptions.TypeSchemas]. In particular, a
+// pointer to a customized type has the same schema as the type, without
+// allowing null.
+//
+// The zero SchemaRegistry has no customizations and is ready to use. It is
+// safe for concurrent use. Customizations affect only tools added after they
+// are registered.
+type SchemaRegistry struct {
+	mu                   sync.Mutex
+	typeSchemas          map[reflect.Type]*jsonschema.Schema
+	enums                map[reflect.Type]func() ([]any, error)
+	descriptions         map[reflect.Type]map[string]string // field JSON name, or "" for the type, to description
+	additionalProperties bool
+	cache                map[reflect.Type]*inferredSchema
+}
+
+// An inferredSchema is a cached result of inference.
+type inferredSchema struct {
+	schema   *jsonschema.Schema
+	resolved *jsonschema.Resolved
+	err      error
+}
+
+// defaultSchemaRegistry caches the schemas of servers without a
+// [ServerOptions.SchemaRegistry].
+var defaultSchemaRegistry SchemaRegistry
+
+// RegisterTypeSchema sets the schema of the type T, for types whose inferred
+// schema is wrong or not precise enough: for example, a type with a custom
+// JSON encoding such as a UUID or a decimal number.
+func RegisterTypeSchema[T any](r *SchemaRegistry, schema *jsonschema.Schema) {
+	r.update(func() { setEntry(&r.typeSchemas, reflect.TypeFor[T](), schema) })
+}
+
+// RegisterEnum restricts the values of the type T to those returned by
+// values. The values are those of the enum keyword of T's inferred schema.
+// The values function is called when a schema using T is first inferred.
+func RegisterEnum[T any](r *SchemaRegistry, values func() []T) {
+	r.update(func() {
+		setEntry(&r.enums, reflect.TypeFor[T](), func() ([]any, error) {
+			var enum []any
+			for _, v := range values() {
+				// Use the JSON form of the value, which is what validation
+				// compares against.
+				var jv any
+				if err := remarshal(v, &jv); err != nil {
+					return nil, fmt.Errorf("enum value %v: %w", v, err)
+				}
+				enum = append(enum, jv)
+			}
+			return enum, nil
+		})
+	})
+}
+
+// RegisterDescription sets the description of a property of the schema of the
+// struct type T, overriding any jsonschema struct tag. The property is named
+// by field, its JSON name. If field is empty, it sets the description of the
+// schema of T itself.
+func RegisterDescription[T any](r *SchemaRegistry, field, description string) {
+	r.update(func() {
+		t := reflect.TypeFor[T]()
+		if r.descriptions[t] == nil {
+			setEntry(&r.descriptions, t, map[string]string{})
+		}
+		r.descriptions[t][field] = description
+	})
+}
+
+// SetAdditionalProperties sets whether inferred schemas allow additional
+// properties in objects. By default, the schemas of structs do not.
+func (r *SchemaRegistry) SetAdditionalProperties(allow bool) {
+	r.update(func() { r.additionalProperties = allow })
+}
+
+// update calls f with r's lock held, and invalidates the cache.
+func (r *SchemaRegistry) update(f func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f()
+	r.cache = nil
+}
+
+func setEntry[V any](m *map[reflect.Type]V, t reflect.Type, v V) {
+	if *m == nil {
+		*m = make(map[reflect.Type]V)
+	}
+	(*m)[t] = v
+}
+
+// schemaFor returns the schema inferred for t, and its resolution. The schema
+// is a copy, which the caller may modify. A nil registry uses
+// defaultSchemaRegistry.
+func (r *SchemaRegistry) schemaFor(t reflect.Type) (*jsonschema.Schema, *jsonschema.Resolved, error) {
+	if r == nil {
+		r = &defaultSchemaRegistry
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	inf, ok := r.cache[t]
+	if !ok {
+		inf = &inferredSchema{}
+		inf.schema, inf.err = r.infer(t)
+		if inf.err == nil {
+			inf.resolved, inf.err = inf.schema.Resolve(&jsonschema.ResolveOptions{ValidateDefaults: true})
+		}
+		setEntry(&r.cache, t, inf)
+	}
+	if inf.err != nil {
+		return nil, nil, inf.err
+	}
+	return inf.schema.CloneSchemas(), inf.resolved, nil
+}
+
+// infer infers the schema of t. It is called with r.mu held.
+func (r *SchemaRegistry) infer(t reflect.Type) (*jsonschema.Schema, error) {
+	// Compute the schemas of the customized types reachable from t, so that
+	// inference uses them.
+	schemas := make(map[reflect.Type]*jsonschema.Schema)
+	if err := r.customize(t, schemas, map[reflect.Type]bool{}); err != nil {
+		return nil, err
+	}
+	s, err := jsonschema.ForType(t, &jsonschema.ForOptions{TypeSchemas: schemas})
+	if err != nil {
+		return nil, err
+	}
+	if r.additionalProperties {
+		allowAdditionalProperties(s)
+	}
+	return s, nil
+}
+
+// customize adds to schemas the schemas of the customized types reachable
+// from t, dependencies first.
+func (r *SchemaRegistry) customize(t reflect.Type, schemas map[reflect.Type]*jsonschema.Schema, seen map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if seen[t] {
+		return nil // a cycle, which inference reports
+	}
+	seen[t] = true
+	if s := r.typeSchemas[t]; s != nil {
+		schemas[t] = s
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if err := r.customize(t.Elem(), schemas, seen); err != nil {
+			return err
+		}
+	case reflect.Struct:
+		for _, f := range reflect.VisibleFields(t) {
+			if err := r.customize(f.Type, schemas, seen); err != nil {
+				return err
+			}
+		}
+	}
+	enum := r.enums[t]
+	descs := r.descriptions[t]
+	if enum == nil && descs == nil {
+		return nil
+	}
+	s, err := jsonschema.ForType(t, &jsonschema.ForOptions{TypeSchemas: schemas})
+	if err != nil {
+		return err
+	}
+	if enum != nil {
+		if s.Enum, err = enum(); err != nil {
+			return fmt.Errorf("%s: %w", t, err)
+		}
+	}
+	for field, desc := range descs {
+		if field == "" {
+			s.Description = desc
+			continue
+		}
+		ps := s.Properties[field]
+		if ps == nil {
+			return fmt.Errorf("%s: no property %q to describe", t, field)
+		}
+		ps.Description = desc
+	}
+	schemas[t] = s
+	return nil
+}
+
+// allowAdditionalProperties removes the restriction on additional properties
+// from the object schemas of s, as produced by inference.
+func allowAdditionalProperties(s *jsonschema.Schema) {
+	if s == nil {
+		return
+	}
+	if ap := s.AdditionalProperties; ap != nil && isFalseSchema(ap) {
+		s.AdditionalProperties = nil
+	}
+	allowAdditionalProperties(s.Items)
+	allowAdditionalProperties(s.AdditionalProperties)
+	for _, ps := range s.Properties {
+		allowAdditionalProperties(ps)
+	}
+}
+
+// isFalseSchema reports whether s is the schema {"not": {}}, which inference
+// uses for the false schema.
+func isFalseSchema(s *jsonschema.Schema) bool {
+	return reflect.DeepEqual(s, &jsonschema.Schema{Not: &jsonschema.Schema{}})
+}
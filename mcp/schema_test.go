@@ -0,0 +1,119 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+type (
+	color   string
+	orderID [16]byte
+	item    struct {
+		ID    orderID `json:"id"`
+		Color color   `json:"color"`
+	}
+	order struct {
+		Items []item `json:"items"`
+		Note  string `json:"note,omitempty" jsonschema:"a note"`
+	}
+)
+
+func TestSchemaRegistry(t *testing.T) {
+	var r SchemaRegistry
+	RegisterTypeSchema[orderID](&r, &jsonschema.Schema{Type: "string", Format: "uuid"})
+	RegisterEnum(&r, func() []color { return []color{"red", "green"} })
+	RegisterDescription[order](&r, "", "an order")
+	RegisterDescription[order](&r, "note", "a note for the shop")
+	RegisterDescription[item](&r, "color", "the color of the item")
+	r.SetAdditionalProperties(true)
+
+	got, _, err := r.schemaFor(reflect.TypeFor[order]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &jsonschema.Schema{
+		Type:        "object",
+		Description: "an order",
+		Required:    []string{"items"},
+		Properties: map[string]*jsonschema.Schema{
+			"items": {
+				Type: "array",
+				Items: &jsonschema.Schema{
+					Type:     "object",
+					Required: []string{"id", "color"},
+					Properties: map[string]*jsonschema.Schema{
+						"id":    {Type: "string", Format: "uuid"},
+						"color": {Type: "string", Enum: []any{"red", "green"}, Description: "the color of the item"},
+					},
+				},
+			},
+			"note": {Type: "string", Description: "a note for the shop"},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(jsonschema.Schema{})); diff != "" {
+		t.Errorf("schema mismatch (-want +got):\n%s", diff)
+	}
+
+	// Schemas are cached, and callers get copies.
+	got.Description = "changed"
+	again, _, err := r.schemaFor(reflect.TypeFor[order]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Description != "an order" {
+		t.Errorf("cached schema was modified: description %q", again.Description)
+	}
+	if len(r.cache) != 1 {
+		t.Errorf("got %d cached schemas, want 1", len(r.cache))
+	}
+
+	RegisterDescription[item](&r, "size", "no such field")
+	if _, _, err := r.schemaFor(reflect.TypeFor[order]()); err == nil {
+		t.Error("describing a nonexistent property succeeded, want error")
+	}
+}
+
+func TestServerSchemaRegistry(t *testing.T) {
+	ctx := context.Background()
+	var r SchemaRegistry
+	RegisterEnum(&r, func() []color { return []color{"red", "green"} })
+	server := NewServer(testImpl, &ServerOptions{SchemaRegistry: &r})
+	AddTool(server, &Tool{Name: "paint"}, func(_ context.Context, _ *CallToolRequest, in item) (*CallToolResult, any, error) {
+		return &CallToolResult{Content: []Content{&TextContent{Text: string(in.Color)}}}, nil, nil
+	})
+	cs, _, _ := basicClientServerConnection(t, nil, server, nil)
+
+	res, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(res.Tools[0].InputSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if got := schema.Properties["color"].Enum; !reflect.DeepEqual(got, []any{"red", "green"}) {
+		t.Errorf("listed color enum %v, want [red green]", got)
+	}
+
+	args := map[string]any{"id": make([]int, 16), "color": "blue"}
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "paint", Arguments: args}); err == nil {
+		t.Error("calling with a color outside the enum succeeded, want error")
+	}
+	args["color"] = "red"
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "paint", Arguments: args}); err != nil {
+		t.Errorf("calling with a valid color: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// This file implements authorization of individual features by the scopes of
+// the caller's access token.
+
+// RequireToolScopes declares that calling the server's tool with the given
+// name requires an access token with all of the given scopes. If scopes is
+// empty, the tool requires no scopes. RequireToolScopes panics if the server
+// has no such tool.
+//
+// The scopes of the caller's token are taken from the [auth.TokenInfo] of the
+// request (see [RequestExtra]), as set by [auth.RequireBearerToken]. Callers
+// without the scopes do not see the tool in tools/list results, and their
+// calls fail with an [InsufficientScopeError]. In particular, callers without
+// a token can't use tools that require scopes.
+//
+// The scopes belong to the server's registration of the tool. A tool added
+// with [Server.AddTool] to replace one with the same name keeps its scopes,
+// until they are declared again; a removed tool takes them with it. Tools
+// added to a session (see [ServerSession.AddTool]) or provided by a
+// [ToolProvider] don't have required scopes, even if they have the name of a
+// server tool; their handlers can check the caller's scopes themselves.
+func (s *Server) RequireToolScopes(name string, scopes ...string) {
+	found := false
+	s.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
+		func() bool {
+			st, ok := s.tools.get(name)
+			if found = ok; !ok || slices.Equal(st.scopes, scopes) {
+				return false
+			}
+			st2 := *st
+			st2.scopes = slices.Clone(scopes)
+			s.tools.add(&st2)
+			return true
+		})
+	if !found {
+		panic(fmt.Sprintf("RequireToolScopes: no tool %q", name))
+	}
+}
+
+// RequirePromptScopes declares that getting the server's prompt with the
+// given name requires an access token with all of the given scopes, as for
+// [Server.RequireToolScopes].
+func (s *Server) RequirePromptScopes(name string, scopes ...string) {
+	found := false
+	s.changeAndNotify(notificationPromptListChanged, &PromptListChangedParams{},
+		func() bool {
+			sp, ok := s.prompts.get(name)
+			if found = ok; !ok || slices.Equal(sp.scopes, scopes) {
+				return false
+			}
+			sp2 := *sp
+			sp2.scopes = slices.Clone(scopes)
+			s.prompts.add(&sp2)
+			return true
+		})
+	if !found {
+		panic(fmt.Sprintf("RequirePromptScopes: no prompt %q", name))
+	}
+}
+
+// RequireResourceScopes declares that reading the server's resource with the
+// given URI requires an access token with all of the given scopes, as for
+// [Server.RequireToolScopes]. The uri may also be the URI template of a
+// resource template, in which case the scopes are required to read any
+// resource matching the template.
+func (s *Server) RequireResourceScopes(uri string, scopes ...string) {
+	found := false
+	s.changeAndNotify(notificationResourceListChanged, &ResourceListChangedParams{},
+		func() bool {
+			if sr, ok := s.resources.get(uri); ok {
+				found = true
+				if slices.Equal(sr.scopes, scopes) {
+					return false
+				}
+				sr2 := *sr
+				sr2.scopes = slices.Clone(scopes)
+				s.resources.add(&sr2)
+				return true
+			}
+			if rt, ok := s.resourceTemplates.get(uri); ok {
+				found = true
+				if slices.Equal(rt.scopes, scopes) {
+					return false
+				}
+				rt2 := *rt
+				rt2.scopes = slices.Clone(scopes)
+				s.resourceTemplates.add(&rt2)
+				return true
+			}
+			return false
+		})
+	if !found {
+		panic(fmt.Sprintf("RequireResourceScopes: no resource or resource template %q", uri))
+	}
+}
+
+// missingScopes returns the given required scopes if ti does not grant them
+// all, or nil if the feature that requires them may be used.
+func missingScopes(required []string, ti *auth.TokenInfo) []string {
+	if !ti.HasScopes(required...) {
+		return required
+	}
+	return nil
+}
+
+// tokenInfo returns the token info of a request with the given extra
+// information, if any.
+func tokenInfo(extra *RequestExtra) *auth.TokenInfo {
+	if extra == nil {
+		return nil
+	}
+	return extra.TokenInfo
+}
+
+// scopeChallenge reports whether data encodes a response that failed with
+// [CodeInsufficientScope] and, if so, returns the WWW-Authenticate challenge
+// for it.
+func scopeChallenge(data []byte, resourceMetadataURL string) (string, bool) {
+	if !bytes.Contains(data, []byte(strconv.Itoa(CodeInsufficientScope))) {
+		return "", false // fast path
+	}
+	msg, err := jsonrpc.DecodeMessage(data)
+	if err != nil {
+		return "", false
+	}
+	resp, ok := msg.(*jsonrpc.Response)
+	if !ok {
+		return "", false
+	}
+	var werr *jsonrpc.Error
+	if !errors.As(resp.Error, &werr) || werr.Code != CodeInsufficientScope {
+		return "", false
+	}
+	var scopeData InsufficientScopeData
+	_ = json.Unmarshal(werr.Data, &scopeData) // the challenge is still useful without scopes
+	return auth.InsufficientScopeChallenge(scopeData.Scopes, resourceMetadataURL), true
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+func newScopedServer() *Server {
+	server := NewServer(testImpl, nil)
+	for _, name := range []string{"public", "admin"} {
+		AddTool(server, &Tool{Name: name}, func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+			return &CallToolResult{Content: []Content{&TextContent{Text: name}}}, nil, nil
+		})
+		server.AddPrompt(&Prompt{Name: name}, func(context.Context, *GetPromptRequest) (*GetPromptResult, error) {
+			return &GetPromptResult{}, nil
+		})
+		server.AddResource(&Resource{URI: "file:///" + name, Name: name}, func(_ context.Context, req *ReadResourceRequest) (*ReadResourceResult, error) {
+			return &ReadResourceResult{Contents: []*ResourceContents{{Text: name}}}, nil
+		})
+	}
+	server.AddResourceTemplate(&ResourceTemplate{URITemplate: "file:///secret/{name}", Name: "secret"}, func(_ context.Context, req *ReadResourceRequest) (*ReadResourceResult, error) {
+		return &ReadResourceResult{Contents: []*ResourceContents{{Text: "secret"}}}, nil
+	})
+	server.RequireToolScopes("admin", "admin")
+	server.RequirePromptScopes("admin", "admin")
+	server.RequireResourceScopes("file:///admin", "admin")
+	server.RequireResourceScopes("file:///secret/{name}", "admin")
+	return server
+}
+
+// checkScopedFeatures checks that the session sees only public features if
+// admin is unset, and all features otherwise.
+func checkScopedFeatures(t *testing.T, cs *ClientSession, admin bool) {
+	t.Helper()
+	ctx := context.Background()
+	want := []string{"public"}
+	if admin {
+		want = []string{"admin", "public"}
+	}
+	var tools, prompts, resources []string
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		tools = append(tools, tool.Name)
+	}
+	for prompt, err := range cs.Prompts(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		prompts = append(prompts, prompt.Name)
+	}
+	for resource, err := range cs.Resources(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		resources = append(resources, resource.Name)
+	}
+	for _, got := range [][]string{tools, prompts, resources} {
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("listed %v, want %v", got, want)
+		}
+	}
+	templates, err := cs.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(templates.ResourceTemplates) > 0; got != admin {
+		t.Errorf("resource templates listed: %t, want %t", got, admin)
+	}
+
+	checkErr := func(op string, err error) {
+		t.Helper()
+		if admin {
+			if err != nil {
+				t.Errorf("%s failed: %v", op, err)
+			}
+			return
+		}
+		var werr *jsonrpc.Error
+		if !errors.As(err, &werr) || werr.Code != CodeInsufficientScope {
+			t.Fatalf("%s: got error %v, want code %d", op, err, CodeInsufficientScope)
+		}
+		var data InsufficientScopeData
+		if err := json.Unmarshal(werr.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(data.Scopes, []string{"admin"}) || !strings.Contains(data.Challenge, `error="insufficient_scope"`) {
+			t.Errorf("%s: got error data %+v", op, data)
+		}
+	}
+	_, err = cs.CallTool(ctx, &CallToolParams{Name: "admin"})
+	checkErr("CallTool", err)
+	_, err = cs.GetPrompt(ctx, &GetPromptParams{Name: "admin"})
+	checkErr("GetPrompt", err)
+	_, err = cs.ReadResource(ctx, &ReadResourceParams{URI: "file:///admin"})
+	checkErr("ReadResource", err)
+	_, err = cs.ReadResource(ctx, &ReadResourceParams{URI: "file:///secret/x"})
+	checkErr("ReadResource (template)", err)
+
+	// Public features are always available.
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "public"}); err != nil {
+		t.Errorf("CallTool(public) failed: %v", err)
+	}
+}
+
+func TestRequireScopes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no token", func(t *testing.T) {
+		ct, st := NewInMemoryTransports()
+		ss, err := newScopedServer().Connect(ctx, st, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ss.Close()
+		cs, err := NewClient(testImpl, nil).Connect(ctx, ct, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cs.Close()
+		checkScopedFeatures(t, cs, false)
+	})
+
+	// The bearer token is a list of scopes, separated by "+".
+	verifier := func(_ context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+		return &auth.TokenInfo{Scopes: strings.Split(token, "+"), Expiration: time.Now().Add(time.Hour)}, nil
+	}
+	for _, jsonResponse := range []bool{false, true} {
+		server := newScopedServer()
+		handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+			JSONResponse:        jsonResponse,
+			ResourceMetadataURL: "https://example.com/.well-known/oauth-protected-resource",
+		})
+		httpServer := httptest.NewServer(mustNotPanic(t, auth.RequireBearerToken(verifier, nil)(handler)))
+		defer httpServer.Close()
+
+		for _, token := range []string{"read", "read+admin"} {
+			t.Run(fmt.Sprintf("jsonResponse=%t,token=%s", jsonResponse, token), func(t *testing.T) {
+				var forbidden atomic.Int32
+				httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					req.Header.Set("Authorization", "Bearer "+token)
+					resp, err := http.DefaultTransport.RoundTrip(req)
+					if err == nil && resp.StatusCode == http.StatusForbidden {
+						forbidden.Add(1)
+						if got, want := resp.Header.Get("WWW-Authenticate"), `Bearer error="insufficient_scope", scope="admin", resource_metadata="https://example.com/.well-known/oauth-protected-resource"`; got != want {
+							t.Errorf("WWW-Authenticate = %q, want %q", got, want)
+						}
+					}
+					return resp, err
+				})}
+				cs, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL, HTTPClient: httpClient}, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer cs.Close()
+				admin := token == "read+admin"
+				checkScopedFeatures(t, cs, admin)
+				// Each rejected call is answered with a challenge.
+				if got, want := forbidden.Load(), map[bool]int32{false: 4, true: 0}[admin]; got != want {
+					t.Errorf("got %d forbidden responses, want %d", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestRequireScopesRegistration(t *testing.T) {
+	ctx := context.Background()
+	handler := func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+		return &CallToolResult{}, nil, nil
+	}
+	callErr := func(server *Server, name string) error {
+		cs, _, cleanup := basicClientServerConnection(t, nil, server, nil)
+		defer cleanup()
+		_, err := cs.CallTool(ctx, &CallToolParams{Name: name})
+		return err
+	}
+
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "replaced"}, handler)
+	server.RequireToolScopes("replaced", "admin")
+
+	// A replaced tool keeps its scopes.
+	AddTool(server, &Tool{Name: "replaced"}, handler)
+	if err := callErr(server, "replaced"); err == nil {
+		t.Error("calling replaced tool: got nil error, want insufficient scope")
+	}
+	// Unless they are declared again.
+	server.RequireToolScopes("replaced")
+	if err := callErr(server, "replaced"); err != nil {
+		t.Errorf("calling replaced tool after clearing its scopes: %v", err)
+	}
+
+	// A removed tool takes its scopes with it.
+	server.RequireToolScopes("replaced", "admin")
+	server.RemoveTools("replaced")
+	AddTool(server, &Tool{Name: "replaced"}, handler)
+	if err := callErr(server, "replaced"); err != nil {
+		t.Errorf("calling replaced tool after re-adding it: %v", err)
+	}
+
+	// Scopes can only be declared for a registered tool.
+	defer func() {
+		if recover() == nil {
+			t.Error("RequireToolScopes for a missing tool did not panic")
+		}
+	}()
+	server.RequireToolScopes("missing", "admin")
+}
@@ -5,10 +5,7 @@
 package mcp
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
-	"encoding/gob"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,12 +15,14 @@ import (
 	"net/url"
 	"path/filepath"
 	"reflect"
+	"runtime/debug"
 	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/auth"
 	"github.com/modelcontextprotocol/go-sdk/internal/jsonrpc2"
 	"github.com/modelcontextprotocol/go-sdk/internal/util"
 	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
@@ -47,6 +46,10 @@ type Server struct {
 	tools                   *featureSet[*serverTool]
 	resources               *featureSet[*serverResource]
 	resourceTemplates       *featureSet[*serverResourceTemplate]
+	toolsets                *featureSet[*serverToolset]
+	promptProviders         []provider[*serverPrompt]
+	toolProviders           []provider[*serverTool]
+	resourceProviders       []provider[*serverResource]
 	sessions                []*ServerSession
 	sendingMethodHandler_   MethodHandler
 	receivingMethodHandler_ MethodHandler
@@ -70,12 +73,28 @@ type ServerOptions struct {
 	RootsListChangedHandler func(context.Context, *RootsListChangedRequest)
 	// If non-nil, called when "notifications/progress" is received.
 	ProgressNotificationHandler func(context.Context, *ProgressNotificationServerRequest)
+	// ProgressInterval is the minimum interval between progress notifications
+	// sent for a single request by a [Progress]. More frequent reports are
+	// coalesced.
+	//
+	// If zero, defaults to 100ms. If negative, reports are never coalesced.
+	ProgressInterval time.Duration
 	// If non-nil, called when "completion/complete" is received.
 	CompletionHandler func(context.Context, *CompleteRequest) (*CompleteResult, error)
 	// If non-zero, defines an interval for regular "ping" requests.
 	// If the peer fails to respond to pings originating from the keepalive check,
 	// the session is automatically closed.
 	KeepAlive time.Duration
+	// Timeouts holds default timeouts for requests sent by the server, keyed by
+	// method (for example, "sampling/createMessage"). See
+	// [ClientOptions.Timeouts] for details.
+	Timeouts map[string]time.Duration
+	// If set, the timeout of a request is restarted each time a progress
+	// notification for it is received. See [ClientOptions.ResetTimeoutOnProgress].
+	ResetTimeoutOnProgress bool
+	// If set, the deadline of a request is sent to the client in the request's
+	// _meta. See [ClientOptions.PropagateDeadlines].
+	PropagateDeadlines bool
 	// Function called when a client session subscribes to a resource.
 	SubscribeHandler func(context.Context, *SubscribeRequest) error
 	// Function called when a client session unsubscribes from a resource.
@@ -90,6 +109,48 @@ type ServerOptions struct {
 	// even if no tools have been registered.
 	HasTools bool
 
+	// CursorCodec encodes and decodes the cursors of paginated lists.
+	//
+	// If nil, cursors are signed with a random key shared by the servers of
+	// the process, so that clients can't forge them, and do not expire.
+	// Replicas of a server in other processes can't decode such cursors: give
+	// all replicas a [NewHMACCursorCodec] with a shared key, which can also
+	// make cursors expire. A [NewUnsignedCursorCodec] lets any server decode
+	// cursors, but also lets clients forge them.
+	CursorCodec CursorCodec
+
+	// ErrorRegistry, if non-nil, maps the errors of handlers to the errors
+	// reported to clients, such as JSON-RPC errors with specific codes, or
+	// tool errors. See [ErrorRegistry].
+	ErrorRegistry *ErrorRegistry
+
+	// SchemaRegistry, if non-nil, customizes the schemas that [AddTool]
+	// infers from Go types. See [SchemaRegistry].
+	SchemaRegistry *SchemaRegistry
+
+	// If non-nil, ToolFilter reports whether a session may see a tool.
+	// Tools it rejects are not listed to the session, and calls to them fail
+	// as if the tools did not exist. It applies to the tools of the server and
+	// of the session (see [ServerSession.AddTool]).
+	//
+	// The filter is called without the server's lock held, so it may call
+	// methods of the [Server]. It must be safe for concurrent use.
+	ToolFilter func(*ServerSession, *Tool) bool
+	// If non-nil, PromptFilter reports whether a session may see a prompt,
+	// as for ToolFilter.
+	PromptFilter func(*ServerSession, *Prompt) bool
+	// If non-nil, ResourceFilter reports whether a session may see a resource,
+	// as for ToolFilter.
+	ResourceFilter func(*ServerSession, *Resource) bool
+	// If non-nil, ResourceTemplateFilter reports whether a session may see a
+	// resource template, and read the resources that match it, as for
+	// ToolFilter.
+	ResourceTemplateFilter func(*ServerSession, *ResourceTemplate) bool
+	// If true, the server has tools that let the model list toolsets and
+	// enable them for its session, except for restricted toolsets (see
+	// [Toolset.Restricted]). See [Server.AddToolset].
+	ToolsetTools bool
+
 	// GetSessionID provides the next session ID to use for an incoming request.
 	// If nil, a default randomly generated ID will be used.
 	//
@@ -131,6 +192,10 @@ func NewServer(impl *Implementation, options *ServerOptions) *Server {
 		panic("UnsubscribeHandler requires SubscribeHandler")
 	}
 
+	if opts.CursorCodec == nil {
+		opts.CursorCodec = defaultCursorCodec()
+	}
+
 	if opts.GetSessionID == nil {
 		opts.GetSessionID = randText
 	}
@@ -139,17 +204,22 @@ func NewServer(impl *Implementation, options *ServerOptions) *Server {
 		opts.Logger = ensureLogger(nil)
 	}
 
-	return &Server{
+	s := &Server{
 		impl:                    impl,
 		opts:                    opts,
 		prompts:                 newFeatureSet(func(p *serverPrompt) string { return p.prompt.Name }),
 		tools:                   newFeatureSet(func(t *serverTool) string { return t.tool.Name }),
 		resources:               newFeatureSet(func(r *serverResource) string { return r.resource.URI }),
 		resourceTemplates:       newFeatureSet(func(t *serverResourceTemplate) string { return t.resourceTemplate.URITemplate }),
+		toolsets:                newFeatureSet(func(t *serverToolset) string { return t.toolset.Name }),
 		sendingMethodHandler_:   defaultSendingMethodHandler,
 		receivingMethodHandler_: defaultReceivingMethodHandler[*ServerSession],
 		resourceSubscriptions:   make(map[string]map[*ServerSession]bool),
 	}
+	if opts.ToolsetTools {
+		s.addToolsetTools()
+	}
+	return s
 }
 
 // AddPrompt adds a [Prompt] to the server, or replaces one with the same name.
@@ -159,14 +229,23 @@ func (s *Server) AddPrompt(p *Prompt, h PromptHandler) {
 	s.changeAndNotify(
 		notificationPromptListChanged,
 		&PromptListChangedParams{},
-		func() bool { s.prompts.add(&serverPrompt{p, h}); return true })
+		func() bool {
+			sp := &serverPrompt{prompt: p, handler: h}
+			if old, ok := s.prompts.get(p.Name); ok {
+				sp.scopes = old.scopes // see [Server.RequirePromptScopes]
+			}
+			s.prompts.add(sp)
+			return true
+		})
 }
 
 // RemovePrompts removes the prompts with the given names.
 // It is not an error to remove a nonexistent prompt.
 func (s *Server) RemovePrompts(names ...string) {
 	s.changeAndNotify(notificationPromptListChanged, &PromptListChangedParams{},
-		func() bool { return s.prompts.remove(names...) })
+		func() bool {
+			return s.prompts.remove(names...)
+		})
 }
 
 // AddTool adds a [Tool] to the server, or replaces one with the same name.
@@ -192,6 +271,24 @@ func (s *Server) RemovePrompts(names ...string) {
 // Most users should use the top-level function [AddTool], which handles all these
 // responsibilities.
 func (s *Server) AddTool(t *Tool, h ToolHandler) {
+	s.checkTool(t)
+	st := &serverTool{tool: t, handler: h}
+	// Assume there was a change, since add replaces existing tools.
+	// (It's possible a tool was replaced with an identical one, but not worth checking.)
+	// TODO: Batch these changes by size and time? The typescript SDK doesn't.
+	// TODO: Surface notify error here? best not, in case we need to batch.
+	s.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
+		func() bool {
+			if old, ok := s.tools.get(t.Name); ok {
+				st.scopes = old.scopes // see [Server.RequireToolScopes]
+			}
+			s.tools.add(st)
+			return true
+		})
+}
+
+// checkTool panics if t does not meet the requirements of [Server.AddTool].
+func (s *Server) checkTool(t *Tool) {
 	if err := validateToolName(t.Name); err != nil {
 		s.opts.Logger.Error(fmt.Sprintf("AddTool: invalid tool name %q: %v", t.Name, err))
 	}
@@ -230,16 +327,9 @@ func (s *Server) AddTool(t *Tool, h ToolHandler) {
 			}
 		}
 	}
-	st := &serverTool{tool: t, handler: h}
-	// Assume there was a change, since add replaces existing tools.
-	// (It's possible a tool was replaced with an identical one, but not worth checking.)
-	// TODO: Batch these changes by size and time? The typescript SDK doesn't.
-	// TODO: Surface notify error here? best not, in case we need to batch.
-	s.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
-		func() bool { s.tools.add(st); return true })
 }
 
-func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHandler, error) {
+func toolForErr[In, Out any](schemas *SchemaRegistry, t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHandler, error) {
 	tt := *t
 
 	// Special handling for an "any" input: treat as an empty object.
@@ -248,7 +338,7 @@ func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHan
 	}
 
 	var inputResolved *jsonschema.Resolved
-	if _, err := setSchema[In](&tt.InputSchema, &inputResolved); err != nil {
+	if _, err := setSchema[In](schemas, &tt.InputSchema, &inputResolved); err != nil {
 		return nil, nil, fmt.Errorf("input schema: %w", err)
 	}
 
@@ -263,7 +353,7 @@ func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHan
 	)
 	if t.OutputSchema != nil || reflect.TypeFor[Out]() != reflect.TypeFor[any]() {
 		var err error
-		elemZero, err = setSchema[Out](&tt.OutputSchema, &outputResolved)
+		elemZero, err = setSchema[Out](schemas, &tt.OutputSchema, &outputResolved)
 		if err != nil {
 			return nil, nil, fmt.Errorf("output schema: %v", err)
 		}
@@ -303,7 +393,7 @@ func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHan
 			}
 			// For regular errors, embed them in the tool result as per MCP spec
 			var errRes CallToolResult
-			errRes.setError(err)
+			errRes.SetError(err)
 			return &errRes, nil
 		}
 
@@ -354,7 +444,7 @@ func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHan
 
 // setSchema sets the schema and resolved schema corresponding to the type T.
 //
-// If sfield is nil, the schema is derived from T.
+// If sfield is nil, the schema is derived from T, as customized by schemas.
 //
 // Pointers are treated equivalently to non-pointers when deriving the schema.
 // If an indirection occurred to derive the schema, a non-nil zero value is
@@ -366,23 +456,22 @@ func toolForErr[In, Out any](t *Tool, h ToolHandlerFor[In, Out]) (*Tool, ToolHan
 //
 // TODO(rfindley): we really shouldn't ever return 'null' results. Maybe we
 // should have a jsonschema.Zero(schema) helper?
-func setSchema[T any](sfield *any, rfield **jsonschema.Resolved) (zero any, err error) {
-	var internalSchema *jsonschema.Schema
+func setSchema[T any](schemas *SchemaRegistry, sfield *any, rfield **jsonschema.Resolved) (zero any, err error) {
 	if *sfield == nil {
 		rt := reflect.TypeFor[T]()
 		if rt.Kind() == reflect.Pointer {
 			rt = rt.Elem()
 			zero = reflect.Zero(rt).Interface()
 		}
-		// TODO: we should be able to pass nil opts here.
-		internalSchema, err = jsonschema.ForType(rt, &jsonschema.ForOptions{})
-		if err == nil {
-			*sfield = internalSchema
+		schema, resolved, err := schemas.schemaFor(rt)
+		if err != nil {
+			return zero, err
 		}
-	} else if err := remarshal(*sfield, &internalSchema); err != nil {
-		return zero, err
+		*sfield, *rfield = schema, resolved
+		return zero, nil
 	}
-	if err != nil {
+	var internalSchema *jsonschema.Schema
+	if err := remarshal(*sfield, &internalSchema); err != nil {
 		return zero, err
 	}
 	*rfield, err = internalSchema.Resolve(&jsonschema.ResolveOptions{ValidateDefaults: true})
@@ -395,10 +484,10 @@ func setSchema[T any](sfield *any, rfield **jsonschema.Resolved) (zero any, err
 // In type parameter. Types are inferred from Go types, and property
 // descriptions are read from the 'jsonschema' struct tag. Internally, the SDK
 // uses the github.com/google/jsonschema-go package for inference and
-// validation. The In type argument must be a map or a struct, so that its
-// inferred JSON Schema has type "object", as required by the spec. As a
-// special case, if the In type is 'any', the tool's input schema is set to an
-// empty object schema value.
+// validation, and [ServerOptions.SchemaRegistry] customizes inference. The In
+// type argument must be a map or a struct, so that its inferred JSON Schema has
+// type "object", as required by the spec. As a special case, if the In type is
+// 'any', the tool's input schema is set to an empty object schema value.
 //
 // If the tool's output schema is nil, and the Out type is not 'any', the
 // output schema is set to the schema inferred from the Out type argument,
@@ -409,7 +498,7 @@ func setSchema[T any](sfield *any, rfield **jsonschema.Resolved) (zero any, err
 // tools to conform to the MCP spec. See [ToolHandlerFor] for a detailed
 // description of this automatic behavior.
 func AddTool[In, Out any](s *Server, t *Tool, h ToolHandlerFor[In, Out]) {
-	tt, hh, err := toolForErr(t, h)
+	tt, hh, err := toolForErr(s.opts.SchemaRegistry, t, h)
 	if err != nil {
 		panic(fmt.Sprintf("AddTool: tool %q: %v", t.Name, err))
 	}
@@ -420,7 +509,9 @@ func AddTool[In, Out any](s *Server, t *Tool, h ToolHandlerFor[In, Out]) {
 // It is not an error to remove a nonexistent tool.
 func (s *Server) RemoveTools(names ...string) {
 	s.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
-		func() bool { return s.tools.remove(names...) })
+		func() bool {
+			return s.tools.remove(names...)
+		})
 }
 
 // AddResource adds a [Resource] to the server, or replaces one with the same URI.
@@ -431,7 +522,11 @@ func (s *Server) AddResource(r *Resource, h ResourceHandler) {
 			if _, err := url.Parse(r.URI); err != nil {
 				panic(err) // url.Parse includes the URI in the error
 			}
-			s.resources.add(&serverResource{r, h})
+			sr := &serverResource{resource: r, handler: h}
+			if old, ok := s.resources.get(r.URI); ok {
+				sr.scopes = old.scopes // see [Server.RequireResourceScopes]
+			}
+			s.resources.add(sr)
 			return true
 		})
 }
@@ -440,7 +535,9 @@ func (s *Server) AddResource(r *Resource, h ResourceHandler) {
 // It is not an error to remove a nonexistent resource.
 func (s *Server) RemoveResources(uris ...string) {
 	s.changeAndNotify(notificationResourceListChanged, &ResourceListChangedParams{},
-		func() bool { return s.resources.remove(uris...) })
+		func() bool {
+			return s.resources.remove(uris...)
+		})
 }
 
 // AddResourceTemplate adds a [ResourceTemplate] to the server, or replaces one with the same URI.
@@ -453,7 +550,11 @@ func (s *Server) AddResourceTemplate(t *ResourceTemplate, h ResourceHandler) {
 			if err != nil {
 				panic(fmt.Errorf("URI template %q is invalid: %w", t.URITemplate, err))
 			}
-			s.resourceTemplates.add(&serverResourceTemplate{t, h})
+			rt := &serverResourceTemplate{resourceTemplate: t, handler: h}
+			if old, ok := s.resourceTemplates.get(t.URITemplate); ok {
+				rt.scopes = old.scopes // see [Server.RequireResourceScopes]
+			}
+			s.resourceTemplates.add(rt)
 			return true
 		})
 }
@@ -462,7 +563,9 @@ func (s *Server) AddResourceTemplate(t *ResourceTemplate, h ResourceHandler) {
 // It is not an error to remove a nonexistent resource.
 func (s *Server) RemoveResourceTemplates(uriTemplates ...string) {
 	s.changeAndNotify(notificationResourceListChanged, &ResourceListChangedParams{},
-		func() bool { return s.resourceTemplates.remove(uriTemplates...) })
+		func() bool {
+			return s.resourceTemplates.remove(uriTemplates...)
+		})
 }
 
 func (s *Server) capabilities() *ServerCapabilities {
@@ -472,13 +575,13 @@ func (s *Server) capabilities() *ServerCapabilities {
 	caps := &ServerCapabilities{
 		Logging: &LoggingCapabilities{},
 	}
-	if s.opts.HasTools || s.tools.len() > 0 {
+	if s.opts.HasTools || s.tools.len() > 0 || len(s.toolProviders) > 0 {
 		caps.Tools = &ToolCapabilities{ListChanged: true}
 	}
-	if s.opts.HasPrompts || s.prompts.len() > 0 {
+	if s.opts.HasPrompts || s.prompts.len() > 0 || len(s.promptProviders) > 0 {
 		caps.Prompts = &PromptCapabilities{ListChanged: true}
 	}
-	if s.opts.HasResources || s.resources.len() > 0 || s.resourceTemplates.len() > 0 {
+	if s.opts.HasResources || s.resources.len() > 0 || s.resourceTemplates.len() > 0 || len(s.resourceProviders) > 0 {
 		caps.Resources = &ResourceCapabilities{ListChanged: true}
 		if s.opts.SubscribeHandler != nil {
 			caps.Resources.Subscribe = true
@@ -522,13 +625,17 @@ func (s *Server) Sessions() iter.Seq[*ServerSession] {
 	return slices.Values(clients)
 }
 
-func (s *Server) listPrompts(_ context.Context, req *ListPromptsRequest) (*ListPromptsResult, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *Server) listPrompts(ctx context.Context, req *ListPromptsRequest) (*ListPromptsResult, error) {
 	if req.Params == nil {
 		req.Params = &ListPromptsParams{}
 	}
-	return paginateList(s.prompts, s.opts.PageSize, req.Params, &ListPromptsResult{}, func(res *ListPromptsResult, prompts []*serverPrompt) {
+	ti := tokenInfo(req.Extra)
+	visible := func(p *serverPrompt) bool { return missingScopes(p.scopes, ti) == nil }
+	filter := sessionFilter(s.opts.PromptFilter, req.Session, func(p *serverPrompt) *Prompt { return p.prompt })
+	sources := func() (featureList[*serverPrompt], []provider[*serverPrompt]) {
+		return sessionSet(req.Session, s.prompts, sessionPrompts), s.promptProviders
+	}
+	return paginateProvided(ctx, s, sources, visible, filter, req.Params, &ListPromptsResult{}, func(res *ListPromptsResult, prompts []*serverPrompt) {
 		res.Prompts = []*Prompt{} // avoid JSON null
 		for _, p := range prompts {
 			res.Prompts = append(res.Prompts, p.prompt)
@@ -538,25 +645,41 @@ func (s *Server) listPrompts(_ context.Context, req *ListPromptsRequest) (*ListP
 
 func (s *Server) getPrompt(ctx context.Context, req *GetPromptRequest) (*GetPromptResult, error) {
 	s.mu.Lock()
-	prompt, ok := s.prompts.get(req.Params.Name)
+	prompt, ok := sessionGet(req.Session, s.prompts, sessionPrompts, req.Params.Name)
+	providers := s.promptProviders
 	s.mu.Unlock()
 	if !ok {
+		var err error
+		if prompt, ok, err = getProvided(ctx, providers, req.Params.Name); err != nil {
+			return nil, err
+		}
+	}
+	if !ok || !shown(s.opts.PromptFilter, req.Session, prompt.prompt) {
 		// Return a proper JSON-RPC error with the correct error code
 		return nil, &jsonrpc.Error{
 			Code:    jsonrpc.CodeInvalidParams,
 			Message: fmt.Sprintf("unknown prompt %q", req.Params.Name),
 		}
 	}
+	if missing := missingScopes(prompt.scopes, tokenInfo(req.Extra)); missing != nil {
+		return nil, InsufficientScopeError(missing...)
+	}
 	return prompt.handler(ctx, req)
 }
 
-func (s *Server) listTools(_ context.Context, req *ListToolsRequest) (*ListToolsResult, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *Server) listTools(ctx context.Context, req *ListToolsRequest) (*ListToolsResult, error) {
 	if req.Params == nil {
 		req.Params = &ListToolsParams{}
 	}
-	return paginateList(s.tools, s.opts.PageSize, req.Params, &ListToolsResult{}, func(res *ListToolsResult, tools []*serverTool) {
+	ti := tokenInfo(req.Extra)
+	visible := func(t *serverTool) bool {
+		return s.toolsetEnabled(req.Session, t.toolset) && missingScopes(t.scopes, ti) == nil
+	}
+	filter := sessionFilter(s.opts.ToolFilter, req.Session, func(t *serverTool) *Tool { return t.tool })
+	sources := func() (featureList[*serverTool], []provider[*serverTool]) {
+		return sessionSet(req.Session, s.tools, sessionTools), s.toolProviders
+	}
+	return paginateProvided(ctx, s, sources, visible, filter, req.Params, &ListToolsResult{}, func(res *ListToolsResult, tools []*serverTool) {
 		res.Tools = []*Tool{} // avoid JSON null
 		for _, t := range tools {
 			res.Tools = append(res.Tools, t.tool)
@@ -566,15 +689,31 @@ func (s *Server) listTools(_ context.Context, req *ListToolsRequest) (*ListTools
 
 func (s *Server) callTool(ctx context.Context, req *CallToolRequest) (*CallToolResult, error) {
 	s.mu.Lock()
-	st, ok := s.tools.get(req.Params.Name)
+	st, ok := sessionGet(req.Session, s.tools, sessionTools, req.Params.Name)
+	providers := s.toolProviders
 	s.mu.Unlock()
 	if !ok {
+		var err error
+		if st, ok, err = getProvided(ctx, providers, req.Params.Name); err != nil {
+			return nil, err
+		}
+	}
+	if ok {
+		s.mu.Lock()
+		ok = s.toolsetEnabled(req.Session, st.toolset)
+		s.mu.Unlock()
+	}
+	if !ok || !shown(s.opts.ToolFilter, req.Session, st.tool) {
 		return nil, &jsonrpc.Error{
 			Code:    jsonrpc.CodeInvalidParams,
 			Message: fmt.Sprintf("unknown tool %q", req.Params.Name),
 		}
 	}
-	res, err := st.handler(ctx, req)
+	if missing := missingScopes(st.scopes, tokenInfo(req.Extra)); missing != nil {
+		return nil, InsufficientScopeError(missing...)
+	}
+	res, err := s.reportToolError(st.handler(ctx, req))
+	setMapped(ctx, err)
 	if err == nil && res != nil && res.Content == nil {
 		res2 := *res
 		res2.Content = []Content{} // avoid "null"
@@ -583,13 +722,17 @@ func (s *Server) callTool(ctx context.Context, req *CallToolRequest) (*CallToolR
 	return res, err
 }
 
-func (s *Server) listResources(_ context.Context, req *ListResourcesRequest) (*ListResourcesResult, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *Server) listResources(ctx context.Context, req *ListResourcesRequest) (*ListResourcesResult, error) {
 	if req.Params == nil {
 		req.Params = &ListResourcesParams{}
 	}
-	return paginateList(s.resources, s.opts.PageSize, req.Params, &ListResourcesResult{}, func(res *ListResourcesResult, resources []*serverResource) {
+	ti := tokenInfo(req.Extra)
+	visible := func(r *serverResource) bool { return missingScopes(r.scopes, ti) == nil }
+	filter := sessionFilter(s.opts.ResourceFilter, req.Session, func(r *serverResource) *Resource { return r.resource })
+	sources := func() (featureList[*serverResource], []provider[*serverResource]) {
+		return sessionSet(req.Session, s.resources, sessionResources), s.resourceProviders
+	}
+	return paginateProvided(ctx, s, sources, visible, filter, req.Params, &ListResourcesResult{}, func(res *ListResourcesResult, resources []*serverResource) {
 		res.Resources = []*Resource{} // avoid JSON null
 		for _, r := range resources {
 			res.Resources = append(res.Resources, r.resource)
@@ -597,13 +740,18 @@ func (s *Server) listResources(_ context.Context, req *ListResourcesRequest) (*L
 	})
 }
 
-func (s *Server) listResourceTemplates(_ context.Context, req *ListResourceTemplatesRequest) (*ListResourceTemplatesResult, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *Server) listResourceTemplates(ctx context.Context, req *ListResourceTemplatesRequest) (*ListResourceTemplatesResult, error) {
 	if req.Params == nil {
 		req.Params = &ListResourceTemplatesParams{}
 	}
-	return paginateList(s.resourceTemplates, s.opts.PageSize, req.Params, &ListResourceTemplatesResult{},
+	ti := tokenInfo(req.Extra)
+	visible := func(rt *serverResourceTemplate) bool { return missingScopes(rt.scopes, ti) == nil }
+	filter := sessionFilter(s.opts.ResourceTemplateFilter, req.Session, func(rt *serverResourceTemplate) *ResourceTemplate { return rt.resourceTemplate })
+	// Resource templates have no providers.
+	sources := func() (featureList[*serverResourceTemplate], []provider[*serverResourceTemplate]) {
+		return sessionSet(req.Session, s.resourceTemplates, sessionResourceTemplates), nil
+	}
+	return paginateProvided(ctx, s, sources, visible, filter, req.Params, &ListResourceTemplatesResult{},
 		func(res *ListResourceTemplatesResult, rts []*serverResourceTemplate) {
 			res.ResourceTemplates = []*ResourceTemplate{} // avoid JSON null
 			for _, rt := range rts {
@@ -616,12 +764,18 @@ func (s *Server) readResource(ctx context.Context, req *ReadResourceRequest) (*R
 	uri := req.Params.URI
 	// Look up the resource URI in the lists of resources and resource templates.
 	// This is a security check as well as an information lookup.
-	handler, mimeType, ok := s.lookupResourceHandler(uri)
+	handler, mimeType, missing, ok, err := s.lookupResourceHandler(ctx, req.Session, uri, tokenInfo(req.Extra))
+	if err != nil {
+		return nil, err
+	}
 	if !ok {
 		// Don't expose the server configuration to the client.
 		// Treat an unregistered resource the same as a registered one that couldn't be found.
 		return nil, ResourceNotFoundError(uri)
 	}
+	if missing != nil {
+		return nil, InsufficientScopeError(missing...)
+	}
 	res, err := handler(ctx, req)
 	if err != nil {
 		return nil, err
@@ -642,21 +796,36 @@ func (s *Server) readResource(ctx context.Context, req *ReadResourceRequest) (*R
 }
 
 // lookupResourceHandler returns the resource handler and MIME type for the resource or
-// resource template matching uri. If none, the last return value is false.
-func (s *Server) lookupResourceHandler(uri string) (ResourceHandler, string, bool) {
+// resource template visible to ss matching uri, and the scopes it requires if ti lacks them.
+// If none, the boolean return value is false.
+func (s *Server) lookupResourceHandler(ctx context.Context, ss *ServerSession, uri string, ti *auth.TokenInfo) (ResourceHandler, string, []string, bool, error) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	r, ok := sessionGet(ss, s.resources, sessionResources, uri)
+	var templates []*serverResourceTemplate
+	for rt := range sessionSet(ss, s.resourceTemplates, sessionResourceTemplates).all() {
+		if rt.Matches(uri) {
+			templates = append(templates, rt)
+		}
+	}
+	providers := s.resourceProviders
+	s.mu.Unlock()
+	// The filters are called without the lock (see [ServerOptions.ToolFilter]).
 	// Try resources first.
-	if r, ok := s.resources.get(uri); ok {
-		return r.handler, r.resource.MIMEType, true
+	if ok && shown(s.opts.ResourceFilter, ss, r.resource) {
+		return r.handler, r.resource.MIMEType, missingScopes(r.scopes, ti), true, nil
 	}
 	// Look for matching template.
-	for rt := range s.resourceTemplates.all() {
-		if rt.Matches(uri) {
-			return rt.handler, rt.resourceTemplate.MIMEType, true
+	for _, rt := range templates {
+		if shown(s.opts.ResourceTemplateFilter, ss, rt.resourceTemplate) {
+			return rt.handler, rt.resourceTemplate.MIMEType, missingScopes(rt.scopes, ti), true, nil
 		}
 	}
-	return nil, "", false
+	// Finally, ask the providers.
+	r, ok, err := getProvided(ctx, providers, uri)
+	if err != nil || !ok || !shown(s.opts.ResourceFilter, ss, r.resource) {
+		return nil, "", nil, false, err
+	}
+	return r.handler, r.resource.MIMEType, nil, true, nil
 }
 
 // fileResourceHandler returns a ReadResourceHandler that reads paths using dir as
@@ -914,6 +1083,13 @@ func (ss *ServerSession) NotifyProgress(ctx context.Context, params *ProgressNot
 	return handleNotify(ctx, notificationProgress, newServerRequest(ss, orZero[Params](params)))
 }
 
+// NotifyElicitationComplete notifies the client that the URL mode elicitation
+// with the given ID has completed out of band. See also [URLElicitor], which
+// sends this notification automatically.
+func (ss *ServerSession) NotifyElicitationComplete(ctx context.Context, params *ElicitationCompleteParams) error {
+	return handleNotify(ctx, notificationElicitationComplete, newServerRequest(ss, orZero[Params](params)))
+}
+
 func newServerRequest[P Params](ss *ServerSession, params P) *ServerRequest[P] {
 	return &ServerRequest[P]{Session: ss, Params: params}
 }
@@ -938,6 +1114,11 @@ type ServerSession struct {
 
 	mu    sync.Mutex
 	state ServerSessionState
+
+	features *sessionFeatures // guarded by server.mu; nil until a feature is added
+
+	// Listeners for progress on outgoing requests.
+	progress progressListeners
 }
 
 func (ss *ServerSession) updateState(mut func(*ServerSessionState)) {
@@ -984,6 +1165,39 @@ func (ss *ServerSession) ID() string {
 	return ""
 }
 
+// A Principal identifies the authenticated user of a session.
+type Principal struct {
+	// Subject identifies the user. See [auth.TokenInfo.Subject].
+	Subject string
+	// ClientID identifies the OAuth client acting for the user.
+	// See [auth.TokenInfo.ClientID].
+	ClientID string
+}
+
+// principalOf returns the principal identified by ti, or nil if ti is nil or
+// identifies no one.
+func principalOf(ti *auth.TokenInfo) *Principal {
+	if ti == nil || (ti.Subject == "" && ti.ClientID == "") {
+		return nil
+	}
+	return &Principal{Subject: ti.Subject, ClientID: ti.ClientID}
+}
+
+// Principal returns the authenticated user of the session, or nil if the
+// session is not authenticated.
+//
+// A session served by a [StreamableHTTPHandler] is authenticated if the
+// request that created it carries an [auth.TokenInfo] (for example, from
+// [auth.RequireBearerToken]) with a subject or client ID. Later requests for
+// the session must carry a token for the same principal, or they are rejected
+// with 403 Forbidden.
+func (ss *ServerSession) Principal() *Principal {
+	if c, ok := ss.mcpConn.(interface{ principal() *Principal }); ok {
+		return c.principal()
+	}
+	return nil
+}
+
 // Ping pings the client.
 func (ss *ServerSession) Ping(ctx context.Context, params *PingParams) error {
 	_, err := handleSend[*emptyResult](ctx, methodPing, newServerRequest(ss, orZero[Params](params)))
@@ -1055,7 +1269,9 @@ func (ss *ServerSession) Elicit(ctx context.Context, params *ElicitParams) (*Eli
 		return nil, err
 	}
 
-	if params.RequestedSchema == nil {
+	// Content is only present, and therefore only validated, when the user
+	// accepted the request.
+	if params.RequestedSchema == nil || res.Action != "accept" {
 		return res, nil
 	}
 	schema, err := validateElicitSchema(params.RequestedSchema)
@@ -1177,8 +1393,24 @@ func (ss *ServerSession) receivingMethodHandler() MethodHandler {
 // getConn implements [session.getConn].
 func (ss *ServerSession) getConn() *jsonrpc2.Connection { return ss.conn }
 
+// sendingTimeout implements [Session.sendingTimeout].
+func (ss *ServerSession) sendingTimeout(method string) (time.Duration, bool, bool) {
+	opts := &ss.server.opts
+	return opts.Timeouts[method], opts.ResetTimeoutOnProgress, opts.PropagateDeadlines
+}
+
+// listenProgress implements [Session.listenProgress].
+func (ss *ServerSession) listenProgress(token any, f func(*ProgressNotificationParams)) func() {
+	return ss.progress.listen(token, f)
+}
+
+// dispatchProgress implements [progressDispatcher].
+func (ss *ServerSession) dispatchProgress(params *ProgressNotificationParams) {
+	ss.progress.dispatch(params)
+}
+
 // handle invokes the method described by the given JSON RPC request.
-func (ss *ServerSession) handle(ctx context.Context, req *jsonrpc.Request) (any, error) {
+func (ss *ServerSession) handle(ctx context.Context, req *jsonrpc.Request) (res any, err error) {
 	ss.mu.Lock()
 	initialized := ss.state.InitializeParams != nil
 	ss.mu.Unlock()
@@ -1206,7 +1438,21 @@ func (ss *ServerSession) handle(ctx context.Context, req *jsonrpc.Request) (any,
 	// server->client calls and notifications to the incoming request from which
 	// they originated. See [idContextKey] for details.
 	ctx = context.WithValue(ctx, idContextKey{}, req.ID)
-	return handleReceive(ctx, ss, req)
+
+	// Recover from panics in handlers, so that a bug in one handler fails only
+	// its request rather than the whole server.
+	defer func() {
+		if r := recover(); r != nil {
+			ss.server.opts.Logger.Error("panic in handler", "method", req.Method, "panic", r, "stack", string(debug.Stack()))
+			res, err = nil, &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: "internal error"}
+		}
+	}()
+	// Map errors with the server's error registry, unless the handler has
+	// already done so (as callTool does, to report tool errors).
+	var mapped error
+	ctx = context.WithValue(ctx, mappedErrorKey{}, &mapped)
+	res, err = handleReceive(ctx, ss, req)
+	return res, ss.server.opts.ErrorRegistry.mapErrorOnce(ctx, err)
 }
 
 // InitializeParams returns the InitializeParams provided during the client's
@@ -1290,60 +1536,36 @@ func (ss *ServerSession) startKeepalive(interval time.Duration) {
 	startKeepalive(ss, interval, &ss.keepaliveCancel)
 }
 
-// pageToken is the internal structure for the opaque pagination cursor.
-// It will be Gob-encoded and then Base64-encoded for use as a string token.
-type pageToken struct {
-	LastUID string // The unique ID of the last resource seen.
-}
-
-// encodeCursor encodes a unique identifier (UID) into a opaque pagination cursor
-// by serializing a pageToken struct.
-func encodeCursor(uid string) (string, error) {
-	var buf bytes.Buffer
-	token := pageToken{LastUID: uid}
-	encoder := gob.NewEncoder(&buf)
-	if err := encoder.Encode(token); err != nil {
-		return "", fmt.Errorf("failed to encode page token: %w", err)
-	}
-	return base64.URLEncoding.EncodeToString(buf.Bytes()), nil
-}
-
-// decodeCursor decodes an opaque pagination cursor into the original pageToken struct.
-func decodeCursor(cursor string) (*pageToken, error) {
-	decodedBytes, err := base64.URLEncoding.DecodeString(cursor)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode cursor: %w", err)
-	}
-
-	var token pageToken
-	buf := bytes.NewBuffer(decodedBytes)
-	decoder := gob.NewDecoder(buf)
-	if err := decoder.Decode(&token); err != nil {
-		return nil, fmt.Errorf("failed to decode page token: %w, cursor: %v", err, cursor)
-	}
-	return &token, nil
-}
-
 // paginateList is a generic helper that returns a paginated slice of items
 // from a featureSet. It populates the provided result res with the items
 // and sets its next cursor for subsequent pages.
 // If there are no more pages, the next cursor within the result will be an empty string.
 func paginateList[P listParams, R listResult[T], T any](fs *featureSet[T], pageSize int, params P, res R, setFunc func(R, []T)) (R, error) {
+	return paginateVisible(defaultCursorCodec(), fs, nil, pageSize, params, res, setFunc)
+}
+
+// paginateVisible is like paginateList, but only lists the features for which
+// visible returns true, and uses codec for cursors. If visible is nil, all
+// features are listed.
+func paginateVisible[P listParams, R listResult[T], T any](codec CursorCodec, fs featureList[T], visible func(T) bool, pageSize int, params P, res R, setFunc func(R, []T)) (R, error) {
 	var seq iter.Seq[T]
 	if params.cursorPtr() == nil || *params.cursorPtr() == "" {
 		seq = fs.all()
 	} else {
-		pageToken, err := decodeCursor(*params.cursorPtr())
+		pageToken, err := decodeToken(codec, *params.cursorPtr())
 		// According to the spec, invalid cursors should return Invalid params.
 		if err != nil {
 			var zero R
-			return zero, jsonrpc2.ErrInvalidParams
+			return zero, err
 		}
 		seq = fs.above(pageToken.LastUID)
 	}
 	var count int
 	var features []T
 	for f := range seq {
+		if visible != nil && !visible(f) {
+			continue
+		}
 		count++
 		// If we've seen pageSize + 1 elements, we've gathered enough info to determine
 		// if there's a next page. Stop processing the sequence.
@@ -1357,7 +1579,7 @@ func paginateList[P listParams, R listResult[T], T any](fs *featureSet[T], pageS
 	if count < pageSize+1 {
 		return res, nil
 	}
-	nextCursor, err := encodeCursor(fs.uniqueID(features[len(features)-1]))
+	nextCursor, err := encodeToken(codec, pageToken{LastUID: fs.id(features[len(features)-1])})
 	if err != nil {
 		var zero R
 		return zero, err
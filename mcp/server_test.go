@@ -58,9 +58,9 @@ var allTestItems = []*testItem{
 // getCursor encodes a string input into a URL-safe base64 cursor,
 // fatally logging any encoding errors.
 func getCursor(input string) string {
-	cursor, err := encodeCursor(input)
+	cursor, err := encodeToken(defaultCursorCodec(), pageToken{LastUID: input})
 	if err != nil {
-		log.Fatalf("encodeCursor(%s) error = %v", input, err)
+		log.Fatalf("encodeToken(%s) error = %v", input, err)
 	}
 	return cursor
 }
@@ -562,7 +562,7 @@ func testToolForSchema[In, Out any](t *testing.T, tool *Tool, in string, out Out
 	th := func(context.Context, *CallToolRequest, In) (*CallToolResult, Out, error) {
 		return nil, out, nil
 	}
-	gott, goth, err := toolForErr(tool, th)
+	gott, goth, err := toolForErr(nil, tool, th)
 	if err != nil {
 		t.Fatal(err)
 	}
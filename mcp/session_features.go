@@ -0,0 +1,245 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"fmt"
+	"iter"
+	"net/url"
+	"slices"
+
+	"github.com/yosida95/uritemplate/v3"
+)
+
+// This file implements features that are visible to a single session, in
+// addition to or in place of the features of its server.
+
+// sessionFeatures holds the features added to a single session. They overlay
+// the features of the server: a session feature replaces a server feature with
+// the same name or URI.
+type sessionFeatures struct {
+	prompts           *featureSet[*serverPrompt]
+	tools             *featureSet[*serverTool]
+	resources         *featureSet[*serverResource]
+	resourceTemplates *featureSet[*serverResourceTemplate]
+	toolsets          map[string]bool // toolset name -> enabled for the session
+}
+
+func newSessionFeatures() *sessionFeatures {
+	return &sessionFeatures{
+		prompts:           newFeatureSet(func(p *serverPrompt) string { return p.prompt.Name }),
+		tools:             newFeatureSet(func(t *serverTool) string { return t.tool.Name }),
+		resources:         newFeatureSet(func(r *serverResource) string { return r.resource.URI }),
+		resourceTemplates: newFeatureSet(func(t *serverResourceTemplate) string { return t.resourceTemplate.URITemplate }),
+		toolsets:          make(map[string]bool),
+	}
+}
+
+// Selectors for the feature sets of a sessionFeatures.
+func sessionPrompts(f *sessionFeatures) *featureSet[*serverPrompt] { return f.prompts }
+func sessionTools(f *sessionFeatures) *featureSet[*serverTool]     { return f.tools }
+func sessionResources(f *sessionFeatures) *featureSet[*serverResource] {
+	return f.resources
+}
+func sessionResourceTemplates(f *sessionFeatures) *featureSet[*serverResourceTemplate] {
+	return f.resourceTemplates
+}
+
+// sessionSet returns the features of global overlaid with the features of ss
+// chosen by sel. The result must only be used with the server's mu held.
+func sessionSet[T any](ss *ServerSession, global *featureSet[T], sel func(*sessionFeatures) *featureSet[T]) featureList[T] {
+	if ss == nil || ss.features == nil || sel(ss.features).len() == 0 {
+		return global
+	}
+	return overlay[T]{global, sel(ss.features)}
+}
+
+// An overlay lists the features of a session together with those of its
+// server, merging the two sets as it is iterated rather than copying them.
+// A session feature replaces the server feature with the same ID.
+type overlay[T any] struct {
+	global, session *featureSet[T]
+}
+
+func (o overlay[T]) all() iter.Seq[T] { return o.merge(o.global.all(), o.session.all()) }
+
+func (o overlay[T]) above(uid string) iter.Seq[T] {
+	return o.merge(o.global.above(uid), o.session.above(uid))
+}
+
+func (o overlay[T]) get(uid string) (T, bool) {
+	if f, ok := o.session.get(uid); ok {
+		return f, true
+	}
+	return o.global.get(uid)
+}
+
+func (o overlay[T]) id(f T) string { return o.global.uniqueID(f) }
+
+// merge merges the sequences of global and session features, both in ID
+// order. Session features are usually few, so they are collected first.
+func (o overlay[T]) merge(global, session iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		local := slices.Collect(session)
+		for f := range global {
+			id := o.id(f)
+			for len(local) > 0 && o.id(local[0]) < id {
+				if !yield(local[0]) {
+					return
+				}
+				local = local[1:]
+			}
+			if len(local) > 0 && o.id(local[0]) == id {
+				continue // replaced by local[0], which is yielded next
+			}
+			if !yield(f) {
+				return
+			}
+		}
+		for _, f := range local {
+			if !yield(f) {
+				return
+			}
+		}
+	}
+}
+
+// sessionGet returns the feature of ss chosen by sel with the given uid, or
+// else the one in global. It must be called with the server's mu held.
+func sessionGet[T any](ss *ServerSession, global *featureSet[T], sel func(*sessionFeatures) *featureSet[T], uid string) (T, bool) {
+	if ss != nil && ss.features != nil {
+		if f, ok := sel(ss.features).get(uid); ok {
+			return f, true
+		}
+	}
+	return global.get(uid)
+}
+
+// shown reports whether filter, one of the feature filters of [ServerOptions],
+// lets ss see f.
+func shown[T any](filter func(*ServerSession, T) bool, ss *ServerSession, f T) bool {
+	return filter == nil || filter(ss, f)
+}
+
+// sessionFilter returns a function reporting whether filter, one of the feature
+// filters of [ServerOptions], lets ss see a feature, whose protocol value is
+// returned by value. It returns nil if filter is nil.
+func sessionFilter[T, F any](filter func(*ServerSession, F) bool, ss *ServerSession, value func(T) F) func(T) bool {
+	if filter == nil {
+		return nil
+	}
+	return func(f T) bool { return filter(ss, value(f)) }
+}
+
+// changeAndNotify is like [Server.changeAndNotify], but for the features of a
+// single session: it calls change with the session's features, and notifies
+// only this session.
+func (ss *ServerSession) changeAndNotify(notification string, params Params, change func(*sessionFeatures) bool) {
+	s := ss.server
+	s.mu.Lock()
+	if ss.features == nil {
+		ss.features = newSessionFeatures()
+	}
+	changed := change(ss.features)
+	s.mu.Unlock()
+	if changed {
+		notifySessions([]*ServerSession{ss}, notification, params)
+	}
+}
+
+// AddPrompt adds a [Prompt] to the session, or replaces one with the same name.
+// The prompt is visible only to this session, in addition to the prompts of
+// the server, and replaces any server prompt with the same name.
+func (ss *ServerSession) AddPrompt(p *Prompt, h PromptHandler) {
+	ss.changeAndNotify(notificationPromptListChanged, &PromptListChangedParams{},
+		func(f *sessionFeatures) bool { f.prompts.add(&serverPrompt{prompt: p, handler: h}); return true })
+}
+
+// RemovePrompts removes the session prompts with the given names.
+// It does not affect the prompts of the server; to hide those from the
+// session, use [ServerOptions.PromptFilter].
+// It is not an error to remove a nonexistent prompt.
+func (ss *ServerSession) RemovePrompts(names ...string) {
+	ss.changeAndNotify(notificationPromptListChanged, &PromptListChangedParams{},
+		func(f *sessionFeatures) bool { return f.prompts.remove(names...) })
+}
+
+// AddTool adds a [Tool] to the session, or replaces one with the same name.
+// The tool is visible only to this session, in addition to the tools of the
+// server, and replaces any server tool with the same name.
+//
+// The requirements of [Server.AddTool] apply. Most users should use the
+// top-level function [AddSessionTool].
+//
+// The tools capability is advertised at initialization only if the server has
+// tools or [ServerOptions.HasTools] is set. Set HasTools if sessions may have
+// tools when the server has none.
+func (ss *ServerSession) AddTool(t *Tool, h ToolHandler) {
+	ss.server.checkTool(t)
+	st := &serverTool{tool: t, handler: h}
+	ss.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
+		func(f *sessionFeatures) bool { f.tools.add(st); return true })
+}
+
+// AddSessionTool adds a tool and typed tool handler to the session, as
+// [AddTool] does for a server. See [ServerSession.AddTool].
+func AddSessionTool[In, Out any](ss *ServerSession, t *Tool, h ToolHandlerFor[In, Out]) {
+	tt, hh, err := toolForErr(ss.server.opts.SchemaRegistry, t, h)
+	if err != nil {
+		panic(fmt.Sprintf("AddSessionTool: tool %q: %v", t.Name, err))
+	}
+	ss.AddTool(tt, hh)
+}
+
+// RemoveTools removes the session tools with the given names.
+// It does not affect the tools of the server; to hide those from the session,
+// use [ServerOptions.ToolFilter].
+// It is not an error to remove a nonexistent tool.
+func (ss *ServerSession) RemoveTools(names ...string) {
+	ss.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
+		func(f *sessionFeatures) bool { return f.tools.remove(names...) })
+}
+
+// AddResource adds a [Resource] to the session, or replaces one with the same
+// URI, as [Server.AddResource] does for the server. The resource is visible
+// only to this session.
+func (ss *ServerSession) AddResource(r *Resource, h ResourceHandler) {
+	if _, err := url.Parse(r.URI); err != nil {
+		panic(err) // url.Parse includes the URI in the error
+	}
+	ss.changeAndNotify(notificationResourceListChanged, &ResourceListChangedParams{},
+		func(f *sessionFeatures) bool { f.resources.add(&serverResource{resource: r, handler: h}); return true })
+}
+
+// RemoveResources removes the session resources with the given URIs.
+// It does not affect the resources of the server; to hide those from the
+// session, use [ServerOptions.ResourceFilter].
+// It is not an error to remove a nonexistent resource.
+func (ss *ServerSession) RemoveResources(uris ...string) {
+	ss.changeAndNotify(notificationResourceListChanged, &ResourceListChangedParams{},
+		func(f *sessionFeatures) bool { return f.resources.remove(uris...) })
+}
+
+// AddResourceTemplate adds a [ResourceTemplate] to the session, or replaces one
+// with the same URI template, as [Server.AddResourceTemplate] does for the
+// server. The template is visible only to this session.
+func (ss *ServerSession) AddResourceTemplate(t *ResourceTemplate, h ResourceHandler) {
+	if _, err := uritemplate.New(t.URITemplate); err != nil {
+		panic(fmt.Errorf("URI template %q is invalid: %w", t.URITemplate, err))
+	}
+	ss.changeAndNotify(notificationResourceListChanged, &ResourceListChangedParams{},
+		func(f *sessionFeatures) bool {
+			f.resourceTemplates.add(&serverResourceTemplate{resourceTemplate: t, handler: h})
+			return true
+		})
+}
+
+// RemoveResourceTemplates removes the session resource templates with the
+// given URI templates.
+// It is not an error to remove a nonexistent resource template.
+func (ss *ServerSession) RemoveResourceTemplates(uriTemplates ...string) {
+	ss.changeAndNotify(notificationResourceListChanged, &ResourceListChangedParams{},
+		func(f *sessionFeatures) bool { return f.resourceTemplates.remove(uriTemplates...) })
+}
@@ -0,0 +1,214 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestSessionFeatures(t *testing.T) {
+	ctx := context.Background()
+	textTool := func(text string) ToolHandler {
+		return func(context.Context, *CallToolRequest) (*CallToolResult, error) {
+			return &CallToolResult{Content: []Content{&TextContent{Text: text}}}, nil
+		}
+	}
+	// Clients named "limited" can't see the "shared" tool or resource.
+	limited := func(ss *ServerSession) bool {
+		return ss.InitializeParams().ClientInfo.Name == "limited"
+	}
+	server := NewServer(testImpl, &ServerOptions{
+		ToolFilter: func(ss *ServerSession, t *Tool) bool {
+			return t.Name != "shared" || !limited(ss)
+		},
+		ResourceFilter: func(ss *ServerSession, r *Resource) bool {
+			return r.URI != "file:///shared" || !limited(ss)
+		},
+	})
+	server.AddTool(&Tool{Name: "shared", InputSchema: &jsonschema.Schema{Type: "object"}}, textTool("server"))
+	server.AddResource(&Resource{URI: "file:///shared", Name: "shared"}, func(context.Context, *ReadResourceRequest) (*ReadResourceResult, error) {
+		return &ReadResourceResult{Contents: []*ResourceContents{{Text: "shared"}}}, nil
+	})
+
+	admin, other, lim := watchTools(t, server, "admin"), watchTools(t, server, "other"), watchTools(t, server, "limited")
+
+	checkTools := func(c *toolsWatcher, want ...string) {
+		t.Helper()
+		res, err := c.cs.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, tool := range res.Tools {
+			got = append(got, tool.Name)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: listed tools %v, want %v", c.ss.InitializeParams().ClientInfo.Name, got, want)
+		}
+	}
+	callTool := func(c *toolsWatcher, name string) string {
+		t.Helper()
+		res, err := c.cs.CallTool(ctx, &CallToolParams{Name: name})
+		if err != nil {
+			return "error"
+		}
+		return res.Content[0].(*TextContent).Text
+	}
+
+	AddSessionTool(admin.ss, &Tool{Name: "admin"}, func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+		return &CallToolResult{Content: []Content{&TextContent{Text: "admin"}}}, nil, nil
+	})
+	admin.awaitChange(t)
+	checkTools(admin, "admin", "shared")
+	checkTools(other, "shared")
+	checkTools(lim)
+	if got := callTool(admin, "admin"); got != "admin" {
+		t.Errorf("admin: calling admin got %q", got)
+	}
+	if got := callTool(other, "admin"); got != "error" {
+		t.Errorf("other: calling admin got %q, want error", got)
+	}
+	if got := callTool(lim, "shared"); got != "error" {
+		t.Errorf("limited: calling shared got %q, want error", got)
+	}
+
+	// A session tool replaces the server tool with the same name, until it is
+	// removed.
+	other.ss.AddTool(&Tool{Name: "shared", InputSchema: &jsonschema.Schema{Type: "object"}}, textTool("session"))
+	other.awaitChange(t)
+	if got := callTool(other, "shared"); got != "session" {
+		t.Errorf("other: calling shared got %q, want %q", got, "session")
+	}
+	if got := callTool(admin, "shared"); got != "server" {
+		t.Errorf("admin: calling shared got %q, want %q", got, "server")
+	}
+	other.ss.RemoveTools("shared", "admin")
+	other.awaitChange(t)
+	checkTools(other, "shared")
+	if got := callTool(other, "shared"); got != "server" {
+		t.Errorf("other: calling shared got %q, want %q", got, "server")
+	}
+
+	// Removing nothing sends no notification.
+	admin.ss.RemoveTools("nonexistent")
+	if got := admin.count.Load(); got != 1 {
+		t.Errorf("admin: got %d notifications, want 1", got)
+	}
+	if got := lim.count.Load(); got != 0 {
+		t.Errorf("limited: got %d notifications, want 0", got)
+	}
+
+	// Session resources and filters.
+	lim.ss.AddResource(&Resource{URI: "file:///mine", Name: "mine"}, func(context.Context, *ReadResourceRequest) (*ReadResourceResult, error) {
+		return &ReadResourceResult{Contents: []*ResourceContents{{Text: "mine"}}}, nil
+	})
+	res, err := lim.cs.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Resources) != 1 || res.Resources[0].Name != "mine" {
+		t.Errorf("limited: listed resources %v, want only mine", res.Resources)
+	}
+	if _, err := lim.cs.ReadResource(ctx, &ReadResourceParams{URI: "file:///shared"}); err == nil {
+		t.Error("limited: reading shared succeeded, want error")
+	}
+	if _, err := other.cs.ReadResource(ctx, &ReadResourceParams{URI: "file:///mine"}); err == nil {
+		t.Error("other: reading mine succeeded, want error")
+	}
+	if _, err := lim.cs.ReadResource(ctx, &ReadResourceParams{URI: "file:///mine"}); err != nil {
+		t.Errorf("limited: reading mine failed: %v", err)
+	}
+}
+
+func TestSessionFeaturesPagination(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, &ServerOptions{PageSize: 2})
+	handler := func(context.Context, *CallToolRequest) (*CallToolResult, error) { return &CallToolResult{}, nil }
+	for _, name := range []string{"a", "c", "e"} {
+		server.AddTool(&Tool{Name: name, InputSchema: &jsonschema.Schema{Type: "object"}}, handler)
+	}
+	cs, ss, cleanup := basicClientServerConnection(t, nil, server, nil)
+	defer cleanup()
+	for _, name := range []string{"b", "c", "f"} {
+		ss.AddTool(&Tool{Name: name, Description: "session", InputSchema: &jsonschema.Schema{Type: "object"}}, handler)
+	}
+
+	var got []string
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, tool.Name+":"+tool.Description)
+	}
+	want := []string{"a:", "b:session", "c:session", "e:", "f:session"}
+	if !slices.Equal(got, want) {
+		t.Errorf("listed tools %v, want %v", got, want)
+	}
+}
+
+func TestFiltersCallServer(t *testing.T) {
+	// Filters may call methods of the server, which take its lock.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var server *Server
+	sessions := func() int { return len(slices.Collect(server.Sessions())) }
+	server = NewServer(testImpl, &ServerOptions{
+		PageSize:               1,
+		ToolFilter:             func(*ServerSession, *Tool) bool { return sessions() > 0 },
+		PromptFilter:           func(*ServerSession, *Prompt) bool { return sessions() > 0 },
+		ResourceFilter:         func(*ServerSession, *Resource) bool { return sessions() > 0 },
+		ResourceTemplateFilter: func(*ServerSession, *ResourceTemplate) bool { return sessions() > 0 },
+	})
+	for _, name := range []string{"a", "b"} {
+		AddTool(server, &Tool{Name: name}, func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) {
+			return &CallToolResult{}, nil, nil
+		})
+	}
+	server.AddPrompt(&Prompt{Name: "p"}, func(context.Context, *GetPromptRequest) (*GetPromptResult, error) {
+		return &GetPromptResult{}, nil
+	})
+	read := func(context.Context, *ReadResourceRequest) (*ReadResourceResult, error) {
+		return &ReadResourceResult{Contents: []*ResourceContents{{Text: "x"}}}, nil
+	}
+	server.AddResource(&Resource{URI: "file:///r", Name: "r"}, read)
+	server.AddResourceTemplate(&ResourceTemplate{URITemplate: "file:///t/{x}", Name: "t"}, read)
+	cs, _, _ := basicClientServerConnection(t, nil, server, nil)
+
+	var tools []string
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		tools = append(tools, tool.Name)
+	}
+	if !slices.Equal(tools, []string{"a", "b"}) {
+		t.Errorf("listed tools %v, want [a b]", tools)
+	}
+	if _, err := cs.CallTool(ctx, &CallToolParams{Name: "a"}); err != nil {
+		t.Errorf("CallTool: %v", err)
+	}
+	if _, err := cs.ListPrompts(ctx, nil); err != nil {
+		t.Errorf("ListPrompts: %v", err)
+	}
+	if _, err := cs.GetPrompt(ctx, &GetPromptParams{Name: "p"}); err != nil {
+		t.Errorf("GetPrompt: %v", err)
+	}
+	if _, err := cs.ListResources(ctx, nil); err != nil {
+		t.Errorf("ListResources: %v", err)
+	}
+	if _, err := cs.ListResourceTemplates(ctx, nil); err != nil {
+		t.Errorf("ListResourceTemplates: %v", err)
+	}
+	for _, uri := range []string{"file:///r", "file:///t/x"} {
+		if _, err := cs.ReadResource(ctx, &ReadResourceParams{URI: uri}); err != nil {
+			t.Errorf("ReadResource(%s): %v", uri, err)
+		}
+	}
+}
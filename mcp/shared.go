@@ -76,6 +76,12 @@ type Session interface {
 	sendingMethodHandler() MethodHandler
 	receivingMethodHandler() MethodHandler
 	getConn() *jsonrpc2.Connection
+	// sendingTimeout reports the default timeout for outgoing requests of the
+	// given method, whether it is reset by progress, and whether deadlines are
+	// propagated to the peer.
+	sendingTimeout(method string) (timeout time.Duration, resetOnProgress, propagateDeadline bool)
+	// listenProgress listens for progress on an outgoing request.
+	listenProgress(token any, f func(*ProgressNotificationParams)) (stop func())
 }
 
 // Middleware is a function from [MethodHandler] to [MethodHandler].
@@ -94,6 +100,9 @@ func defaultSendingMethodHandler(ctx context.Context, method string, req Request
 		// This can be called from user code, with an arbitrary value for method.
 		return nil, jsonrpc2.ErrNotHandled
 	}
+	if cs, ok := req.GetSession().(*ClientSession); ok && cs.rc != nil {
+		return cs.sendReconnecting(ctx, method, info, req.GetParams())
+	}
 	// Notifications don't have results.
 	if strings.HasPrefix(method, "notifications/") {
 		return nil, req.GetSession().getConn().Notify(ctx, method, req.GetParams())
@@ -123,6 +132,8 @@ func handleNotify(ctx context.Context, method string, req Request) error {
 }
 
 func handleSend[R Result](ctx context.Context, method string, req Request) (R, error) {
+	ctx, done := withSendingTimeout(ctx, method, req.GetSession(), req.GetParams())
+	defer done()
 	mh := req.GetSession().sendingMethodHandler()
 	// mh might be user code, so ensure that it returns the right values for the jsonrpc2 protocol.
 	res, err := mh(ctx, method, req)
@@ -143,7 +154,7 @@ func defaultReceivingMethodHandler[S Session](ctx context.Context, method string
 	return info.handleMethod(ctx, method, req)
 }
 
-func handleReceive[S Session](ctx context.Context, session S, jreq *jsonrpc.Request) (Result, error) {
+func handleReceive[S Session](ctx context.Context, session S, jreq *jsonrpc.Request) (res Result, err error) {
 	info, err := checkRequest(jreq, session.receivingMethodInfos())
 	if err != nil {
 		return nil, err
@@ -156,8 +167,20 @@ func handleReceive[S Session](ctx context.Context, session S, jreq *jsonrpc.Requ
 	mh := session.receivingMethodHandler()
 	re, _ := jreq.Extra.(*RequestExtra)
 	req := info.newRequest(session, params, re)
+	if d, ok := receivedTimeout(params); ok && jreq.IsCall() {
+		// Stop work when the peer stops waiting.
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	if ss, ok := any(session).(*ServerSession); ok && jreq.IsCall() {
+		if pr := newProgressReporter(ctx, ss, params); pr != nil {
+			req.(interface{ setProgress(*progressReporter) }).setProgress(pr)
+			defer func() { pr.finish(err) }()
+		}
+	}
 	// mh might be user code, so ensure that it returns the right values for the jsonrpc2 protocol.
-	res, err := mh(ctx, jreq.Method, req)
+	res, err = mh(ctx, jreq.Method, req)
 	if err != nil {
 		return nil, err
 	}
@@ -339,6 +362,12 @@ const (
 	// before processing the request. The client should execute the elicitation handler
 	// with the elicitations provided in the error data.
 	CodeURLElicitationRequired = -32042
+	// CodeInsufficientScope indicates that the caller's access token lacks
+	// the scopes required for the request. The error data holds the required
+	// scopes, and a WWW-Authenticate challenge for step-up authorization.
+	//
+	// This code is specific to this SDK.
+	CodeInsufficientScope = -32043
 )
 
 // URLElicitationRequiredError returns an error indicating that URL elicitation is required
@@ -370,6 +399,41 @@ func URLElicitationRequiredError(elicitations []*ElicitParams) error {
 	}
 }
 
+// InsufficientScopeData is the data of an error with code
+// [CodeInsufficientScope].
+type InsufficientScopeData struct {
+	// Scopes are the scopes required for the request.
+	Scopes []string `json:"scopes"`
+	// Challenge is a WWW-Authenticate challenge that asks the client to
+	// obtain a token with the required scopes.
+	// See [auth.InsufficientScopeChallenge].
+	Challenge string `json:"challenge"`
+}
+
+// InsufficientScopeError returns an error indicating that the caller's access
+// token lacks the given scopes.
+//
+// Servers return this error for features that require scopes the caller
+// lacks (see [Server.RequireToolScopes]). Over the streamable transport, a
+// POST whose only call fails with this error is answered with 403 Forbidden,
+// and the challenge in the WWW-Authenticate header (see
+// [StreamableHTTPOptions.ResourceMetadataURL]).
+func InsufficientScopeError(scopes ...string) error {
+	data, err := json.Marshal(InsufficientScopeData{
+		Scopes:    scopes,
+		Challenge: auth.InsufficientScopeChallenge(scopes, ""),
+	})
+	if err != nil {
+		// This should never happen.
+		panic(fmt.Sprintf("failed to marshal scopes: %v", err))
+	}
+	return &jsonrpc.Error{
+		Code:    CodeInsufficientScope,
+		Message: fmt.Sprintf("insufficient scope: requires %s", strings.Join(scopes, " ")),
+		Data:    json.RawMessage(data),
+	}
+}
+
 // Internal error codes
 const (
 	// The error code if the method exists and was called properly, but the peer does not support it.
@@ -436,6 +500,7 @@ func setProgressToken(p Params, pt any) {
 	m := p.GetMeta()
 	if m == nil {
 		m = map[string]any{}
+		p.SetMeta(m)
 	}
 	m[progressTokenKey] = pt
 }
@@ -461,6 +526,8 @@ type ServerRequest[P Params] struct {
 	Session *ServerSession
 	Params  P
 	Extra   *RequestExtra
+
+	progress *progressReporter // set for incoming calls with a progress token
 }
 
 // RequestExtra is extra information included in requests, typically from
@@ -490,6 +557,23 @@ func (r *ServerRequest[P]) GetParams() Params { return r.Params }
 func (r *ClientRequest[P]) GetExtra() *RequestExtra { return nil }
 func (r *ServerRequest[P]) GetExtra() *RequestExtra { return r.Extra }
 
+// Progress returns a [Progress] for reporting on the progress of the request.
+// If the client did not provide a progress token, the result is a no-op.
+//
+// Calling Progress opts in to a final report of completion when the handler
+// for the request returns successfully.
+func (r *ServerRequest[P]) Progress() *Progress {
+	if r.progress == nil {
+		return &Progress{}
+	}
+	r.progress.mu.Lock()
+	r.progress.used = true
+	r.progress.mu.Unlock()
+	return &Progress{r: r.progress, scale: 1}
+}
+
+func (r *ServerRequest[P]) setProgress(pr *progressReporter) { r.progress = pr }
+
 func serverRequestFor[P Params](s *ServerSession, p P) *ServerRequest[P] {
 	return &ServerRequest[P]{Session: s, Params: p}
 }
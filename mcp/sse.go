@@ -7,6 +7,7 @@ package mcp
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -44,6 +45,7 @@ import (
 type SSEHandler struct {
 	getServer    func(request *http.Request) *Server
 	opts         SSEOptions
+	guard        *httpGuard
 	onConnection func(*ServerSession) // for testing; must not block
 
 	mu       sync.Mutex
@@ -51,9 +53,21 @@ type SSEHandler struct {
 }
 
 // SSEOptions specifies options for an [SSEHandler].
-// for now, it is empty, but may be extended in future.
 // https://github.com/modelcontextprotocol/go-sdk/issues/507
-type SSEOptions struct{}
+type SSEOptions struct {
+	// MaxBodySize is the maximum size of a POST body, in bytes. Larger
+	// bodies are rejected with 413 Content Too Large. If zero,
+	// [DefaultMaxMessageSize] is used; if negative, there is no limit.
+	MaxBodySize int64
+	// AllowedOrigins lists the origins from which requests are accepted, and
+	// which may make cross-origin requests. AllowedHosts lists the accepted
+	// values of the Host header. ExposedHeaders are the response headers
+	// exposed to cross-origin requests. See "Origin and Host validation" in
+	// the package documentation for details and defaults.
+	AllowedOrigins []string
+	AllowedHosts   []string
+	ExposedHeaders []string
+}
 
 // NewSSEHandler returns a new [SSEHandler] that creates and manages MCP
 // sessions created via incoming HTTP requests.
@@ -77,6 +91,7 @@ func NewSSEHandler(getServer func(request *http.Request) *Server, opts *SSEOptio
 	if opts != nil {
 		s.opts = *opts
 	}
+	s.guard = newHTTPGuard(s.opts.AllowedOrigins, s.opts.AllowedHosts, s.opts.ExposedHeaders, "GET, POST")
 
 	return s
 }
@@ -110,6 +125,9 @@ type SSEServerTransport struct {
 	// Response is the hanging response body to the incoming GET request.
 	Response http.ResponseWriter
 
+	// MaxBodySize limits the size of POST bodies, as for [SSEOptions].
+	MaxBodySize int64
+
 	// incoming is the queue of incoming messages.
 	// It is never closed, and by convention, incoming is non-nil if and only if
 	// the transport is connected.
@@ -132,8 +150,12 @@ func (t *SSEServerTransport) ServeHTTP(w http.ResponseWriter, req *http.Request)
 	}
 
 	// Read and parse the message.
+	limitBody(w, req, resolveLimit(t.MaxBodySize, DefaultMaxMessageSize))
 	data, err := io.ReadAll(req.Body)
 	if err != nil {
+		if bodyTooLarge(w, err) {
+			return
+		}
 		http.Error(w, "failed to read body", http.StatusBadRequest)
 		return
 	}
@@ -178,6 +200,10 @@ func (t *SSEServerTransport) Connect(context.Context) (Connection, error) {
 }
 
 func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if !h.guard.check(w, req) {
+		return
+	}
+
 	sessionID := req.URL.Query().Get("sessionid")
 
 	// TODO: consider checking Content-Type here. For now, we are lax.
@@ -222,7 +248,7 @@ func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	transport := &SSEServerTransport{Endpoint: endpoint.RequestURI(), Response: w}
+	transport := &SSEServerTransport{Endpoint: endpoint.RequestURI(), Response: w, MaxBodySize: h.opts.MaxBodySize}
 
 	// The session is terminated when the request exits.
 	h.mu.Lock()
@@ -329,6 +355,12 @@ type SSEClientTransport struct {
 	// HTTPClient is the client to use for making HTTP requests. If nil,
 	// http.DefaultClient is used.
 	HTTPClient *http.Client
+
+	// MaxMessageSize is the maximum size of an incoming message, in bytes.
+	// Oversized messages are skipped, and a call whose response is oversized
+	// fails with an error. If zero, [DefaultMaxMessageSize] is used; if
+	// negative, there is no limit.
+	MaxMessageSize int64
 }
 
 // Connect connects through the client endpoint.
@@ -382,12 +414,25 @@ func (c *SSEClientTransport) Connect(ctx context.Context) (Connection, error) {
 	go func() {
 		defer s.Close() // close the transport when the GET exits
 
-		for evt, err := range scanEvents(resp.Body) {
+		for evt, err := range scanEventsLimit(resp.Body, resolveLimit(c.MaxMessageSize, DefaultMaxMessageSize)) {
+			data := evt.Data
 			if err != nil {
-				return
+				var oversized *oversizedError
+				if !errors.As(err, &oversized) {
+					return
+				}
+				// Fail the call awaiting an oversized response, if any.
+				// Other oversized messages are dropped.
+				resp, local := oversizedResponse(oversized)
+				if !local {
+					continue
+				}
+				if data, err = jsonrpc2.EncodeMessage(resp); err != nil {
+					continue
+				}
 			}
 			select {
-			case s.incoming <- evt.Data:
+			case s.incoming <- data:
 			case <-s.done:
 				return
 			}
@@ -41,6 +41,7 @@ const (
 type StreamableHTTPHandler struct {
 	getServer func(*http.Request) *Server
 	opts      StreamableHTTPOptions
+	guard     *httpGuard
 
 	onTransportDeletion func(sessionID string) // for testing
 
@@ -151,6 +152,31 @@ type StreamableHTTPOptions struct {
 	//
 	// If SessionTimeout is the zero value, idle sessions are never closed.
 	SessionTimeout time.Duration
+
+	// MaxBodySize is the maximum size of a POST body, in bytes. Larger
+	// bodies are rejected with 413 Content Too Large, and the session is
+	// unaffected. If zero, [DefaultMaxMessageSize] is used; if negative,
+	// there is no limit.
+	MaxBodySize int64
+
+	// MaxBatchSize is the maximum number of messages in a JSON-RPC batch.
+	// Larger batches are rejected with 413 Content Too Large. If zero,
+	// [DefaultMaxBatchSize] is used; if negative, there is no limit.
+	MaxBatchSize int
+	// AllowedOrigins lists the origins from which requests are accepted, and
+	// which may make cross-origin requests. AllowedHosts lists the accepted
+	// values of the Host header. ExposedHeaders are the response headers
+	// exposed to cross-origin requests. See "Origin and Host validation" in
+	// the package documentation for details and defaults.
+	AllowedOrigins []string
+	AllowedHosts   []string
+	ExposedHeaders []string
+
+	// ResourceMetadataURL is the URL of the server's protected resource
+	// metadata, if any. It is included in the WWW-Authenticate challenge of
+	// requests rejected for insufficient scope (see [InsufficientScopeError]),
+	// so that clients can obtain a token with the required scopes.
+	ResourceMetadataURL string
 }
 
 // NewStreamableHTTPHandler returns a new [StreamableHTTPHandler].
@@ -170,6 +196,7 @@ func NewStreamableHTTPHandler(getServer func(*http.Request) *Server, opts *Strea
 	if h.opts.Logger == nil { // ensure we have a logger
 		h.opts.Logger = ensureLogger(nil)
 	}
+	h.guard = newHTTPGuard(h.opts.AllowedOrigins, h.opts.AllowedHosts, h.opts.ExposedHeaders, "GET, POST, DELETE")
 
 	return h
 }
@@ -198,6 +225,10 @@ func (h *StreamableHTTPHandler) closeAll() {
 }
 
 func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if !h.guard.check(w, req) {
+		return
+	}
+
 	// Allow multiple 'Accept' headers.
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Reference/Headers/Accept#syntax
 	accept := strings.Split(strings.Join(req.Header.Values("Accept"), ","), ",")
@@ -238,6 +269,16 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 			http.Error(w, "session not found", http.StatusNotFound)
 			return
 		}
+		// A session created by an authenticated request may only be used by the
+		// same principal, so that a session ID can't be used to hijack it.
+		if sessInfo != nil {
+			if owner := sessInfo.transport.principal; owner != nil {
+				if p := principalOf(auth.TokenInfoFromContext(req.Context())); p == nil || *p != *owner {
+					http.Error(w, "Forbidden: session belongs to a different principal", http.StatusForbidden)
+					return
+				}
+			}
+		}
 	}
 
 	if req.Method == http.MethodDelete {
@@ -256,6 +297,9 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 
 	switch req.Method {
 	case http.MethodPost, http.MethodGet:
+		if req.Method == http.MethodPost {
+			limitBody(w, req, resolveLimit(h.opts.MaxBodySize, DefaultMaxMessageSize))
+		}
 		if req.Method == http.MethodGet && (h.opts.Stateless || sessionID == "") {
 			http.Error(w, "GET requires an active session", http.StatusMethodNotAllowed)
 			return
@@ -321,8 +365,13 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 			SessionID:    sessionID,
 			Stateless:    h.opts.Stateless,
 			EventStore:   h.opts.EventStore,
+			MaxBodySize:  h.opts.MaxBodySize,
+			MaxBatchSize: h.opts.MaxBatchSize,
 			jsonResponse: h.opts.JSONResponse,
 			logger:       h.opts.Logger,
+			principal:    principalOf(auth.TokenInfoFromContext(req.Context())),
+
+			resourceMetadataURL: h.opts.ResourceMetadataURL,
 		}
 
 		// Sessions without a session ID are also stateless: there's no way to
@@ -340,6 +389,9 @@ func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Reque
 				// stateless servers.
 				body, err := io.ReadAll(req.Body)
 				if err != nil {
+					if bodyTooLarge(w, err) {
+						return
+					}
 					http.Error(w, "failed to read body", http.StatusInternalServerError)
 					return
 				}
@@ -488,6 +540,13 @@ type StreamableServerTransport struct {
 	// upon stream resumption.
 	EventStore EventStore
 
+	// MaxBodySize and MaxBatchSize limit the size of incoming POST requests.
+	//
+	// See [StreamableHTTPOptions.MaxBodySize] and
+	// [StreamableHTTPOptions.MaxBatchSize].
+	MaxBodySize  int64
+	MaxBatchSize int
+
 	// jsonResponse, if set, tells the server to prefer to respond to requests
 	// using application/json responses rather than text/event-stream.
 	//
@@ -507,6 +566,14 @@ type StreamableServerTransport struct {
 	// to write their own streamable HTTP handler.
 	logger *slog.Logger
 
+	// principal is the authenticated user that created the session, if any.
+	// See [ServerSession.Principal].
+	principal *Principal
+
+	// resourceMetadataURL is included in insufficient scope challenges.
+	// See [StreamableHTTPOptions.ResourceMetadataURL].
+	resourceMetadataURL string
+
 	// connection is non-nil if and only if the transport has been connected.
 	connection *streamableServerConn
 }
@@ -517,15 +584,19 @@ func (t *StreamableServerTransport) Connect(ctx context.Context) (Connection, er
 		return nil, fmt.Errorf("transport already connected")
 	}
 	t.connection = &streamableServerConn{
-		sessionID:      t.SessionID,
-		stateless:      t.Stateless,
-		eventStore:     t.EventStore,
-		jsonResponse:   t.jsonResponse,
-		logger:         ensureLogger(t.logger), // see #556: must be non-nil
-		incoming:       make(chan jsonrpc.Message, 10),
-		done:           make(chan struct{}),
-		streams:        make(map[string]*stream),
-		requestStreams: make(map[jsonrpc.ID]string),
+		sessionID:           t.SessionID,
+		stateless:           t.Stateless,
+		eventStore:          t.EventStore,
+		maxBodySize:         resolveLimit(t.MaxBodySize, DefaultMaxMessageSize),
+		maxBatchSize:        resolveLimit(t.MaxBatchSize, DefaultMaxBatchSize),
+		jsonResponse:        t.jsonResponse,
+		owner:               t.principal,
+		resourceMetadataURL: t.resourceMetadataURL,
+		logger:              ensureLogger(t.logger), // see #556: must be non-nil
+		incoming:            make(chan jsonrpc.Message, 10),
+		done:                make(chan struct{}),
+		streams:             make(map[string]*stream),
+		requestStreams:      make(map[jsonrpc.ID]string),
 	}
 	// Stream 0 corresponds to the standalone SSE stream.
 	//
@@ -544,6 +615,11 @@ type streamableServerConn struct {
 	stateless    bool
 	jsonResponse bool
 	eventStore   EventStore
+	maxBodySize  int64 // if positive, the maximum size of a POST body
+	maxBatchSize int   // if positive, the maximum size of an incoming batch
+	owner        *Principal
+
+	resourceMetadataURL string
 
 	logger *slog.Logger
 
@@ -580,6 +656,25 @@ func (c *streamableServerConn) SessionID() string {
 	return c.sessionID
 }
 
+func (c *streamableServerConn) principal() *Principal {
+	return c.owner
+}
+
+// challengeScope reports whether data is a response that failed for
+// insufficient scope. If so, it writes the header of a 403 Forbidden response
+// to w, with a WWW-Authenticate challenge for the required scopes. The caller
+// should write data as the body.
+func (c *streamableServerConn) challengeScope(w http.ResponseWriter, data []byte) bool {
+	challenge, ok := scopeChallenge(data, c.resourceMetadataURL)
+	if !ok {
+		return false
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	return true
+}
+
 // A stream is a single logical stream of SSE events within a server session.
 // A stream begins with a client request, or with a client GET that has
 // no Last-Event-ID header.
@@ -904,8 +999,12 @@ func (c *streamableServerConn) servePOST(w http.ResponseWriter, req *http.Reques
 	}
 
 	// Read incoming messages.
+	limitBody(w, req, c.maxBodySize)
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
+		if bodyTooLarge(w, err) {
+			return
+		}
 		http.Error(w, "failed to read body", http.StatusBadRequest)
 		return
 	}
@@ -918,6 +1017,10 @@ func (c *streamableServerConn) servePOST(w http.ResponseWriter, req *http.Reques
 		http.Error(w, fmt.Sprintf("malformed payload: %v", err), http.StatusBadRequest)
 		return
 	}
+	if err := batchTooLarge(len(incoming), c.maxBatchSize); err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
 
 	protocolVersion := req.Header.Get(protocolVersionHeader)
 	if protocolVersion == "" {
@@ -1036,6 +1139,7 @@ func (c *streamableServerConn) servePOST(w http.ResponseWriter, req *http.Reques
 			var toWrite []byte
 			if len(msgs) == 1 {
 				toWrite = []byte(msgs[0])
+				c.challengeScope(w, toWrite)
 			} else {
 				var err error
 				toWrite, err = json.Marshal(msgs)
@@ -1069,6 +1173,12 @@ func (c *streamableServerConn) servePOST(w http.ResponseWriter, req *http.Reques
 			}
 			if final {
 				defer close(done)
+				// If the only message is a response failing for insufficient
+				// scope, reply with a challenge instead of a stream.
+				if lastIndex < 0 && c.challengeScope(w, data) {
+					_, err := w.Write(data)
+					return err
+				}
 			}
 			return c.writeEvent(w, stream.id, Event{Name: "message", Data: data}, &lastIndex)
 		}
@@ -1319,34 +1429,185 @@ type StreamableClientTransport struct {
 	HTTPClient *http.Client
 	// MaxRetries is the maximum number of times to attempt a reconnect before giving up.
 	// It defaults to 5. To disable retries, use a negative number.
+	//
+	// If RetryPolicy.MaxAttempts is set, it takes precedence.
 	MaxRetries int
+	// RetryPolicy controls how failed requests are retried. If nil, the
+	// defaults described by [RetryPolicy] are used.
+	RetryPolicy *RetryPolicy
 
-	// TODO(rfindley): propose exporting these.
-	// If strict is set, the transport is in 'strict mode', where any violation
+	// If Strict is set, the transport is in 'strict mode', where any violation
 	// of the MCP spec causes a failure.
-	strict bool
-	// If logger is set, it is used to log aspects of the transport, such as spec
-	// violations that were ignored.
-	logger *slog.Logger
-}
+	Strict bool
+	// If Logger is set, it is used to log aspects of the transport, such as spec
+	// violations that were ignored, and retried requests.
+	Logger *slog.Logger
 
-// These settings are not (yet) exposed to the user in
-// StreamableClientTransport.
-const (
-	// reconnectGrowFactor is the multiplicative factor by which the delay increases after each attempt.
-	// A value of 1.0 results in a constant delay, while a value of 2.0 would double it each time.
-	// It must be 1.0 or greater if MaxRetries is greater than 0.
-	reconnectGrowFactor = 1.5
-	// reconnectMaxDelay caps the backoff delay, preventing it from growing indefinitely.
-	reconnectMaxDelay = 30 * time.Second
-)
+	// MaxMessageSize is the maximum size of an incoming message, in bytes.
+	// A call whose response is larger fails with an error wrapping
+	// [ErrMessageTooLarge]; other oversized messages are dropped. If zero,
+	// [DefaultMaxMessageSize] is used; if negative, there is no limit.
+	//
+	// A call that the server rejects with 413 Content Too Large also fails
+	// with an error wrapping ErrMessageTooLarge. In both cases, the session
+	// remains usable.
+	MaxMessageSize int64
+}
 
-var (
-	// reconnectInitialDelay is the base delay for the first reconnect attempt.
+// A RetryPolicy controls how a [StreamableClientTransport] retries requests.
+//
+// Requests are retried after a network error, when resuming an interrupted
+// SSE stream, or when the server responds with one of the
+// RetryableStatusCodes. The delay before each attempt grows exponentially,
+// and has random jitter added. If the server specifies a delay, using the
+// Retry-After header or the SSE retry field, it is used instead, up to
+// MaxDelay.
+type RetryPolicy struct {
+	// InitialDelay is the delay before the first retry. It defaults to 1s.
+	InitialDelay time.Duration
+	// GrowFactor is the multiplicative factor by which the delay increases
+	// after each attempt. A value of 1.0 results in a constant delay, while a
+	// value of 2.0 doubles it each time. It defaults to 1.5, and values less
+	// than 1.0 are treated as 1.0.
+	GrowFactor float64
+	// MaxDelay caps the delay, preventing it from growing indefinitely, and
+	// caps the delays requested by the server. It defaults to 30s.
+	MaxDelay time.Duration
+	// Jitter is the maximum random amount added to each delay, as a fraction
+	// of the delay. It defaults to 1.0, meaning that each delay is up to twice
+	// the computed backoff. To disable jitter, use a negative number.
+	Jitter float64
+	// MaxAttempts is the maximum number of times to retry a request before
+	// giving up. If zero, [StreamableClientTransport.MaxRetries] is used. To
+	// disable retries, use a negative number.
+	MaxAttempts int
+	// RetryableStatusCodes are the HTTP status codes for which a request is
+	// retried. If nil, it defaults to 429 (Too Many Requests) and 503 (Service
+	// Unavailable), which mean that the server did not process the request;
+	// GET requests, which are idempotent, are also retried on 502 (Bad
+	// Gateway) and 504 (Gateway Timeout). To disable retrying based on status
+	// codes, use an empty, non-nil slice.
 	//
-	// Mutable for testing.
-	reconnectInitialDelay = 1 * time.Second
-)
+	// Other codes apply to all requests, including POSTs of calls that may
+	// not be safe to repeat, such as tools/call: after a 502 or 504, for
+	// example, the server may have processed the request.
+	RetryableStatusCodes []int
+
+	// idempotentStatusCodes are additional codes for which idempotent
+	// requests are retried.
+	idempotentStatusCodes []int
+}
+
+// defaultRetryableStatusCodes are the default value of
+// [RetryPolicy.RetryableStatusCodes].
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusServiceUnavailable,
+}
+
+// defaultIdempotentStatusCodes are the codes for which idempotent requests are
+// also retried, if [RetryPolicy.RetryableStatusCodes] is nil.
+var defaultIdempotentStatusCodes = []int{
+	http.StatusBadGateway,
+	http.StatusGatewayTimeout,
+}
+
+// resolve returns a copy of p with defaults applied. maxRetries is the value
+// of [StreamableClientTransport.MaxRetries].
+func (p *RetryPolicy) resolve(maxRetries int) RetryPolicy {
+	var r RetryPolicy
+	if p != nil {
+		r = *p
+	}
+	if r.InitialDelay <= 0 {
+		r.InitialDelay = 1 * time.Second
+	}
+	if r.GrowFactor == 0 {
+		r.GrowFactor = 1.5
+	}
+	r.GrowFactor = max(r.GrowFactor, 1)
+	if r.MaxDelay <= 0 {
+		r.MaxDelay = 30 * time.Second
+	}
+	if r.Jitter == 0 {
+		r.Jitter = 1
+	}
+	r.Jitter = max(r.Jitter, 0)
+	if r.MaxAttempts == 0 {
+		r.MaxAttempts = maxRetries
+	}
+	if r.MaxAttempts == 0 {
+		r.MaxAttempts = 5
+	} else if r.MaxAttempts < 0 {
+		r.MaxAttempts = 0
+	}
+	if r.RetryableStatusCodes == nil {
+		r.RetryableStatusCodes = defaultRetryableStatusCodes
+		r.idempotentStatusCodes = defaultIdempotentStatusCodes
+	}
+	return r
+}
+
+// delay calculates the delay before the given attempt using exponential
+// backoff with jitter.
+//
+// If base is positive, it is a delay requested by the server, which is used
+// for the first attempt and as the initial delay for subsequent attempts. It
+// is capped at p.MaxDelay.
+func (p *RetryPolicy) delay(attempt int, base time.Duration) time.Duration {
+	if attempt == 0 {
+		return 0
+	}
+	base = min(base, p.MaxDelay)
+	initial := p.InitialDelay
+	if base > 0 {
+		if attempt == 1 {
+			return base // honor the server's requested delay
+		}
+		initial = base
+	}
+	// Calculate the exponential backoff using the grow factor.
+	backoffDuration := time.Duration(float64(initial) * math.Pow(p.GrowFactor, float64(attempt-1)))
+	// Cap the backoffDuration at maxDelay.
+	backoffDuration = min(backoffDuration, p.MaxDelay)
+
+	if jitter := time.Duration(p.Jitter * float64(backoffDuration)); jitter > 0 {
+		backoffDuration += rand.N(jitter)
+	}
+	return backoffDuration
+}
+
+// retryAfter reports whether resp should be retried as the given attempt, and
+// if so, the delay before doing so. If idempotent is set, the request can be
+// repeated safely even if the server processed it.
+func (p *RetryPolicy) retryAfter(resp *http.Response, attempt int, idempotent bool) (time.Duration, bool) {
+	if attempt > p.MaxAttempts {
+		return 0, false
+	}
+	if !slices.Contains(p.RetryableStatusCodes, resp.StatusCode) &&
+		!(idempotent && slices.Contains(p.idempotentStatusCodes, resp.StatusCode)) {
+		return 0, false
+	}
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		return min(d, p.MaxDelay), true
+	}
+	return p.delay(attempt, 0), true
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which may be
+// either a number of seconds or an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return max(time.Duration(secs)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
 
 // Connect implements the [Transport] interface.
 //
@@ -1361,12 +1622,6 @@ func (t *StreamableClientTransport) Connect(ctx context.Context) (Connection, er
 	if client == nil {
 		client = http.DefaultClient
 	}
-	maxRetries := t.MaxRetries
-	if maxRetries == 0 {
-		maxRetries = 5
-	} else if maxRetries < 0 {
-		maxRetries = 0
-	}
 	// Create a new cancellable context that will manage the connection's lifecycle.
 	// This is crucial for cleanly shutting down the background SSE listener by
 	// cancelling its blocking network operations, which prevents hangs on exit.
@@ -1385,29 +1640,31 @@ func (t *StreamableClientTransport) Connect(ctx context.Context) (Connection, er
 	// middleware), yet only cancel the standalone stream when the connection is closed.
 	connCtx, cancel := context.WithCancel(xcontext.Detach(ctx))
 	conn := &streamableClientConn{
-		url:        t.Endpoint,
-		client:     client,
-		incoming:   make(chan jsonrpc.Message, 10),
-		done:       make(chan struct{}),
-		maxRetries: maxRetries,
-		strict:     t.strict,
-		logger:     t.logger,
-		ctx:        connCtx,
-		cancel:     cancel,
-		failed:     make(chan struct{}),
+		url:      t.Endpoint,
+		client:   client,
+		incoming: make(chan jsonrpc.Message, 10),
+		done:     make(chan struct{}),
+		retry:    t.RetryPolicy.resolve(t.MaxRetries),
+		maxSize:  resolveLimit(t.MaxMessageSize, DefaultMaxMessageSize),
+		strict:   t.Strict,
+		logger:   t.Logger,
+		ctx:      connCtx,
+		cancel:   cancel,
+		failed:   make(chan struct{}),
 	}
 	return conn, nil
 }
 
 type streamableClientConn struct {
-	url        string
-	client     *http.Client
-	ctx        context.Context    // connection context, detached from Connect
-	cancel     context.CancelFunc // cancels ctx
-	incoming   chan jsonrpc.Message
-	maxRetries int
-	strict     bool         // from [StreamableClientTransport.strict]
-	logger     *slog.Logger // from [StreamableClientTransport.logger]
+	url      string
+	client   *http.Client
+	ctx      context.Context    // connection context, detached from Connect
+	cancel   context.CancelFunc // cancels ctx
+	incoming chan jsonrpc.Message
+	retry    RetryPolicy  // from [StreamableClientTransport.RetryPolicy], with defaults applied
+	maxSize  int64        // if positive, the maximum size of an incoming message
+	strict   bool         // from [StreamableClientTransport.Strict]
+	logger   *slog.Logger // from [StreamableClientTransport.Logger]
 
 	// Guard calls to Close, as it may be called multiple times.
 	closeOnce sync.Once
@@ -1438,8 +1695,10 @@ type streamableClientConn struct {
 // API) to the user?
 //
 // The spec says that if the server returns 404, clients should reestablish
-// a session. For now, we delegate that to the user, but do they need a way to
-// differentiate a 'NotFound' error from other errors?
+// a session. The connection fails, and a ClientSession configured with
+// [ClientSessionOptions.Reconnect] establishes a new one; otherwise, this is
+// delegated to the user, but do they need a way to differentiate a 'NotFound'
+// error from other errors?
 var errSessionMissing = errors.New("session not found")
 
 var _ clientConnection = (*streamableClientConn)(nil)
@@ -1579,19 +1838,49 @@ func (c *streamableClientConn) Write(ctx context.Context, msg jsonrpc.Message) e
 		return fmt.Errorf("%s: %v", requestSummary, err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json, text/event-stream")
-	c.setMCPHeaders(req)
+	var resp *http.Response
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		c.setMCPHeaders(req)
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("%s: %v", requestSummary, err)
+		resp, err = c.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("%s: %v", requestSummary, err)
+		}
+		// By default, only statuses that mean the server did not process the
+		// request (429 and 503) are retried, since the request may not be
+		// safe to repeat.
+		delay, ok := c.retry.retryAfter(resp, attempt, false)
+		if !ok {
+			break
+		}
+		resp.Body.Close()
+		c.logRetry(requestSummary, resp.StatusCode, delay)
+		select {
+		case <-c.done:
+			return fmt.Errorf("%s: connection closed by client during retry", requestSummary)
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", requestSummary, ctx.Err())
+		case <-time.After(delay):
+		}
 	}
 
+	if resp.StatusCode == http.StatusRequestEntityTooLarge {
+		// The server rejected the message, but the session is unaffected.
+		resp.Body.Close()
+		return fmt.Errorf("%s: %w (%w)", requestSummary, ErrMessageTooLarge, jsonrpc2.ErrRejected)
+	}
+	if challenge := resp.Header.Get("WWW-Authenticate"); resp.StatusCode == http.StatusForbidden && strings.Contains(challenge, "insufficient_scope") {
+		// The token lacks the scopes for this request, but the session is
+		// unaffected. If the server responded to the call, fail the call with
+		// the response.
+		return c.rejectScope(requestSummary, resp, challenge)
+	}
 	if err := c.checkResponse(requestSummary, resp); err != nil {
 		c.fail(err)
 		return err
@@ -1667,15 +1956,53 @@ func (c *streamableClientConn) setMCPHeaders(req *http.Request) {
 	}
 }
 
+// rejectScope handles a 403 Forbidden response with an insufficient_scope
+// challenge. If the body holds a JSON-RPC response, it is delivered, so that
+// the call fails with its error. Otherwise, rejectScope returns an error
+// rejecting the message.
+func (c *streamableClientConn) rejectScope(requestSummary string, resp *http.Response, challenge string) error {
+	defer resp.Body.Close()
+	var r io.Reader = resp.Body
+	if c.maxSize > 0 {
+		r = io.LimitReader(r, c.maxSize+1)
+	}
+	body, err := io.ReadAll(r)
+	if err == nil && (c.maxSize <= 0 || int64(len(body)) <= c.maxSize) {
+		if msg, err := jsonrpc.DecodeMessage(body); err == nil {
+			if _, ok := msg.(*jsonrpc.Response); ok {
+				go func() {
+					select {
+					case c.incoming <- msg:
+					case <-c.done:
+					}
+				}()
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%s: insufficient scope (%s): %w", requestSummary, challenge, jsonrpc2.ErrRejected)
+}
+
 func (c *streamableClientConn) handleJSON(requestSummary string, resp *http.Response) {
-	body, err := io.ReadAll(resp.Body)
+	var r io.Reader = resp.Body
+	if c.maxSize > 0 {
+		r = io.LimitReader(r, c.maxSize+1)
+	}
+	body, err := io.ReadAll(r)
 	resp.Body.Close()
 	if err != nil {
 		c.fail(fmt.Errorf("%s: failed to read body: %v", requestSummary, err))
 		return
 	}
-	msg, err := jsonrpc.DecodeMessage(body)
-	if err != nil {
+	var msg jsonrpc.Message
+	if c.maxSize > 0 && int64(len(body)) > c.maxSize {
+		// Fail the call, if we can tell which call it is.
+		resp, local := oversizedResponse(&oversizedError{limit: c.maxSize, prefix: body})
+		if !local {
+			return
+		}
+		msg = resp
+	} else if msg, err = jsonrpc.DecodeMessage(body); err != nil {
 		c.fail(fmt.Errorf("%s: failed to decode response: %v", requestSummary, err))
 		return
 	}
@@ -1692,6 +2019,10 @@ func (c *streamableClientConn) handleJSON(requestSummary string, resp *http.Resp
 // If forCall is set, it is the call that initiated the stream, and the
 // stream is complete when we receive its response.
 func (c *streamableClientConn) handleSSE(ctx context.Context, requestSummary string, resp *http.Response, persistent bool, forCall *jsonrpc2.Request) {
+	// reconnectDelay is the reconnection time most recently set by the server
+	// using the SSE retry field. Per the SSE spec, it persists across
+	// reconnections.
+	var reconnectDelay time.Duration
 	for {
 		// Connection was successful. Continue the loop with the new response.
 		//
@@ -1701,7 +2032,10 @@ func (c *streamableClientConn) handleSSE(ctx context.Context, requestSummary str
 		//
 		// Eventually, if we don't get the response, we should stop trying and
 		// fail the request.
-		lastEventID, reconnectDelay, clientClosed := c.processStream(ctx, requestSummary, resp, forCall)
+		lastEventID, retryDelay, clientClosed := c.processStream(ctx, requestSummary, resp, forCall)
+		if retryDelay > 0 {
+			reconnectDelay = retryDelay
+		}
 
 		// If the connection was closed by the client, we're done.
 		if clientClosed {
@@ -1763,8 +2097,9 @@ func (c *streamableClientConn) checkResponse(requestSummary string, resp *http.R
 // returns "", false.
 func (c *streamableClientConn) processStream(ctx context.Context, requestSummary string, resp *http.Response, forCall *jsonrpc.Request) (lastEventID string, reconnectDelay time.Duration, clientClosed bool) {
 	defer resp.Body.Close()
-	for evt, err := range scanEvents(resp.Body) {
-		if err != nil {
+	for evt, err := range scanEventsLimit(resp.Body, c.maxSize) {
+		var oversized *oversizedError
+		if err != nil && !errors.As(err, &oversized) {
 			if ctx.Err() != nil {
 				return "", 0, true // don't reconnect: client cancelled
 			}
@@ -1785,8 +2120,16 @@ func (c *streamableClientConn) processStream(ctx context.Context, requestSummary
 			continue
 		}
 
-		msg, err := jsonrpc.DecodeMessage(evt.Data)
-		if err != nil {
+		var msg jsonrpc.Message
+		if oversized != nil {
+			// Fail the call awaiting an oversized response, if any.
+			// Other oversized messages are dropped.
+			resp, local := oversizedResponse(oversized)
+			if !local {
+				continue
+			}
+			msg = resp
+		} else if msg, err = jsonrpc.DecodeMessage(evt.Data); err != nil {
 			c.fail(fmt.Errorf("%s: failed to decode event: %v", requestSummary, err))
 			return "", 0, true
 		}
@@ -1835,7 +2178,9 @@ func (c *streamableClientConn) processStream(ctx context.Context, requestSummary
 // if all retries are exhausted.
 //
 // reconnectDelay is the delay set by the server using the SSE retry field, or
-// 0.
+// 0. Responses with a status in [RetryPolicy.RetryableStatusCodes] are also
+// retried, honoring any Retry-After header, except for the last attempt, whose
+// response is returned to the caller.
 //
 // If initial is set, this is the initial attempt.
 //
@@ -1852,11 +2197,8 @@ func (c *streamableClientConn) connectSSE(ctx context.Context, lastEventID strin
 		// logical request.
 		attempt = 1
 	}
-	delay := calculateReconnectDelay(attempt)
-	if reconnectDelay > 0 {
-		delay = reconnectDelay // honor the server's requested initial delay
-	}
-	for ; attempt <= c.maxRetries; attempt++ {
+	delay := c.retry.delay(attempt, reconnectDelay)
+	for ; attempt <= c.retry.MaxAttempts; attempt++ {
 		select {
 		case <-c.done:
 			return nil, fmt.Errorf("connection closed by client during reconnect")
@@ -1879,7 +2221,14 @@ func (c *streamableClientConn) connectSSE(ctx context.Context, lastEventID strin
 			resp, err := c.client.Do(req)
 			if err != nil {
 				finalErr = err // Store the error and try again.
-				delay = calculateReconnectDelay(attempt + 1)
+				delay = c.retry.delay(attempt+1, reconnectDelay)
+				continue
+			}
+			if d, ok := c.retry.retryAfter(resp, attempt+1, true); ok {
+				resp.Body.Close()
+				c.logRetry("SSE request", resp.StatusCode, d)
+				finalErr = errors.New(http.StatusText(resp.StatusCode))
+				delay = d
 				continue
 			}
 			return resp, nil
@@ -1887,9 +2236,9 @@ func (c *streamableClientConn) connectSSE(ctx context.Context, lastEventID strin
 	}
 	// If the loop completes, all retries have failed, or the client is closing.
 	if finalErr != nil {
-		return nil, fmt.Errorf("connection failed after %d attempts: %w", c.maxRetries, finalErr)
+		return nil, fmt.Errorf("connection failed after %d attempts: %w", c.retry.MaxAttempts, finalErr)
 	}
-	return nil, fmt.Errorf("connection aborted after %d attempts", c.maxRetries)
+	return nil, fmt.Errorf("connection aborted after %d attempts", c.retry.MaxAttempts)
 }
 
 // Close implements the [Connection] interface.
@@ -1916,18 +2265,9 @@ func (c *streamableClientConn) Close() error {
 	return c.closeErr
 }
 
-// calculateReconnectDelay calculates a delay using exponential backoff with full jitter.
-func calculateReconnectDelay(attempt int) time.Duration {
-	if attempt == 0 {
-		return 0
+// logRetry logs that a request is being retried due to the given status.
+func (c *streamableClientConn) logRetry(requestSummary string, status int, delay time.Duration) {
+	if c.logger != nil {
+		c.logger.Info(fmt.Sprintf("%s: got %d, retrying in %v", requestSummary, status, delay))
 	}
-	// Calculate the exponential backoff using the grow factor.
-	backoffDuration := time.Duration(float64(reconnectInitialDelay) * math.Pow(reconnectGrowFactor, float64(attempt-1)))
-	// Cap the backoffDuration at maxDelay.
-	backoffDuration = min(backoffDuration, reconnectMaxDelay)
-
-	// Use a full jitter using backoffDuration
-	jitter := rand.N(backoffDuration)
-
-	return backoffDuration + jitter
 }
@@ -362,7 +362,7 @@ func TestStreamableClientStrictness(t *testing.T) {
 			httpServer := httptest.NewServer(fake)
 			defer httpServer.Close()
 
-			transport := &StreamableClientTransport{Endpoint: httpServer.URL, strict: test.strict}
+			transport := &StreamableClientTransport{Endpoint: httpServer.URL, Strict: test.strict}
 			client := NewClient(testImpl, nil)
 			session, err := client.Connect(ctx, transport, nil)
 			if (err != nil) != test.wantConnectError {
@@ -432,10 +432,6 @@ func TestStreamableClientResumption_Cancelled(t *testing.T) {
 	//
 	// TODO(#680): experiment with instead using synctest.
 	const tick = 10 * time.Millisecond
-	defer func(delay time.Duration) {
-		reconnectInitialDelay = delay
-	}(reconnectInitialDelay)
-	reconnectInitialDelay = 2 * tick
 
 	// The setup: terminate a request stream and make the resumed request hang
 	// indefinitely. CallTool should still exit when its context is canceled.
@@ -519,7 +515,10 @@ data: { "jsonrpc": "2.0", "method": "notifications/message", "params": { "level"
 			defer httpServer.Close()
 			defer close(allDone) // must be deferred *after* httpServer.Close, to avoid deadlock
 
-			transport := &StreamableClientTransport{Endpoint: httpServer.URL}
+			transport := &StreamableClientTransport{
+				Endpoint:    httpServer.URL,
+				RetryPolicy: &RetryPolicy{InitialDelay: 2 * tick},
+			}
 			client := NewClient(testImpl, nil)
 			cs, err := client.Connect(ctx, transport, nil)
 			if err != nil {
@@ -555,3 +554,98 @@ data: { "jsonrpc": "2.0", "method": "notifications/message", "params": { "level"
 		})
 	}
 }
+
+func TestStreamableClientRetryPolicy(t *testing.T) {
+	server := NewServer(testImpl, nil)
+	handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, nil)
+
+	tests := []struct {
+		label      string
+		failures   int // number of requests to reject
+		status     int // status of rejections; 503 if zero
+		retryAfter string
+		policy     *RetryPolicy
+		wantErr    bool
+	}{
+		// The server's Retry-After takes precedence over the (very long) delay
+		// from the policy.
+		{"retry after", 2, 0, "0", &RetryPolicy{InitialDelay: time.Hour}, false},
+		// ...but is capped at MaxDelay.
+		{"long retry after", 2, 0, "3600", &RetryPolicy{MaxDelay: time.Millisecond}, false},
+		{"backoff", 2, 0, "", &RetryPolicy{InitialDelay: time.Millisecond}, false},
+		{"max attempts", 3, 0, "0", &RetryPolicy{MaxAttempts: 2}, true},
+		{"not retryable", 1, 0, "0", &RetryPolicy{RetryableStatusCodes: []int{}}, true},
+		// The server may have processed a POST that failed with 502, so it is
+		// not retried by default.
+		{"bad gateway", 1, http.StatusBadGateway, "0", nil, true},
+		{"bad gateway opt-in", 1, http.StatusBadGateway, "0", &RetryPolicy{RetryableStatusCodes: []int{http.StatusBadGateway}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.label, func(t *testing.T) {
+			var mu sync.Mutex
+			failures := test.failures
+			httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				mu.Lock()
+				fail := req.Method == http.MethodPost && failures > 0
+				if fail {
+					failures--
+				}
+				mu.Unlock()
+				if fail {
+					if test.retryAfter != "" {
+						w.Header().Set("Retry-After", test.retryAfter)
+					}
+					status := test.status
+					if status == 0 {
+						status = http.StatusServiceUnavailable
+					}
+					http.Error(w, "busy", status)
+					return
+				}
+				handler.ServeHTTP(w, req)
+			}))
+			defer httpServer.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			transport := &StreamableClientTransport{Endpoint: httpServer.URL, RetryPolicy: test.policy}
+			cs, err := NewClient(testImpl, nil).Connect(ctx, transport, nil)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Connect() error = %v, want error: %t", err, test.wantErr)
+			}
+			if err == nil {
+				cs.Close()
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	// Delays requested by the server, such as with the SSE retry field, are
+	// capped at MaxDelay, jitter aside.
+	p := (&RetryPolicy{MaxDelay: time.Second, Jitter: -1}).resolve(0)
+	for attempt := 1; attempt <= 3; attempt++ {
+		if got := p.delay(attempt, time.Hour); got > time.Second {
+			t.Errorf("delay(%d, 1h) = %v, want at most 1s", attempt, got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	for _, test := range []struct {
+		in     string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-3", 0, true},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0, true}, // in the past
+		{"soon", 0, false},
+	} {
+		got, ok := parseRetryAfter(test.in)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("parseRetryAfter(%q) = (%v, %t), want (%v, %t)", test.in, got, ok, test.want, test.wantOK)
+		}
+	}
+}
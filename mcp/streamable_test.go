@@ -1624,6 +1624,7 @@ func TestTokenInfo(t *testing.T) {
 			Scopes: []string{"scope"},
 			// Expiration is far, far in the future.
 			Expiration: time.Date(5000, 1, 2, 3, 4, 5, 0, time.UTC),
+			Subject:    "user",
 		}, nil
 	}
 	handler := auth.RequireBearerToken(verifier, nil)(streamHandler)
@@ -1649,11 +1650,68 @@ func TestTokenInfo(t *testing.T) {
 	if !ok {
 		t.Fatal("not TextContent")
 	}
-	if g, w := tc.Text, "&{[scope] 5000-01-02 03:04:05 +0000 UTC map[]}"; g != w {
+	if g, w := tc.Text, "&{[scope] 5000-01-02 03:04:05 +0000 UTC map[] user  }"; g != w {
 		t.Errorf("got %q, want %q", g, w)
 	}
 }
 
+func TestSessionPrincipal(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "whoami"}, func(ctx context.Context, req *CallToolRequest, _ struct{}) (*CallToolResult, any, error) {
+		return &CallToolResult{Content: []Content{&TextContent{Text: req.Session.Principal().Subject}}}, nil, nil
+	})
+	// The bearer token is the name of the user.
+	verifier := func(_ context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+		return &auth.TokenInfo{Subject: token, Expiration: time.Now().Add(time.Hour)}, nil
+	}
+	handler := auth.RequireBearerToken(verifier, nil)(NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, nil))
+	httpServer := httptest.NewServer(mustNotPanic(t, handler))
+	defer httpServer.Close()
+
+	httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req.Header.Set("Authorization", "Bearer alice")
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	session, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{Endpoint: httpServer.URL, HTTPClient: httpClient}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	res, err := session.CallTool(ctx, &CallToolParams{Name: "whoami"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Content[0].(*TextContent).Text; got != "alice" {
+		t.Errorf("Principal().Subject = %q, want %q", got, "alice")
+	}
+
+	// Another user can't use alice's session.
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+		req, err := http.NewRequest(method, httpServer.URL, strings.NewReader(`{"jsonrpc":"2.0","id":100,"method":"tools/call","params":{"name":"whoami"}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer bob")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(sessionIDHeader, session.ID())
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s with another user's token: got status %d, want %d", method, resp.StatusCode, http.StatusForbidden)
+		}
+	}
+
+	// The session is unaffected.
+	if _, err := session.CallTool(ctx, &CallToolParams{Name: "whoami"}); err != nil {
+		t.Errorf("CallTool after rejected requests failed: %v", err)
+	}
+}
+
 func TestStreamableGET(t *testing.T) {
 	// This test checks the fix for problematic behavior described in #410:
 	// Hanging GET headers should be written immediately, even if there are no
@@ -2046,3 +2104,45 @@ data: {"jsonrpc":"2.0","method":"test2","params":{}}
 		}
 	}
 }
+
+func TestStreamableMessageLimits(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, nil)
+	AddTool(server, &Tool{Name: "repeat"}, func(ctx context.Context, req *CallToolRequest, args struct{ N int }) (*CallToolResult, any, error) {
+		return &CallToolResult{Content: []Content{&TextContent{Text: strings.Repeat("x", args.N)}}}, nil, nil
+	})
+	for _, jsonResponse := range []bool{false, true} {
+		t.Run(fmt.Sprintf("jsonResponse=%t", jsonResponse), func(t *testing.T) {
+			handler := NewStreamableHTTPHandler(func(*http.Request) *Server { return server }, &StreamableHTTPOptions{
+				JSONResponse: jsonResponse,
+				MaxBodySize:  1000,
+			})
+			httpServer := httptest.NewServer(mustNotPanic(t, handler))
+			defer httpServer.Close()
+
+			session, err := NewClient(testImpl, nil).Connect(ctx, &StreamableClientTransport{
+				Endpoint:       httpServer.URL,
+				MaxMessageSize: 2000,
+			}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer session.Close()
+
+			// The server rejects an oversized request.
+			_, err = session.CallTool(ctx, &CallToolParams{Name: "repeat", Arguments: map[string]any{"N": 1, "Pad": strings.Repeat("x", 1000)}})
+			if !errors.Is(err, ErrMessageTooLarge) {
+				t.Errorf("CallTool with oversized request: got error %v, want %v", err, ErrMessageTooLarge)
+			}
+			// The client rejects an oversized response.
+			_, err = session.CallTool(ctx, &CallToolParams{Name: "repeat", Arguments: map[string]any{"N": 5000}})
+			if !errors.Is(err, ErrMessageTooLarge) {
+				t.Errorf("CallTool with oversized response: got error %v, want %v", err, ErrMessageTooLarge)
+			}
+			// The session survives.
+			if _, err := session.CallTool(ctx, &CallToolParams{Name: "repeat", Arguments: map[string]any{"N": 10}}); err != nil {
+				t.Errorf("CallTool after oversized messages failed: %v", err)
+			}
+		})
+	}
+}
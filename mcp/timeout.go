@@ -0,0 +1,117 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"time"
+)
+
+// timeoutMetaKey is the _meta key used to propagate the time remaining until
+// the deadline of a request, in milliseconds.
+const timeoutMetaKey = "go.modelcontextprotocol.io/timeout"
+
+// sendMetaKey is the context key for the sendMeta of an outgoing request.
+type sendMetaKey struct{}
+
+// sendMeta describes the _meta values to add to an outgoing request.
+type sendMeta struct {
+	progressToken any  // if non-nil, the progress token to add
+	deadline      bool // whether to add the time remaining until the deadline
+}
+
+// withSendingTimeout applies the session's default timeout for method to ctx.
+// The caller must call the returned function when the request completes.
+//
+// If the session resets timeouts on progress, the request is given a progress
+// token (if it doesn't have one), and the timeout is restarted whenever
+// progress is received for it. Since such a timeout has no fixed deadline, it
+// is not propagated to the peer, though the deadline of the caller's context
+// is, if the session propagates deadlines.
+func withSendingTimeout(ctx context.Context, method string, s Session, params Params) (context.Context, func()) {
+	d, reset, propagate := s.sendingTimeout(method)
+	sm := sendMeta{deadline: propagate}
+	if d > 0 && reset && params != nil && getProgressToken(params) == nil {
+		sm.progressToken = randText()
+	}
+	if sm.deadline || sm.progressToken != nil {
+		ctx = context.WithValue(ctx, sendMetaKey{}, sm)
+	}
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	if !reset || params == nil {
+		return context.WithTimeout(ctx, d)
+	}
+	token := getProgressToken(params)
+	if token == nil {
+		token = sm.progressToken
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(d, func() { cancel(context.DeadlineExceeded) })
+	stop := s.listenProgress(token, func(*ProgressNotificationParams) { timer.Reset(d) })
+	return ctx, func() {
+		stop()
+		timer.Stop()
+		cancel(nil)
+	}
+}
+
+// withContextMeta returns the params to send for an outgoing request: params,
+// with the _meta values described by the request's sendMeta added.
+//
+// Values already in the _meta of params take precedence. Since params belong
+// to the caller, they are not modified: the values are set on a shallow copy.
+func withContextMeta(ctx context.Context, params Params) Params {
+	sm, ok := ctx.Value(sendMetaKey{}).(sendMeta)
+	if !ok || params == nil {
+		return params
+	}
+	add := make(map[string]any)
+	if sm.progressToken != nil {
+		add[progressTokenKey] = sm.progressToken
+	}
+	if deadline, ok := ctx.Deadline(); ok && sm.deadline {
+		add[timeoutMetaKey] = max(time.Until(deadline).Milliseconds(), 0)
+	}
+	if len(add) == 0 {
+		return params
+	}
+	v := reflect.ValueOf(params)
+	if v.Kind() != reflect.Pointer || v.Type().Elem().Kind() != reflect.Struct {
+		return params
+	}
+	cv := reflect.New(v.Type().Elem())
+	if !v.IsNil() {
+		cv.Elem().Set(v.Elem())
+	}
+	cp := cv.Interface().(Params)
+	meta := maps.Clone(cp.GetMeta())
+	if meta == nil {
+		meta = make(map[string]any)
+	}
+	for k, v := range add {
+		if _, ok := meta[k]; !ok {
+			meta[k] = v
+		}
+	}
+	cp.SetMeta(meta)
+	return cp
+}
+
+// receivedTimeout reports the timeout propagated by the peer in params, if
+// any.
+func receivedTimeout(params Params) (time.Duration, bool) {
+	if params == nil {
+		return 0, false
+	}
+	ms, ok := params.GetMeta()[timeoutMetaKey].(float64)
+	if !ok || ms < 0 {
+		return 0, false
+	}
+	return time.Duration(ms * float64(time.Millisecond)), true
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeouts(t *testing.T) {
+	handlerErrs := make(chan error, 1)
+	server := NewServer(testImpl, &ServerOptions{ProgressInterval: -1})
+	AddTool(server, &Tool{Name: "block"}, func(ctx context.Context, req *CallToolRequest, _ any) (*CallToolResult, any, error) {
+		<-ctx.Done()
+		handlerErrs <- ctx.Err()
+		return nil, nil, ctx.Err()
+	})
+	AddTool(server, &Tool{Name: "deadline"}, func(ctx context.Context, req *CallToolRequest, _ any) (*CallToolResult, any, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return nil, nil, errors.New("no deadline")
+		}
+		if d := time.Until(deadline); d < 50*time.Minute || d > time.Hour {
+			return nil, nil, errors.New("wrong deadline")
+		}
+		return &CallToolResult{}, nil, nil
+	})
+	AddTool(server, &Tool{Name: "slow"}, func(ctx context.Context, req *CallToolRequest, _ any) (*CallToolResult, any, error) {
+		p := req.Progress()
+		for i := range 10 {
+			select {
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			case <-time.After(20 * time.Millisecond):
+			}
+			p.Report(float64(i)/10, "")
+		}
+		return &CallToolResult{}, nil, nil
+	})
+	ctx := context.Background()
+
+	t.Run("expiry", func(t *testing.T) {
+		client := NewClient(testImpl, &ClientOptions{
+			Timeouts: map[string]time.Duration{methodCallTool: 50 * time.Millisecond},
+		})
+		cs, _, cleanup := basicClientServerConnection(t, client, server, nil)
+		defer cleanup()
+		if res, err := cs.CallTool(ctx, &CallToolParams{Name: "block"}); !timedOut(res, err) {
+			t.Errorf("CallTool = (%v, %v), want timeout", res, err)
+		}
+		select {
+		case err := <-handlerErrs:
+			if err == nil {
+				t.Error("handler context not done")
+			}
+		case <-time.After(time.Second):
+			t.Error("handler not cancelled")
+		}
+		// Other methods are not subject to the timeout.
+		if err := cs.Ping(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("propagation", func(t *testing.T) {
+		for _, propagate := range []bool{false, true} {
+			client := NewClient(testImpl, &ClientOptions{PropagateDeadlines: propagate})
+			cs, _, cleanup := basicClientServerConnection(t, client, server, nil)
+			ctx, cancel := context.WithTimeout(ctx, time.Hour)
+			params := &CallToolParams{Name: "deadline", Meta: Meta{"k": "v"}}
+			res, err := cs.CallTool(ctx, params)
+			cancel()
+			cleanup()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.IsError == propagate {
+				t.Errorf("propagate=%t: tool error: %t, want %t (%v)", propagate, res.IsError, !propagate, res.Content)
+			}
+			// The caller's params are unchanged.
+			if len(params.Meta) != 1 {
+				t.Errorf("propagate=%t: params meta modified: %v", propagate, params.Meta)
+			}
+		}
+	})
+
+	t.Run("reset on progress", func(t *testing.T) {
+		// The tool takes longer than the timeout, but reports progress more often.
+		for _, reset := range []bool{false, true} {
+			client := NewClient(testImpl, &ClientOptions{
+				Timeouts:               map[string]time.Duration{methodCallTool: 100 * time.Millisecond},
+				ResetTimeoutOnProgress: reset,
+			})
+			cs, _, cleanup := basicClientServerConnection(t, client, server, nil)
+			res, err := cs.CallTool(ctx, &CallToolParams{Name: "slow"})
+			if got := timedOut(res, err); got != !reset {
+				t.Errorf("reset=%t: CallTool = (%v, %v), want timeout: %t", reset, res, err, !reset)
+			}
+			cleanup()
+		}
+	})
+}
+
+// timedOut reports whether a call timed out, either on the client or, if the
+// server's tool handler was cancelled first, in the handler.
+func timedOut(res *CallToolResult, err error) bool {
+	if err != nil {
+		return errors.Is(err, context.DeadlineExceeded)
+	}
+	return res.IsError
+}
@@ -59,6 +59,8 @@ type ToolHandlerFor[In, Out any] func(_ context.Context, request *CallToolReques
 type serverTool struct {
 	tool    *Tool
 	handler ToolHandler
+	toolset string   // name of the tool's toolset, if any; see [Server.AddToolsetTool]
+	scopes  []string // see [Server.RequireToolScopes]
 }
 
 // applySchema validates whether data is valid JSON according to the provided
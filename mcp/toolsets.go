@@ -0,0 +1,201 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+)
+
+// This file implements toolsets: named groups of tools that are enabled or
+// disabled together, so that a server with many tools need only show the
+// model the ones it is using.
+
+// A Toolset is a named group of tools.
+type Toolset struct {
+	// Name identifies the toolset.
+	Name string `json:"name"`
+	// Description tells the model what the tools of the toolset are for.
+	Description string `json:"description,omitempty"`
+	// Restricted excludes the toolset from the tools of
+	// [ServerOptions.ToolsetTools], so that the model can neither list nor
+	// enable it. Only the server can enable it, with [Server.EnableToolsets]
+	// or [ServerSession.EnableToolsets].
+	Restricted bool `json:"-"`
+}
+
+type serverToolset struct {
+	toolset *Toolset
+	enabled bool // for sessions that don't override it
+}
+
+// Names of the tools added by [ServerOptions.ToolsetTools].
+const (
+	listToolsetsToolName  = "list_toolsets"
+	enableToolsetToolName = "enable_toolset"
+)
+
+// AddToolset adds a [Toolset] to the server, or replaces one with the same
+// name. If enabled is true, the tools of the toolset are visible to every
+// session that has not disabled it; otherwise, they are visible only to sessions
+// that have enabled it (see [ServerSession.EnableToolsets]).
+//
+// Add tools to the toolset with [Server.AddToolsetTool] or [AddToolsetTool].
+// Replacing a toolset does not affect its tools.
+func (s *Server) AddToolset(ts *Toolset, enabled bool) {
+	s.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
+		func() bool { s.toolsets.add(&serverToolset{ts, enabled}); return true })
+}
+
+// AddToolsetTool adds a tool to the server as part of the named toolset, as
+// [Server.AddTool] does. The tool is visible to a session only while the
+// toolset is enabled for it. A tool whose toolset has not been added with
+// [Server.AddToolset] is not visible.
+func (s *Server) AddToolsetTool(toolset string, t *Tool, h ToolHandler) {
+	s.checkTool(t)
+	st := &serverTool{tool: t, handler: h, toolset: toolset}
+	s.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
+		func() bool {
+			if old, ok := s.tools.get(t.Name); ok {
+				st.scopes = old.scopes // see [Server.RequireToolScopes]
+			}
+			s.tools.add(st)
+			return true
+		})
+}
+
+// AddToolsetTool adds a tool and typed tool handler to the server as part of
+// the named toolset. It is like [AddTool], but see [Server.AddToolsetTool].
+func AddToolsetTool[In, Out any](s *Server, toolset string, t *Tool, h ToolHandlerFor[In, Out]) {
+	tt, hh, err := toolForErr(s.opts.SchemaRegistry, t, h)
+	if err != nil {
+		panic(fmt.Sprintf("AddToolsetTool: tool %q: %v", t.Name, err))
+	}
+	s.AddToolsetTool(toolset, tt, hh)
+}
+
+// EnableToolsets enables the named toolsets for all sessions that have not
+// disabled them. It is not an error to enable a nonexistent toolset.
+func (s *Server) EnableToolsets(names ...string) {
+	s.setToolsets(names, true)
+}
+
+// DisableToolsets disables the named toolsets for all sessions that have not
+// enabled them. It is not an error to disable a nonexistent toolset.
+func (s *Server) DisableToolsets(names ...string) {
+	s.setToolsets(names, false)
+}
+
+func (s *Server) setToolsets(names []string, enabled bool) {
+	s.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
+		func() bool {
+			changed := false
+			for _, name := range names {
+				if ts, ok := s.toolsets.get(name); ok && ts.enabled != enabled {
+					ts.enabled = enabled
+					changed = true
+				}
+			}
+			return changed
+		})
+}
+
+// EnableToolsets enables the named toolsets for the session, regardless of
+// whether they are enabled for the server.
+func (ss *ServerSession) EnableToolsets(names ...string) {
+	ss.setToolsets(names, true)
+}
+
+// DisableToolsets disables the named toolsets for the session, regardless of
+// whether they are enabled for the server.
+func (ss *ServerSession) DisableToolsets(names ...string) {
+	ss.setToolsets(names, false)
+}
+
+func (ss *ServerSession) setToolsets(names []string, enabled bool) {
+	ss.changeAndNotify(notificationToolListChanged, &ToolListChangedParams{},
+		func(f *sessionFeatures) bool {
+			changed := false
+			for _, name := range names {
+				if e, ok := f.toolsets[name]; !ok || e != enabled {
+					f.toolsets[name] = enabled
+					changed = true
+				}
+			}
+			return changed
+		})
+}
+
+// toolsetEnabled reports whether the named toolset is enabled for ss.
+// Tools without a toolset are always enabled.
+// It must be called with s.mu held.
+func (s *Server) toolsetEnabled(ss *ServerSession, name string) bool {
+	if name == "" {
+		return true
+	}
+	ts, ok := s.toolsets.get(name)
+	if !ok {
+		return false
+	}
+	if ss != nil && ss.features != nil {
+		if enabled, ok := ss.features.toolsets[name]; ok {
+			return enabled
+		}
+	}
+	return ts.enabled
+}
+
+type toolsetInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled"`
+}
+
+type listToolsetsResult struct {
+	Toolsets []toolsetInfo `json:"toolsets"`
+}
+
+type enableToolsetArgs struct {
+	Toolset string `json:"toolset" jsonschema:"the name of the toolset to enable"`
+}
+
+// addToolsetTools adds the tools that let the model list and enable toolsets.
+// See [ServerOptions.ToolsetTools].
+func (s *Server) addToolsetTools() {
+	AddTool(s, &Tool{
+		Name:        listToolsetsToolName,
+		Description: "List the toolsets of this server, and whether each is enabled. Only the tools of enabled toolsets are available.",
+	}, func(_ context.Context, req *CallToolRequest, _ any) (*CallToolResult, listToolsetsResult, error) {
+		res := listToolsetsResult{Toolsets: []toolsetInfo{}} // avoid JSON null
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for ts := range s.toolsets.all() {
+			if ts.toolset.Restricted {
+				continue
+			}
+			res.Toolsets = append(res.Toolsets, toolsetInfo{
+				Name:        ts.toolset.Name,
+				Description: ts.toolset.Description,
+				Enabled:     s.toolsetEnabled(req.Session, ts.toolset.Name),
+			})
+		}
+		return nil, res, nil
+	})
+	AddTool(s, &Tool{
+		Name:        enableToolsetToolName,
+		Description: "Enable a toolset, making its tools available. Use " + listToolsetsToolName + " to see the toolsets.",
+	}, func(_ context.Context, req *CallToolRequest, args enableToolsetArgs) (*CallToolResult, any, error) {
+		s.mu.Lock()
+		ts, ok := s.toolsets.get(args.Toolset)
+		s.mu.Unlock()
+		if !ok || ts.toolset.Restricted {
+			return nil, nil, fmt.Errorf("unknown toolset %q", args.Toolset)
+		}
+		req.Session.EnableToolsets(args.Toolset)
+		return &CallToolResult{
+			Content: []Content{&TextContent{Text: fmt.Sprintf("Enabled toolset %q.", args.Toolset)}},
+		}, nil, nil
+	})
+}
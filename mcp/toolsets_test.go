@@ -0,0 +1,99 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestToolsets(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(testImpl, &ServerOptions{ToolsetTools: true})
+	handler := func(context.Context, *CallToolRequest, any) (*CallToolResult, any, error) { return nil, nil, nil }
+	server.AddToolset(&Toolset{Name: "issues", Description: "Track issues"}, true)
+	server.AddToolset(&Toolset{Name: "wiki"}, false)
+	server.AddToolset(&Toolset{Name: "admin", Restricted: true}, false)
+	AddToolsetTool(server, "admin", &Tool{Name: "drop_db"}, handler)
+	AddToolsetTool(server, "issues", &Tool{Name: "create_issue"}, handler)
+	AddToolsetTool(server, "wiki", &Tool{Name: "edit_page"}, handler)
+	AddToolsetTool(server, "missing", &Tool{Name: "orphan"}, handler)
+	AddTool(server, &Tool{Name: "ping"}, handler)
+
+	c1, c2 := watchTools(t, server, "c1"), watchTools(t, server, "c2")
+
+	checkTools := func(c *toolsWatcher, want ...string) {
+		t.Helper()
+		res, err := c.cs.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, tool := range res.Tools {
+			got = append(got, tool.Name)
+		}
+		want = append(want, "enable_toolset", "list_toolsets", "ping")
+		slices.Sort(want)
+		if !slices.Equal(got, want) {
+			t.Errorf("listed tools %v, want %v", got, want)
+		}
+	}
+
+	checkTools(c1, "create_issue")
+	if _, err := c1.cs.CallTool(ctx, &CallToolParams{Name: "edit_page"}); err == nil {
+		t.Error("calling a tool of a disabled toolset succeeded")
+	}
+
+	// The model lists toolsets and enables one for its session.
+	res, err := c1.cs.CallTool(ctx, &CallToolParams{Name: "list_toolsets"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list listToolsetsResult
+	data, _ := json.Marshal(res.StructuredContent)
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatal(err)
+	}
+	want := []toolsetInfo{{"issues", "Track issues", true}, {"wiki", "", false}}
+	if diff := cmp.Diff(want, list.Toolsets); diff != "" {
+		t.Errorf("list_toolsets mismatch (-want +got):\n%s", diff)
+	}
+	res, err = c1.cs.CallTool(ctx, &CallToolParams{Name: "enable_toolset", Arguments: map[string]any{"toolset": "wiki"}})
+	if err != nil || res.IsError {
+		t.Fatalf("enable_toolset failed: %v, %v", err, res)
+	}
+	c1.awaitChange(t)
+	checkTools(c1, "create_issue", "edit_page")
+	checkTools(c2, "create_issue")
+	// The model can't enable nonexistent or restricted toolsets.
+	for _, name := range []string{"nonexistent", "admin"} {
+		res, err = c1.cs.CallTool(ctx, &CallToolParams{Name: "enable_toolset", Arguments: map[string]any{"toolset": name}})
+		if err != nil || !res.IsError {
+			t.Errorf("enable_toolset(%s): got %v, %v, want tool error", name, err, res)
+		}
+	}
+
+	// Global changes apply to sessions that haven't overridden them.
+	server.DisableToolsets("issues", "wiki")
+	c1.awaitChange(t)
+	c2.awaitChange(t)
+	checkTools(c1, "edit_page")
+	checkTools(c2)
+	c2.ss.EnableToolsets("issues")
+	c2.awaitChange(t)
+	checkTools(c2, "create_issue")
+	c1.ss.DisableToolsets("wiki")
+	c1.awaitChange(t)
+	checkTools(c1)
+
+	// The server can enable restricted toolsets.
+	c1.ss.EnableToolsets("admin")
+	c1.awaitChange(t)
+	checkTools(c1, "drop_db")
+}
@@ -5,6 +5,8 @@
 package mcp
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -86,11 +88,16 @@ type serverConnection interface {
 
 // A StdioTransport is a [Transport] that communicates over stdin/stdout using
 // newline-delimited JSON.
-type StdioTransport struct{}
+type StdioTransport struct {
+	// MaxMessageSize and MaxBatchSize limit incoming messages, as for
+	// [IOTransport].
+	MaxMessageSize int64
+	MaxBatchSize   int
+}
 
 // Connect implements the [Transport] interface.
-func (*StdioTransport) Connect(context.Context) (Connection, error) {
-	return newIOConn(rwc{os.Stdin, nopCloserWriter{os.Stdout}}), nil
+func (t *StdioTransport) Connect(context.Context) (Connection, error) {
+	return newLimitedIOConn(rwc{os.Stdin, nopCloserWriter{os.Stdout}}, t.MaxMessageSize, t.MaxBatchSize), nil
 }
 
 // nopCloserWriter is an io.WriteCloser with a trivial Close method.
@@ -105,11 +112,24 @@ func (nopCloserWriter) Close() error { return nil }
 type IOTransport struct {
 	Reader io.ReadCloser
 	Writer io.WriteCloser
+
+	// MaxMessageSize is the maximum size of an incoming message (or batch),
+	// in bytes. An oversized request is answered with a JSON-RPC error if its
+	// ID can be read, and a call whose response is oversized fails with
+	// [ErrMessageTooLarge]. Other oversized messages are dropped. Either way,
+	// the connection remains open.
+	// If zero or negative, there is no limit.
+	MaxMessageSize int64
+
+	// MaxBatchSize is the maximum number of messages in an incoming batch.
+	// The requests in larger batches are answered with JSON-RPC errors.
+	// If zero or negative, there is no limit.
+	MaxBatchSize int
 }
 
 // Connect implements the [Transport] interface.
 func (t *IOTransport) Connect(context.Context) (Connection, error) {
-	return newIOConn(rwc{t.Reader, t.Writer}), nil
+	return newLimitedIOConn(rwc{t.Reader, t.Writer}, t.MaxMessageSize, t.MaxBatchSize), nil
 }
 
 // An InMemoryTransport is a [Transport] that communicates over an in-memory
@@ -162,6 +182,9 @@ func connect[H handler, State any](ctx context.Context, t Transport, b binder[H,
 	bind := func(conn *jsonrpc2.Connection) jsonrpc2.Handler {
 		h = b.bind(mcpConn, conn, s, onClose)
 		preempter.conn = conn
+		if d, ok := any(h).(progressDispatcher); ok {
+			preempter.progress = d
+		}
 		return jsonrpc2.HandlerFunc(h.handle)
 	}
 	_ = jsonrpc2.NewConnection(ctx, jsonrpc2.ConnectionConfig{
@@ -181,12 +204,30 @@ func connect[H handler, State any](ctx context.Context, t Transport, b binder[H,
 
 // A canceller is a jsonrpc2.Preempter that cancels in-flight requests on MCP
 // cancelled notifications.
+//
+// It also delivers progress notifications to the progressDispatcher, if any.
+// Doing so in the preempter guarantees that progress is delivered before the
+// response to the request it describes is processed.
 type canceller struct {
-	conn *jsonrpc2.Connection
+	conn     *jsonrpc2.Connection
+	progress progressDispatcher
+}
+
+// A progressDispatcher routes progress notifications to listeners for
+// individual requests.
+type progressDispatcher interface {
+	dispatchProgress(*ProgressNotificationParams)
 }
 
 // Preempt implements [jsonrpc2.Preempter].
 func (c *canceller) Preempt(ctx context.Context, req *jsonrpc.Request) (result any, err error) {
+	if req.Method == notificationProgress && c.progress != nil {
+		var params ProgressNotificationParams
+		if err := json.Unmarshal(req.Params, &params); err == nil {
+			c.progress.dispatchProgress(&params)
+		}
+		// Malformed params are reported by the handler.
+	}
 	if req.Method == notificationCancelled {
 		var params CancelledParams
 		if err := json.Unmarshal(req.Params, &params); err != nil {
@@ -205,15 +246,22 @@ func (c *canceller) Preempt(ctx context.Context, req *jsonrpc.Request) (result a
 // translating errors into the mcp domain.
 func call(ctx context.Context, conn *jsonrpc2.Connection, method string, params Params, result Result) error {
 	// The "%w"s in this function expose jsonrpc.Error as part of the API.
-	call := conn.Call(ctx, method, params)
+	call := conn.Call(ctx, method, withContextMeta(ctx, params))
 	err := call.Await(ctx, result)
 	switch {
+	case jsonrpc2.IsRejected(err):
+		// The transport rejected the call, but the connection is intact: only
+		// this call failed. (An error response from the peer with the code of
+		// ErrRejected is indistinguishable from ErrServerClosing, and is
+		// handled below.)
+		return fmt.Errorf("calling %q: %w", method, err)
 	case errors.Is(err, jsonrpc2.ErrClientClosing), errors.Is(err, jsonrpc2.ErrServerClosing):
 		return fmt.Errorf("%w: calling %q: %v", ErrConnectionClosed, method, err)
 	case ctx.Err() != nil:
-		// Notify the peer of cancellation.
+		// Notify the peer of cancellation. If the context was cancelled with a
+		// cause, such as by [ToolCall.Cancel], report the cause as the reason.
 		err := conn.Notify(xcontext.Detach(ctx), notificationCancelled, &CancelledParams{
-			Reason:    ctx.Err().Error(),
+			Reason:    context.Cause(ctx).Error(),
 			RequestID: call.ID().Raw(),
 		})
 		// By default, the jsonrpc2 library waits for graceful shutdown when the
@@ -228,7 +276,12 @@ func call(ctx context.Context, conn *jsonrpc2.Connection, method string, params
 		// outgoingCalls map, when the caller context is cancelled: if the caller
 		// will never receive the response, there's no need to track it.
 		conn.Retire(call, ctx.Err())
-		return errors.Join(ctx.Err(), err)
+		ctxErr := ctx.Err()
+		if cause := context.Cause(ctx); errors.Is(cause, context.DeadlineExceeded) {
+			// The call timed out, though possibly not by a context deadline.
+			ctxErr = cause
+		}
+		return errors.Join(ctxErr, err)
 	case err != nil:
 		return fmt.Errorf("calling %q: %w", method, err)
 	}
@@ -360,6 +413,10 @@ type ioConn struct {
 	batchMu sync.Mutex
 	batches map[jsonrpc2.ID]*msgBatch // lazily allocated
 
+	// maxBatchSize is the maximum number of messages in an incoming batch,
+	// if positive.
+	maxBatchSize int
+
 	closeOnce sync.Once
 	closed    chan struct{}
 	closeErr  error
@@ -371,6 +428,13 @@ type msgOrErr struct {
 }
 
 func newIOConn(rwc io.ReadWriteCloser) *ioConn {
+	return newLimitedIOConn(rwc, 0, 0)
+}
+
+// newLimitedIOConn returns a new ioConn with the given limits on the size of
+// incoming messages and batches. Limits that are not positive disable
+// limiting, as for [IOTransport].
+func newLimitedIOConn(rwc io.ReadWriteCloser, maxMessageSize int64, maxBatchSize int) *ioConn {
 	var (
 		incoming = make(chan msgOrErr)
 		closed   = make(chan struct{})
@@ -381,39 +445,82 @@ func newIOConn(rwc io.ReadWriteCloser) *ioConn {
 	// This leaks a goroutine if rwc.Read does not unblock after it is closed,
 	// but that is unavoidable since AFAIK there is no (easy and portable) way to
 	// guarantee that reads of stdin are unblocked when closed.
-	go func() {
-		dec := json.NewDecoder(rwc)
-		for {
-			var raw json.RawMessage
-			err := dec.Decode(&raw)
-			// If decoding was successful, check for trailing data at the end of the stream.
-			if err == nil {
-				// Read the next byte to check if there is trailing data.
-				var tr [1]byte
-				if n, readErr := dec.Buffered().Read(tr[:]); n > 0 {
-					// If read byte is not a newline, it is an error.
-					// Support both Unix (\n) and Windows (\r\n) line endings.
-					if tr[0] != '\n' && tr[0] != '\r' {
-						err = fmt.Errorf("invalid trailing data at the end of stream")
-					}
-				} else if readErr != nil && readErr != io.EOF {
-					err = readErr
+	go readIOMessages(rwc, max(maxMessageSize, 0), incoming, closed)
+	return &ioConn{
+		rwc:          rwc,
+		incoming:     incoming,
+		closed:       closed,
+		maxBatchSize: max(maxBatchSize, 0),
+	}
+}
+
+// readIOMessages reads newline-delimited JSON values from r, sending them to
+// incoming until an error occurs or closed is closed.
+//
+// If limit is positive, values larger than limit bytes are reported with an
+// [oversizedError], after which reading resumes at the next line.
+func readIOMessages(r io.Reader, limit int64, incoming chan<- msgOrErr, closed <-chan struct{}) {
+	br := bufio.NewReader(r)
+	lr := &limitedReader{r: br}
+	dec := json.NewDecoder(lr)
+	for {
+		if limit > 0 {
+			// Allow one more byte than the limit, to detect the end of a value.
+			lr.max = dec.InputOffset() + limit + 1
+		}
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		// If decoding was successful, check for trailing data at the end of the stream.
+		if err == nil {
+			// Read the next byte to check if there is trailing data.
+			var tr [1]byte
+			if n, readErr := dec.Buffered().Read(tr[:]); n > 0 {
+				// If read byte is not a newline, it is an error.
+				// Support both Unix (\n) and Windows (\r\n) line endings.
+				if tr[0] != '\n' && tr[0] != '\r' {
+					err = fmt.Errorf("invalid trailing data at the end of stream")
 				}
+			} else if readErr != nil && readErr != io.EOF {
+				err = readErr
 			}
-			select {
-			case incoming <- msgOrErr{msg: raw, err: err}:
-			case <-closed:
-				return
+		}
+		if errors.Is(err, errReadLimit) {
+			// The decoder can't be used after an error. Skip the rest of the
+			// oversized message, and start again at the next line.
+			prefix, _ := io.ReadAll(dec.Buffered())
+			// The buffered data may start with the end of the previous line.
+			prefix = bytes.TrimLeft(prefix, " \t\r\n")
+			var rest []byte
+			if i := bytes.IndexByte(prefix, '\n'); i >= 0 {
+				prefix, rest = prefix[:i], prefix[i+1:]
+				err = nil
+			} else {
+				err = skipLine(br)
 			}
-			if err != nil {
-				return
+			if err == nil {
+				err = &oversizedError{limit: limit, prefix: prefix}
 			}
+			lr = &limitedReader{r: io.MultiReader(bytes.NewReader(rest), br)}
+			dec = json.NewDecoder(lr)
+		}
+		select {
+		case incoming <- msgOrErr{msg: raw, err: err}:
+		case <-closed:
+			return
+		}
+		if err != nil && !errors.As(err, new(*oversizedError)) {
+			return
+		}
+	}
+}
+
+// skipLine discards data from r up to and including the next newline.
+func skipLine(r *bufio.Reader) error {
+	for {
+		_, err := r.ReadSlice('\n')
+		if err != bufio.ErrBufferFull {
+			return err
 		}
-	}()
-	return &ioConn{
-		rwc:      rwc,
-		incoming: incoming,
-		closed:   closed,
 	}
 }
 
@@ -509,27 +616,56 @@ func (t *ioConn) Read(ctx context.Context) (jsonrpc.Message, error) {
 		return next, nil
 	}
 
-	var raw json.RawMessage
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
+	var (
+		msgs  []jsonrpc.Message
+		batch bool
+	)
+	for msgs == nil {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case v := <-t.incoming:
+			// Reject oversized messages, keeping the connection open.
+			var tooLarge *oversizedError
+			if errors.As(v.err, &tooLarge) {
+				resp, local := oversizedResponse(tooLarge)
+				if local {
+					return resp, nil
+				}
+				if resp == nil {
+					log.Printf("dropping message: %v", tooLarge)
+				}
+				t.reject(ctx, resp)
+				continue
+			}
+			if v.err != nil {
+				return nil, v.err
+			}
+			var err error
+			msgs, batch, err = readBatch(v.msg)
+			if err != nil {
+				return nil, err
+			}
 
-	case v := <-t.incoming:
-		if v.err != nil {
-			return nil, v.err
+		case <-t.closed:
+			return nil, io.EOF
+		}
+		if batch && t.protocolVersion >= protocolVersion20250618 {
+			return nil, fmt.Errorf("JSON-RPC batching is not supported in %s and later (request version: %s)", protocolVersion20250618, t.protocolVersion)
+		}
+		if batch {
+			if err := batchTooLarge(len(msgs), t.maxBatchSize); err != nil {
+				// Answer the calls of the batch, and drop the rest.
+				log.Printf("dropping batch: %v", err)
+				for _, msg := range msgs {
+					if req, ok := msg.(*jsonrpc.Request); ok && req.IsCall() {
+						t.reject(ctx, &jsonrpc.Response{ID: req.ID, Error: jsonrpc2.NewError(jsonrpc.CodeInvalidRequest, err.Error())})
+					}
+				}
+				msgs = nil
+			}
 		}
-		raw = v.msg
-
-	case <-t.closed:
-		return nil, io.EOF
-	}
-
-	msgs, batch, err := readBatch(raw)
-	if err != nil {
-		return nil, err
-	}
-	if batch && t.protocolVersion >= protocolVersion20250618 {
-		return nil, fmt.Errorf("JSON-RPC batching is not supported in %s and later (request version: %s)", protocolVersion20250618, t.protocolVersion)
 	}
 
 	t.queue = msgs[1:]
@@ -537,7 +673,7 @@ func (t *ioConn) Read(ctx context.Context) (jsonrpc.Message, error) {
 	if batch {
 		var respBatch *msgBatch // track incoming requests in the batch
 		for _, msg := range msgs {
-			if req, ok := msg.(*jsonrpc.Request); ok {
+			if req, ok := msg.(*jsonrpc.Request); ok && req.IsCall() {
 				if respBatch == nil {
 					respBatch = &msgBatch{
 						unresolved: make(map[jsonrpc2.ID]int),
@@ -557,7 +693,15 @@ func (t *ioConn) Read(ctx context.Context) (jsonrpc.Message, error) {
 			}
 		}
 	}
-	return msgs[0], err
+	return msgs[0], nil
+}
+
+// reject sends resp, the response to a rejected incoming message, if it is
+// non-nil. Errors are ignored, as the message has already been discarded.
+func (t *ioConn) reject(ctx context.Context, resp *jsonrpc.Response) {
+	if resp != nil {
+		t.Write(ctx, resp)
+	}
 }
 
 // readBatch reads batch data, which may be either a single JSON-RPC message,
@@ -5,7 +5,10 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"strings"
 	"testing"
@@ -54,6 +57,38 @@ func TestBatchFraming(t *testing.T) {
 	}
 }
 
+func TestBatchResponse(t *testing.T) {
+	// This test checks that a batch of incoming calls is answered with a
+	// batch of responses, once all calls have been answered. Notifications in
+	// the batch have no responses.
+	ctx := context.Background()
+	input := `[{"jsonrpc":"2.0","id":1,"method":"test1"},{"jsonrpc":"2.0","method":"note"},{"jsonrpc":"2.0","method":"note"},{"jsonrpc":"2.0","id":2,"method":"test2"}]`
+	var out bytes.Buffer
+	tport := newIOConn(rwc{
+		rc: io.NopCloser(strings.NewReader(input)),
+		wc: nopWriteCloser{&out},
+	})
+	t.Cleanup(func() { tport.Close() })
+	for range 4 {
+		if _, err := tport.Read(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []int64{2, 1} {
+		if err := tport.Write(ctx, &jsonrpc.Response{ID: jsonrpc2.Int64ID(id), Result: json.RawMessage(`{}`)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := `[{"jsonrpc":"2.0","id":1,"result":{}},{"jsonrpc":"2.0","id":2,"result":{}}]` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("wrote %q, want %q", got, want)
+	}
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
 func TestIOConnRead(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -124,3 +159,122 @@ func TestIOConnRead(t *testing.T) {
 		})
 	}
 }
+
+func TestIOConnLimits(t *testing.T) {
+	ctx := context.Background()
+	big := strings.Repeat("x", 200)
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"test","params":{"data":"` + big + `"}}`,
+		`{"jsonrpc":"2.0","method":"test","params":{"data":"` + big + `"},"id":5}`,
+		`{"jsonrpc":"2.0","method":"notify","params":{"data":"` + big + `"}}`,
+		`[{"jsonrpc":"2.0","id":4,"method":"a"},{"jsonrpc":"2.0","method":"b"},{"jsonrpc":"2.0","method":"c"}]`,
+		`{"jsonrpc":"2.0","id":2,"result":"` + big + `"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"test"}`,
+	}, "\n")
+	var out strings.Builder
+	tr := newLimitedIOConn(rwc{rc: io.NopCloser(strings.NewReader(input)), wc: nopWriteCloser{&out}}, 120, 2)
+	t.Cleanup(func() { tr.Close() })
+
+	// The oversized request and the call in the batch are rejected, other
+	// oversized messages are dropped, and the oversized response is reported
+	// locally.
+	msg, err := tr.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, ok := msg.(*jsonrpc.Response)
+	if !ok || resp.ID != jsonrpc2.Int64ID(2) || !errors.Is(resp.Error, ErrMessageTooLarge) {
+		t.Fatalf("first Read = %+v, want oversized error response for ID 2", msg)
+	}
+	// The connection survives.
+	msg, err = tr.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req, ok := msg.(*jsonrpc.Request); !ok || req.ID != jsonrpc2.Int64ID(3) {
+		t.Fatalf("second Read = %+v, want request 3", msg)
+	}
+
+	var rejected []*jsonrpc.Response
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		msg, err := jsonrpc2.DecodeMessage([]byte(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rejected = append(rejected, msg.(*jsonrpc.Response))
+	}
+	if len(rejected) != 2 {
+		t.Fatalf("wrote %d responses, want 2:\n%s", len(rejected), out.String())
+	}
+	if rejected[0].ID != jsonrpc2.Int64ID(1) {
+		t.Errorf("first rejection has ID %v, want 1", rejected[0].ID)
+	}
+	if rejected[1].ID != jsonrpc2.Int64ID(4) {
+		t.Errorf("batch rejection has ID %v, want 4", rejected[1].ID)
+	}
+	for _, r := range rejected {
+		var werr *jsonrpc.Error
+		if !errors.As(r.Error, &werr) || werr.Code != jsonrpc.CodeInvalidRequest {
+			t.Errorf("rejection error = %v, want code %d", r.Error, jsonrpc.CodeInvalidRequest)
+		}
+	}
+}
+
+func TestCallServerClosing(t *testing.T) {
+	// A peer's "server is closing" error has the code of jsonrpc2.ErrRejected,
+	// but it must still be reported as a closed connection.
+	ctx := context.Background()
+	ct, st := NewInMemoryTransports()
+	sconn, err := st.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sconn.Close()
+	go func() {
+		for {
+			msg, err := sconn.Read(ctx)
+			if err != nil {
+				return
+			}
+			req, ok := msg.(*jsonrpc.Request)
+			if !ok || !req.IsCall() {
+				continue
+			}
+			res := &jsonrpc.Response{ID: req.ID}
+			if req.Method == methodInitialize {
+				res.Result = mustMarshal(&InitializeResult{ProtocolVersion: latestProtocolVersion, ServerInfo: testImpl})
+			} else {
+				res.Error = &jsonrpc.Error{Code: -32004, Message: "server is closing"}
+			}
+			if err := sconn.Write(ctx, res); err != nil {
+				return
+			}
+		}
+	}()
+	cs, err := NewClient(testImpl, nil).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	if err := cs.Ping(ctx, nil); !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("Ping: got error %v, want ErrConnectionClosed", err)
+	}
+}
+
+func TestIOConnUnlimitedByDefault(t *testing.T) {
+	// Transports over byte streams have no limits unless configured.
+	ctx := context.Background()
+	big := `{"jsonrpc":"2.0","id":1,"method":"test","params":{"data":"` + strings.Repeat("x", DefaultMaxMessageSize) + `"}}`
+	batch := "[" + strings.TrimSuffix(strings.Repeat(`{"jsonrpc":"2.0","method":"a"},`, DefaultMaxBatchSize+1), ",") + "]"
+	var out strings.Builder
+	tr := newLimitedIOConn(rwc{rc: io.NopCloser(strings.NewReader(big + "\n" + batch)), wc: nopWriteCloser{&out}}, 0, 0)
+	t.Cleanup(func() { tr.Close() })
+	for i := range 1 + DefaultMaxBatchSize + 1 {
+		if _, err := tr.Read(ctx); err != nil {
+			t.Fatalf("Read #%d: %v", i, err)
+		}
+	}
+	if out.Len() != 0 {
+		t.Errorf("got rejections %s, want none", out.String())
+	}
+}
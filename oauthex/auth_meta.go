@@ -109,6 +109,16 @@ type AuthServerMeta struct {
 	// CodeChallengeMethodsSupported is a RECOMMENDED JSON array of strings containing a list of
 	// PKCE code challenge methods supported by this authorization server.
 	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported,omitempty"`
+
+	// DPOPSigningAlgValuesSupported is a JSON array of strings containing a list of the JWS
+	// signing algorithms ("alg" values) supported by the authorization server for DPoP proof
+	// JWTs (RFC 9449). Its presence indicates that the server supports DPoP.
+	DPOPSigningAlgValuesSupported []string `json:"dpop_signing_alg_values_supported,omitempty"`
+
+	// ClientIDMetadataDocumentSupported is a boolean indicating whether the server
+	// accepts HTTPS URLs of client ID metadata documents as client IDs.
+	// See [ResolveClient].
+	ClientIDMetadataDocumentSupported bool `json:"client_id_metadata_document_supported,omitempty"`
 }
 
 var wellKnownPaths = []string{
@@ -0,0 +1,260 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// This file implements fetching and validating Client ID Metadata Documents,
+// for authorization servers.
+// See https://datatracker.ietf.org/doc/draft-ietf-oauth-client-id-metadata-document.
+
+package oauthex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ClientIDMetadataDocument is a client metadata document published at the URL that
+// serves as the client's ID.
+type ClientIDMetadataDocument struct {
+	// ClientID is the REQUIRED URL of the document itself.
+	ClientID string `json:"client_id"`
+
+	// ClientRegistrationMetadata contains the client's metadata, with the same
+	// fields as a dynamic client registration request.
+	ClientRegistrationMetadata
+}
+
+// checkClientIDURL checks that u is a valid client ID URL: an HTTPS URL with a path,
+// but without dot segments, a fragment, or credentials.
+func checkClientIDURL(u string) error {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("invalid client ID URL %q: %w", u, err)
+	}
+	if pu.Scheme != "https" || pu.Host == "" {
+		return fmt.Errorf("client ID URL %q is not an absolute HTTPS URL", u)
+	}
+	if pu.Path == "" || pu.Path == "/" {
+		return fmt.Errorf("client ID URL %q has no path", u)
+	}
+	for _, seg := range strings.Split(pu.Path, "/") {
+		if seg == "." || seg == ".." {
+			return fmt.Errorf("client ID URL %q has a dot segment", u)
+		}
+	}
+	if pu.Fragment != "" || pu.User != nil {
+		return fmt.Errorf("client ID URL %q has a fragment or credentials", u)
+	}
+	return nil
+}
+
+// maxClientIDMetadataSize is the maximum size of a client ID metadata document.
+// The draft suggests 5 kilobytes.
+const maxClientIDMetadataSize = 5 << 10
+
+// A ClientIDMetadataFetcher fetches, validates and caches the client ID metadata
+// documents of clients, for use by authorization servers.
+//
+// Since client IDs are URLs chosen by clients, fetching them exposes the server
+// to server-side request forgery. By default, the fetcher refuses to connect to
+// loopback, private, link-local and other non-public addresses.
+type ClientIDMetadataFetcher struct {
+	// HTTPClient is used to fetch documents.
+	// If nil, a client that connects only to public addresses, without a
+	// proxy, is used. A non-nil client is responsible for refusing requests to
+	// internal services.
+	HTTPClient *http.Client
+	// MaxAge is the maximum duration for which documents are cached. Documents are
+	// cached for less time if their response says so (with Cache-Control max-age or
+	// no-store). If zero, it is one hour. If negative, documents are not cached.
+	MaxAge time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedClientMetadata
+}
+
+type cachedClientMetadata struct {
+	doc     *ClientIDMetadataDocument
+	expires time.Time
+}
+
+// Fetch returns the validated metadata document of the client with the given ID,
+// from the cache if possible.
+//
+// The document is valid if its client_id is clientID, it lists at least one
+// redirect URI, and it does not use authentication with a client secret, which
+// clients identified by URL cannot have.
+func (f *ClientIDMetadataFetcher) Fetch(ctx context.Context, clientID string) (*ClientIDMetadataDocument, error) {
+	now := time.Now()
+	f.mu.Lock()
+	if e, ok := f.cache[clientID]; ok && now.Before(e.expires) {
+		f.mu.Unlock()
+		return e.doc, nil
+	}
+	f.mu.Unlock()
+
+	doc, maxAge, err := f.fetch(ctx, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching client ID metadata document %q: %w", clientID, err)
+	}
+	if maxAge > 0 {
+		f.mu.Lock()
+		if f.cache == nil {
+			f.cache = make(map[string]cachedClientMetadata)
+		}
+		// Drop expired entries, so that the cache does not grow without bound.
+		for id, e := range f.cache {
+			if !now.Before(e.expires) {
+				delete(f.cache, id)
+			}
+		}
+		f.cache[clientID] = cachedClientMetadata{doc, now.Add(maxAge)}
+		f.mu.Unlock()
+	}
+	return doc, nil
+}
+
+// fetch fetches and validates a document, and returns it with the duration for
+// which it may be cached.
+func (f *ClientIDMetadataFetcher) fetch(ctx context.Context, clientID string) (*ClientIDMetadataDocument, time.Duration, error) {
+	if err := checkClientIDURL(clientID); err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, clientID, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	c := f.HTTPClient
+	if c == nil {
+		c = publicClient
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("bad status %s", res.Status)
+	}
+	if mt, _, err := mime.ParseMediaType(res.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+		return nil, 0, fmt.Errorf("bad content type %q", res.Header.Get("Content-Type"))
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxClientIDMetadataSize+1))
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(data) > maxClientIDMetadataSize {
+		return nil, 0, fmt.Errorf("document exceeds %d bytes", maxClientIDMetadataSize)
+	}
+	var doc ClientIDMetadataDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+	if err := doc.validate(clientID); err != nil {
+		return nil, 0, err
+	}
+	return &doc, f.cacheAge(res.Header.Get("Cache-Control")), nil
+}
+
+// publicClient is the default client of a [ClientIDMetadataFetcher]. It
+// connects only to public addresses, which it checks after name resolution
+// (and so for redirects too).
+var publicClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 30 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				ap, err := netip.ParseAddrPort(address)
+				if err != nil {
+					return err
+				}
+				if !isPublic(ap.Addr()) {
+					return fmt.Errorf("refusing to connect to non-public address %s", ap.Addr())
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+	Timeout: time.Minute,
+}
+
+// isPublic reports whether a is a public unicast address.
+func isPublic(a netip.Addr) bool {
+	a = a.Unmap()
+	return a.IsGlobalUnicast() && !a.IsPrivate() &&
+		!(a.Is4() && netip.MustParsePrefix("100.64.0.0/10").Contains(a)) // shared address space (RFC 6598)
+}
+
+// validate checks that doc is a valid document for the given client ID.
+func (doc *ClientIDMetadataDocument) validate(clientID string) error {
+	if doc.ClientID != clientID {
+		return fmt.Errorf("document has client_id %q", doc.ClientID)
+	}
+	if len(doc.RedirectURIs) == 0 {
+		return errors.New("document has no redirect_uris")
+	}
+	for _, u := range doc.RedirectURIs {
+		if err := checkURLScheme(u); err != nil {
+			return err
+		}
+	}
+	if slices.Contains([]string{"client_secret_basic", "client_secret_post", "client_secret_jwt"}, doc.TokenEndpointAuthMethod) {
+		return fmt.Errorf("document uses a client secret (%s)", doc.TokenEndpointAuthMethod)
+	}
+	return nil
+}
+
+// cacheAge returns the duration for which a document may be cached, given its
+// Cache-Control header.
+func (f *ClientIDMetadataFetcher) cacheAge(cacheControl string) time.Duration {
+	maxAge := f.MaxAge
+	if maxAge == 0 {
+		maxAge = time.Hour
+	}
+	for _, d := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0
+		case "max-age":
+			if secs, err := strconv.Atoi(value); err == nil {
+				maxAge = min(maxAge, time.Duration(secs)*time.Second)
+			}
+		}
+	}
+	return maxAge
+}
+
+// checkURLScheme ensures that its argument is a valid URL with a scheme
+// that prevents XSS attacks.
+// See #526.
+func checkURLScheme(u string) error {
+	if u == "" {
+		return nil
+	}
+	uu, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+	scheme := strings.ToLower(uu.Scheme)
+	if scheme == "javascript" || scheme == "data" || scheme == "vbscript" {
+		return fmt.Errorf("URL has disallowed scheme %q", scheme)
+	}
+	return nil
+}
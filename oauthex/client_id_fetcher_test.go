@@ -0,0 +1,104 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package oauthex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestClientIDMetadataFetcher(t *testing.T) {
+	ctx := context.Background()
+	var fetches int
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+	serve := func(path, cacheControl, doc string) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			fetches++
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			if cacheControl != "" {
+				w.Header().Set("Cache-Control", cacheControl)
+			}
+			fmt.Fprintf(w, doc, server.URL+path)
+		})
+	}
+	serve("/good", "", `{"client_id":%q,"redirect_uris":["http://localhost/cb"],"client_name":"App"}`)
+	serve("/nostore", "no-store", `{"client_id":%q,"redirect_uris":["http://localhost/cb"]}`)
+	serve("/wrongid", "", `{"client_id":"https://other.example.com/x%.0s","redirect_uris":["http://localhost/cb"]}`)
+	serve("/secret", "", `{"client_id":%q,"redirect_uris":["http://localhost/cb"],"token_endpoint_auth_method":"client_secret_basic"}`)
+	serve("/noredirect", "", `{"client_id":%q}`)
+	serve("/xss", "", `{"client_id":%q,"redirect_uris":["javascript:alert(1)"]}`)
+	serve("/big", "", `{"client_id":%q,"redirect_uris":["http://localhost/cb"],"client_name":"`+strings.Repeat("x", 6000)+`"}`)
+
+	f := &ClientIDMetadataFetcher{HTTPClient: server.Client()}
+	for range 2 {
+		doc, err := f.Fetch(ctx, server.URL+"/good")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc.ClientName != "App" {
+			t.Errorf("got client name %q, want %q", doc.ClientName, "App")
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("good document: got %d fetches, want 1 (cached)", fetches)
+	}
+	fetches = 0
+	for range 2 {
+		if _, err := f.Fetch(ctx, server.URL+"/nostore"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 2 {
+		t.Errorf("no-store document: got %d fetches, want 2", fetches)
+	}
+
+	for _, path := range []string{"/wrongid", "/secret", "/noredirect", "/xss", "/big", "/missing", "/./good"} {
+		if _, err := f.Fetch(ctx, server.URL+path); err == nil {
+			t.Errorf("%s: got nil error, want error", path)
+		}
+	}
+	if _, err := f.Fetch(ctx, "http://app.example.com/client.json"); err == nil {
+		t.Error("HTTP client ID: got nil error, want error")
+	}
+
+	// By default, the fetcher refuses to connect to the loopback address of
+	// the test server.
+	if _, err := new(ClientIDMetadataFetcher).Fetch(ctx, server.URL+"/good"); err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Errorf("default client: got error %v, want refusal of non-public address", err)
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	for _, test := range []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1::1", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	} {
+		if got := isPublic(netip.MustParseAddr(test.addr)); got != test.want {
+			t.Errorf("isPublic(%s) = %t, want %t", test.addr, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// This file implements Client ID Metadata Documents, which identify a client
+// by an HTTPS URL at which its metadata is published, instead of registering it.
+// See https://datatracker.ietf.org/doc/draft-ietf-oauth-client-id-metadata-document.
+
+//go:build mcp_go_client_oauth
+
+package oauthex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ResolveClient identifies the client at the authorization server described by asm.
+//
+// If the server supports client ID metadata documents and metadataURL is non-empty,
+// metadataURL is used as the client ID: no registration is needed, but the client's
+// metadata, including its client_id, must be published at that URL. Otherwise, the
+// client is registered with [RegisterClient], using clientMeta.
+//
+// Either way, the returned registration holds the client ID to use at the server.
+func ResolveClient(ctx context.Context, asm *AuthServerMeta, metadataURL string, clientMeta *ClientRegistrationMetadata, c *http.Client) (*ClientRegistrationResponse, error) {
+	if asm.ClientIDMetadataDocumentSupported && metadataURL != "" {
+		if err := checkClientIDURL(metadataURL); err != nil {
+			return nil, err
+		}
+		reg := &ClientRegistrationResponse{ClientID: metadataURL}
+		if clientMeta != nil {
+			reg.ClientRegistrationMetadata = *clientMeta
+		}
+		// Clients identified by URL have no secrets (see section 4.1 of the draft).
+		reg.TokenEndpointAuthMethod = "none"
+		return reg, nil
+	}
+	if asm.RegistrationEndpoint == "" {
+		return nil, fmt.Errorf("authorization server %q supports neither client ID metadata documents nor dynamic client registration", asm.Issuer)
+	}
+	return RegisterClient(ctx, asm.RegistrationEndpoint, clientMeta, c)
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 The Go MCP SDK Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build mcp_go_client_oauth
+
+package oauthex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolveClient(t *testing.T) {
+	ctx := context.Background()
+	var registrations int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		registrations++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"client_id":"registered"}`))
+	}))
+	defer server.Close()
+	clientMeta := &ClientRegistrationMetadata{ClientName: "App", RedirectURIs: []string{"http://localhost/cb"}}
+	const metadataURL = "https://app.example.com/client.json"
+
+	for _, tt := range []struct {
+		name        string
+		asm         *AuthServerMeta
+		metadataURL string
+		want        string // client ID, or error substring
+	}{
+		{"metadata document", &AuthServerMeta{ClientIDMetadataDocumentSupported: true, RegistrationEndpoint: server.URL}, metadataURL, metadataURL},
+		{"no document support", &AuthServerMeta{RegistrationEndpoint: server.URL}, metadataURL, "registered"},
+		{"no metadata URL", &AuthServerMeta{ClientIDMetadataDocumentSupported: true, RegistrationEndpoint: server.URL}, "", "registered"},
+		{"bad metadata URL", &AuthServerMeta{ClientIDMetadataDocumentSupported: true}, "https://app.example.com", "has no path"},
+		{"neither", &AuthServerMeta{Issuer: "https://as.example.com"}, metadataURL, "supports neither"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			reg, err := ResolveClient(ctx, tt.asm, tt.metadataURL, clientMeta, server.Client())
+			if err != nil {
+				if !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("got error %v, want %q", err, tt.want)
+				}
+				return
+			}
+			if reg.ClientID != tt.want {
+				t.Errorf("got client ID %q, want %q", reg.ClientID, tt.want)
+			}
+		})
+	}
+	if registrations != 2 {
+		t.Errorf("got %d registrations, want 2", registrations)
+	}
+}
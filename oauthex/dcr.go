@@ -19,74 +19,6 @@ import (
 	"time"
 )
 
-// ClientRegistrationMetadata represents the client metadata fields for the DCR POST request (RFC 7591).
-type ClientRegistrationMetadata struct {
-	// RedirectURIs is a REQUIRED JSON array of redirection URI strings for use in
-	// redirect-based flows (such as the authorization code grant).
-	RedirectURIs []string `json:"redirect_uris"`
-
-	// TokenEndpointAuthMethod is an OPTIONAL string indicator of the requested
-	// authentication method for the token endpoint.
-	// If omitted, the default is "client_secret_basic".
-	TokenEndpointAuthMethod string `json:"token_endpoint_auth_method,omitempty"`
-
-	// GrantTypes is an OPTIONAL JSON array of OAuth 2.0 grant type strings
-	// that the client will restrict itself to using.
-	// If omitted, the default is ["authorization_code"].
-	GrantTypes []string `json:"grant_types,omitempty"`
-
-	// ResponseTypes is an OPTIONAL JSON array of OAuth 2.0 response type strings
-	// that the client will restrict itself to using.
-	// If omitted, the default is ["code"].
-	ResponseTypes []string `json:"response_types,omitempty"`
-
-	// ClientName is a RECOMMENDED human-readable name of the client to be presented
-	// to the end-user.
-	ClientName string `json:"client_name,omitempty"`
-
-	// ClientURI is a RECOMMENDED URL of a web page providing information about the client.
-	ClientURI string `json:"client_uri,omitempty"`
-
-	// LogoURI is an OPTIONAL URL of a logo for the client, which may be displayed
-	// to the end-user.
-	LogoURI string `json:"logo_uri,omitempty"`
-
-	// Scope is an OPTIONAL string containing a space-separated list of scope values
-	// that the client will restrict itself to using.
-	Scope string `json:"scope,omitempty"`
-
-	// Contacts is an OPTIONAL JSON array of strings representing ways to contact
-	// people responsible for this client (e.g., email addresses).
-	Contacts []string `json:"contacts,omitempty"`
-
-	// TOSURI is an OPTIONAL URL that the client provides to the end-user
-	// to read about the client's terms of service.
-	TOSURI string `json:"tos_uri,omitempty"`
-
-	// PolicyURI is an OPTIONAL URL that the client provides to the end-user
-	// to read about the client's privacy policy.
-	PolicyURI string `json:"policy_uri,omitempty"`
-
-	// JWKSURI is an OPTIONAL URL for the client's JSON Web Key Set [JWK] document.
-	// This is preferred over the 'jwks' parameter.
-	JWKSURI string `json:"jwks_uri,omitempty"`
-
-	// JWKS is an OPTIONAL client's JSON Web Key Set [JWK] document, passed by value.
-	// This is an alternative to providing a JWKSURI.
-	JWKS string `json:"jwks,omitempty"`
-
-	// SoftwareID is an OPTIONAL unique identifier string for the client software,
-	// constant across all instances and versions.
-	SoftwareID string `json:"software_id,omitempty"`
-
-	// SoftwareVersion is an OPTIONAL version identifier string for the client software.
-	SoftwareVersion string `json:"software_version,omitempty"`
-
-	// SoftwareStatement is an OPTIONAL JWT that asserts client metadata values.
-	// Values in the software statement take precedence over other metadata values.
-	SoftwareStatement string `json:"software_statement,omitempty"`
-}
-
 // ClientRegistrationResponse represents the fields returned by the Authorization Server
 // (RFC 7591, Section 3.2.1 and 3.2.2).
 type ClientRegistrationResponse struct {
@@ -106,6 +38,14 @@ type ClientRegistrationResponse struct {
 	// ClientSecretExpiresAt is the REQUIRED (if client_secret is issued) Unix
 	// timestamp when the secret expires, or 0 if it never expires.
 	ClientSecretExpiresAt time.Time `json:"client_secret_expires_at,omitempty"`
+
+	// RegistrationAccessToken is an OPTIONAL access token to be used at the client
+	// configuration endpoint to read, update and delete the registration (RFC 7592, Section 3).
+	RegistrationAccessToken string `json:"registration_access_token,omitempty"`
+
+	// RegistrationClientURI is the location of the client configuration endpoint,
+	// REQUIRED if RegistrationAccessToken is issued (RFC 7592, Section 3).
+	RegistrationClientURI string `json:"registration_client_uri,omitempty"`
 }
 
 func (r *ClientRegistrationResponse) MarshalJSON() ([]byte, error) {
@@ -221,3 +161,106 @@ func RegisterClient(ctx context.Context, registrationEndpoint string, clientMeta
 
 	return nil, fmt.Errorf("registration failed with status %s: %s", resp.Status, string(body))
 }
+
+// GetClientRegistration reads the current registration of a client from its client
+// configuration endpoint, according to RFC 7592, Section 2.1.
+// The registration must have been returned by [RegisterClient] (or by an earlier call
+// to one of the registration management functions), with a registration access token.
+func GetClientRegistration(ctx context.Context, reg *ClientRegistrationResponse, c *http.Client) (*ClientRegistrationResponse, error) {
+	return manageClient(ctx, http.MethodGet, reg, nil, c)
+}
+
+// UpdateClientRegistration replaces the metadata of a client's registration with clientMeta,
+// according to RFC 7592, Section 2.2. Fields omitted from clientMeta may be deleted or reset
+// to default values by the server.
+// It returns the updated registration, which may include a new client secret or
+// registration access token.
+func UpdateClientRegistration(ctx context.Context, reg *ClientRegistrationResponse, clientMeta *ClientRegistrationMetadata, c *http.Client) (*ClientRegistrationResponse, error) {
+	// The request includes the client ID and secret, but not the registration
+	// access token or the timestamps (Section 2.2).
+	body := struct {
+		*ClientRegistrationMetadata
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret,omitempty"`
+	}{clientMeta, reg.ClientID, reg.ClientSecret}
+	return manageClient(ctx, http.MethodPut, reg, body, c)
+}
+
+// DeleteClientRegistration deprovisions a client, according to RFC 7592, Section 2.3.
+// After it succeeds, the client's credentials and registration access token are invalid.
+func DeleteClientRegistration(ctx context.Context, reg *ClientRegistrationResponse, c *http.Client) error {
+	_, err := manageClient(ctx, http.MethodDelete, reg, nil, c)
+	return err
+}
+
+// manageClient sends a request with the given method and body (if non-nil) to the
+// client configuration endpoint of reg, and returns the registration in the response,
+// if any.
+func manageClient(ctx context.Context, method string, reg *ClientRegistrationResponse, body any, c *http.Client) (*ClientRegistrationResponse, error) {
+	if reg.RegistrationClientURI == "" || reg.RegistrationAccessToken == "" {
+		return nil, fmt.Errorf("registration of client %q has no client configuration endpoint or registration access token", reg.ClientID)
+	}
+	if c == nil {
+		c = http.DefaultClient
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal client metadata: %w", err)
+		}
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reg.RegistrationClientURI, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client configuration request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+reg.RegistrationAccessToken)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("client configuration request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client configuration response body: %w", err)
+	}
+
+	switch {
+	case method == http.MethodDelete && resp.StatusCode == http.StatusNoContent:
+		return nil, nil
+	case method != http.MethodDelete && resp.StatusCode == http.StatusOK:
+		var newReg ClientRegistrationResponse
+		if err := json.Unmarshal(respBody, &newReg); err != nil {
+			return nil, fmt.Errorf("failed to decode client configuration response: %w (%s)", err, string(respBody))
+		}
+		if newReg.ClientID != reg.ClientID {
+			return nil, fmt.Errorf("client configuration response has client_id %q, want %q", newReg.ClientID, reg.ClientID)
+		}
+		// The server need not return the registration access token and
+		// endpoint if they are unchanged (Section 3).
+		if newReg.RegistrationAccessToken == "" {
+			newReg.RegistrationAccessToken = reg.RegistrationAccessToken
+		}
+		if newReg.RegistrationClientURI == "" {
+			newReg.RegistrationClientURI = reg.RegistrationClientURI
+		}
+		return &newReg, nil
+	case resp.StatusCode == http.StatusBadRequest:
+		var regError ClientRegistrationError
+		if err := json.Unmarshal(respBody, &regError); err != nil {
+			return nil, fmt.Errorf("failed to decode client configuration error response: %w (%s)", err, string(respBody))
+		}
+		return nil, &regError
+	}
+	// A 401 means the registration access token is invalid, and a 403 or 404
+	// that the client no longer exists (Section 2.1).
+	return nil, fmt.Errorf("client configuration request failed with status %s: %s", resp.Status, string(respBody))
+}
@@ -215,3 +215,79 @@ func TestClientRegistrationResponseJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestClientRegistrationManagement(t *testing.T) {
+	ctx := context.Background()
+	// The server stores the registration of a single client, rotating its
+	// registration access token on update.
+	current := ClientRegistrationMetadata{ClientName: "App", RedirectURIs: []string{"http://localhost/cb"}}
+	token := "token1"
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/register/client1" || r.Header.Get("Authorization") != "Bearer "+token || deleted {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		resp := ClientRegistrationResponse{ClientID: "client1"}
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body["client_id"] != "client1" || body["registration_access_token"] != nil {
+				t.Errorf("update request has body %v", body)
+			}
+			data, _ := json.Marshal(body)
+			current = ClientRegistrationMetadata{}
+			json.Unmarshal(data, &current)
+			token = "token2"
+			resp.RegistrationAccessToken = token
+		case http.MethodDelete:
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		resp.ClientRegistrationMetadata = current
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&resp)
+	}))
+	defer server.Close()
+
+	reg := &ClientRegistrationResponse{
+		ClientID:                "client1",
+		RegistrationAccessToken: "token1",
+		RegistrationClientURI:   server.URL + "/register/client1",
+	}
+	got, err := GetClientRegistration(ctx, reg, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ClientName != "App" || got.RegistrationAccessToken != "token1" || got.RegistrationClientURI != reg.RegistrationClientURI {
+		t.Errorf("GetClientRegistration() = %+v", got)
+	}
+
+	got, err = UpdateClientRegistration(ctx, got, &ClientRegistrationMetadata{ClientName: "New App", RedirectURIs: []string{"http://localhost/cb2"}}, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ClientName != "New App" || got.RegistrationAccessToken != "token2" {
+		t.Errorf("UpdateClientRegistration() = %+v", got)
+	}
+	// The old token no longer works.
+	if _, err := GetClientRegistration(ctx, reg, server.Client()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("GetClientRegistration with old token: got error %v, want 401", err)
+	}
+
+	if err := DeleteClientRegistration(ctx, got, server.Client()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetClientRegistration(ctx, got, server.Client()); err == nil {
+		t.Error("GetClientRegistration after delete: got nil error, want error")
+	}
+
+	if _, err := GetClientRegistration(ctx, &ClientRegistrationResponse{ClientID: "client1"}, nil); err == nil {
+		t.Error("GetClientRegistration without token: got nil error, want error")
+	}
+}